@@ -9,6 +9,7 @@ package main
 
 import "C"
 import (
+	"encoding/hex"
 	"encoding/json"
 	"github.com/donnie4w/go-logger/logger"
 	_ "github.com/donnie4w/wfs/keystore"
@@ -16,6 +17,8 @@ import (
 	_ "github.com/donnie4w/wfs/stor"
 	. "github.com/donnie4w/wfs/sys"
 	_ "github.com/donnie4w/wfs/tc"
+	"github.com/donnie4w/wfs/util"
+	"io"
 	"sync"
 	"unsafe"
 )
@@ -123,6 +126,13 @@ func GetInitStatus() *C.char {
 
 //export Append
 func Append(name *C.char, data *C.uchar, dataLen C.int, compress C.int) *C.char { // 改为 C.uchar
+	return AppendWithMeta(name, data, dataLen, compress, nil, nil, 0)
+}
+
+// AppendWithMeta 追加数据并保存Content-Type与用户元数据，metaJSON为字符串键值对的JSON对象，均可为NULL；ttl为过期秒数，0为不过期
+//
+//export AppendWithMeta
+func AppendWithMeta(name *C.char, data *C.uchar, dataLen C.int, compress C.int, contentType *C.char, metaJSON *C.char, ttl C.longlong) *C.char {
 	initMutex.RLock()
 	if !isInitialized {
 		initMutex.RUnlock()
@@ -141,7 +151,17 @@ func Append(name *C.char, data *C.uchar, dataLen C.int, compress C.int) *C.char
 		return C.CString(ERR_OVERSIZE.Error().Error())
 	}
 
-	if _, err := AppendData(goName, goData, int32(compress)); err != nil {
+	mb := &MetaBean{Expire: util.ExpireAt(int64(ttl))}
+	if contentType != nil {
+		mb.ContentType = C.GoString(contentType)
+	}
+	if metaJSON != nil {
+		if err := json.Unmarshal([]byte(C.GoString(metaJSON)), &mb.Meta); err != nil {
+			return C.CString(ERR_PARAMS.Error().Error())
+		}
+	}
+
+	if _, err := AppendData(goName, goData, int32(compress), mb); err != nil {
 		return C.CString(err.Error().Error())
 	}
 
@@ -185,15 +205,29 @@ func Get(path *C.char, resultLen *C.int) *C.uchar {
 	}
 
 	goPath := C.GoString(path)
-	data := GetData(goPath)
+	db := GetReader(goPath)
 
-	if data == nil || len(data) == 0 {
-		*resultLen = 0
+	if db == nil || db.Size == 0 {
+		if *resultLen = 0; db == nil && Corrupt(goPath) {
+			*resultLen = -2
+		}
+		if db != nil {
+			db.Close()
+		}
+		return nil
+	}
+	defer db.Close()
+
+	// 数据直接读入C内存，不在Go中另存一份
+	ptr := C.malloc(C.size_t(db.Size))
+	if _, err := io.ReadFull(db.Reader, unsafe.Slice((*byte)(ptr), db.Size)); err != nil {
+		C.free(ptr)
+		*resultLen = -2
 		return nil
 	}
 
-	*resultLen = C.int(len(data))
-	return (*C.uchar)(C.CBytes(data))
+	*resultLen = C.int(db.Size)
+	return (*C.uchar)(ptr)
 }
 
 //export Rename
@@ -238,6 +272,54 @@ func Has(path *C.char) C.int {
 	return 0
 }
 
+//export Stat
+func Stat(path *C.char) *C.char {
+	initMutex.RLock()
+	if !isInitialized {
+		initMutex.RUnlock()
+		return C.CString(`{"error":"uninitialized"}`)
+	}
+	initMutex.RUnlock()
+
+	if path == nil {
+		return C.CString(`{"error":"` + ERR_PARAMS.Error().Error() + `"}`)
+	}
+
+	sb := StatData(C.GoString(path))
+	if sb == nil {
+		return C.CString(`{"exist":false}`)
+	}
+
+	result := map[string]interface{}{
+		"exist":        true,
+		"size":         sb.Size,
+		"storedSize":   sb.StoredSize,
+		"compressType": sb.CompressType,
+		"refercount":   sb.Refercount,
+		"fingerprint":  hex.EncodeToString(sb.Fingerprint),
+		"timestramp":   sb.Timestramp,
+	}
+	if sb.Node != "" {
+		result["node"], result["offset"] = sb.Node, sb.Offset
+	}
+	if sb.ContentType != "" {
+		result["contentType"] = sb.ContentType
+	}
+	if len(sb.Meta) > 0 {
+		result["meta"] = sb.Meta
+	}
+	if sb.Expire > 0 {
+		result["expire"] = sb.Expire
+	}
+
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return C.CString(`{"error":"json marshal error"}`)
+	}
+
+	return C.CString(string(jsonData))
+}
+
 //export GetKeys
 func GetKeys(fromId C.longlong, limit C.int) *C.char {
 	initMutex.RLock()
@@ -277,6 +359,64 @@ func GetKeys(fromId C.longlong, limit C.int) *C.char {
 	return C.CString(string(jsonData))
 }
 
+//export List
+func List(prefix *C.char, startAfter *C.char, delimiter *C.char, limit C.int) *C.char {
+	initMutex.RLock()
+	if !isInitialized {
+		initMutex.RUnlock()
+		return C.CString(`{"error":"uninitialized"}`)
+	}
+	initMutex.RUnlock()
+
+	var goPrefix, goStartAfter, goDelimiter string
+	if prefix != nil {
+		goPrefix = C.GoString(prefix)
+	}
+	if startAfter != nil {
+		goStartAfter = C.GoString(startAfter)
+	}
+	if delimiter != nil {
+		goDelimiter = C.GoString(delimiter)
+	}
+
+	lr := ListPaths(goPrefix, goStartAfter, goDelimiter, int(limit))
+	if lr == nil {
+		return C.CString(`{"entries":[],"prefixes":[]}`)
+	}
+
+	type EntryInfo struct {
+		Path         string `json:"path"`
+		Size         int64  `json:"size"`
+		Timestramp   int64  `json:"timestramp"`
+		CompressType int32  `json:"compressType"`
+	}
+
+	entries := make([]EntryInfo, len(lr.Entries))
+	for i, e := range lr.Entries {
+		entries[i] = EntryInfo{
+			Path:         e.Path,
+			Size:         e.Size,
+			Timestramp:   e.Timestramp,
+			CompressType: e.CompressType,
+		}
+	}
+
+	result := map[string]interface{}{
+		"entries":  entries,
+		"prefixes": lr.Prefixes,
+	}
+	if lr.Next != "" {
+		result["next"] = lr.Next
+	}
+
+	jsonData, err := json.Marshal(result)
+	if err != nil {
+		return C.CString(`{"error":"json marshal error"}`)
+	}
+
+	return C.CString(string(jsonData))
+}
+
 //export FreeMemory
 func FreeMemory(ptr unsafe.Pointer) {
 	if ptr != nil {
@@ -83,11 +83,14 @@ extern char* Init(char* configJSON);
 extern int IsInit();
 extern char* GetInitStatus();
 extern char* Append(char* name, unsigned char* data, int dataLen, int compress);
+extern char* AppendWithMeta(char* name, unsigned char* data, int dataLen, int compress, char* contentType, char* metaJSON, long long int ttl);
 extern char* Delete(char* path);
 extern unsigned char* Get(char* path, int* resultLen);
 extern char* Rename(char* path, char* newpath);
 extern int Has(char* path);
+extern char* Stat(char* path);
 extern char* GetKeys(long long int fromId, int limit);
+extern char* List(char* prefix, char* startAfter, char* delimiter, int limit);
 extern void FreeMemory(void* ptr);
 extern void FreeString(char* s);
 extern void Close();
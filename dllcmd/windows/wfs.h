@@ -83,11 +83,14 @@ extern __declspec(dllexport) char* Init(char* configJSON);
 extern __declspec(dllexport) int IsInit();
 extern __declspec(dllexport) char* GetInitStatus();
 extern __declspec(dllexport) char* Append(char* name, unsigned char* data, int dataLen, int compress);
+extern __declspec(dllexport) char* AppendWithMeta(char* name, unsigned char* data, int dataLen, int compress, char* contentType, char* metaJSON, long long int ttl);
 extern __declspec(dllexport) char* Delete(char* path);
 extern __declspec(dllexport) unsigned char* Get(char* path, int* resultLen);
 extern __declspec(dllexport) char* Rename(char* path, char* newpath);
 extern __declspec(dllexport) int Has(char* path);
+extern __declspec(dllexport) char* Stat(char* path);
 extern __declspec(dllexport) char* GetKeys(long long int fromId, int limit);
+extern __declspec(dllexport) char* List(char* prefix, char* startAfter, char* delimiter, int limit);
 extern __declspec(dllexport) void FreeMemory(void* ptr);
 extern __declspec(dllexport) void FreeString(char* s);
 extern __declspec(dllexport) void Close();
@@ -3,12 +3,16 @@ module github.com/donnie4w/wfs
 go 1.23.2
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/donnie4w/go-logger v0.28.0
 	github.com/donnie4w/gofer v0.1.8
 	github.com/donnie4w/gothrift v0.0.3
 	github.com/donnie4w/tlnet v0.1.1
 	github.com/hashicorp/golang-lru/v2 v2.0.7
+	github.com/klauspost/compress v1.17.10
+	github.com/klauspost/reedsolomon v1.10.0
 	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/pierrec/lz4/v4 v4.1.21
 	github.com/shirou/gopsutil/v3 v3.24.5
 	github.com/syndtr/goleveldb v1.0.0
 	github.com/yuin/goldmark v1.7.4
@@ -26,7 +30,7 @@ require (
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/google/btree v1.1.3 // indirect
-	github.com/klauspost/compress v1.17.10 // indirect
+	github.com/klauspost/cpuid/v2 v2.2.4 // indirect
 	github.com/lufia/plan9stats v0.0.0-20211012122336-39d0f177ccd0 // indirect
 	github.com/power-devops/perfstat v0.0.0-20210106213030-5aafc221ea8c // indirect
 	github.com/shoenig/go-m1cpu v0.1.6 // indirect
@@ -120,10 +120,13 @@ type pcontext struct {
 	tt       thrift.TTransport
 	mux      *sync.Mutex
 	_isClose bool
+	// reader is the reader of path kept between the chunks of GetChunk
+	reader *sys.DataBean
+	path   string
 }
 
 func newCliContext(tt thrift.TTransport) (cc *pcontext) {
-	cc = &pcontext{goutil.UUID64(), false, tt, &sync.Mutex{}, false}
+	cc = &pcontext{goutil.UUID64(), false, tt, &sync.Mutex{}, false, nil, ""}
 	return
 }
 
@@ -133,6 +136,25 @@ func (t *pcontext) close() {
 	t.mux.Lock()
 	if !t._isClose {
 		t._isClose = true
+		t.closeReader()
 		t.tt.Close()
 	}
 }
+
+// openReader returns the reader of path, the one kept by the last chunk if it reads the same path
+func (t *pcontext) openReader(path string) *sys.DataBean {
+	if t.reader == nil || t.path != path {
+		t.closeReader()
+		if t.reader = sys.GetReader(path); t.reader != nil {
+			t.path = path
+		}
+	}
+	return t.reader
+}
+
+func (t *pcontext) closeReader() {
+	if t.reader != nil {
+		t.reader.Close()
+		t.reader, t.path = nil, ""
+	}
+}
@@ -9,6 +9,7 @@ package level1
 
 import (
 	"context"
+	"io"
 	"strings"
 
 	goutil "github.com/donnie4w/gofer/util"
@@ -51,11 +52,12 @@ func (t *processhandle) Append(ctx context.Context, wf *WfsFile) (_r *WfsAck, _e
 			_r.Ok, _r.Error = false, sys.ERR_OVERSIZE.WfsError()
 			return
 		}
-		compress := sys.CompressType
+		compress := sys.CompressOf(wf.Name)
 		if wf.Compress != nil {
 			compress = int32(*wf.Compress)
 		}
-		if _, err := sys.AppendData(wf.Name, wf.Data, compress); err != nil {
+		mb := &sys.MetaBean{ContentType: wf.GetContentType(), Meta: wf.Meta, Expire: util.ExpireAt(wf.GetTtl())}
+		if _, err := sys.AppendData(wf.Name, wf.Data, compress, mb); err != nil {
 			_r.Ok, _r.Error = false, err.WfsError()
 		}
 	}
@@ -91,7 +93,55 @@ func (t *processhandle) Get(ctx context.Context, path string) (_r *WfsData, _err
 	}
 	_r = &WfsData{}
 	if path != "" {
-		_r.Data = sys.GetData(path)
+		if _r.Data = sys.GetData(path); _r.Data == nil {
+			if sys.Corrupt(path) {
+				_err = sys.ERR_CORRUPT.Error()
+			} else if sb := sys.StatData(path); sb != nil && sb.Size > sys.DataMaxsize {
+				// a multipart file larger than data.maxsize is not read at once, it is read by GetChunk
+				_err = sys.ERR_OVERSIZE.Error()
+			}
+		}
+	}
+	return
+}
+
+// GetChunk reads at most size bytes of path at offset. The reader of path is kept by the connection until
+// its last chunk is read, the chunks read in order go on from where the last one ended.
+func (t *processhandle) GetChunk(ctx context.Context, path string, offset int64, size int32) (_r *WfsData, _err error) {
+	defer util.Recover()
+	cc := ctx2CliContext(ctx)
+	cc.mux.Lock()
+	defer cc.mux.Unlock()
+	if noAuthAndClose(cc) {
+		_err = sys.ERR_AUTH.Error()
+		return
+	}
+	_r = &WfsData{}
+	if path == "" || offset < 0 || size <= 0 {
+		return
+	}
+	db := cc.openReader(path)
+	if db == nil {
+		if sys.Corrupt(path) {
+			_err = sys.ERR_CORRUPT.Error()
+		}
+		return
+	}
+	if offset < db.Size {
+		bs := make([]byte, min(int64(size), db.Size-offset, sys.DataMaxsize))
+		_, err := db.Reader.Seek(offset, io.SeekStart)
+		if err == nil {
+			_, err = io.ReadFull(db.Reader, bs)
+		}
+		if err != nil {
+			cc.closeReader()
+			_err = sys.ERR_CORRUPT.Error()
+			return
+		}
+		_r.Data, offset = bs, offset+int64(len(bs))
+	}
+	if offset >= db.Size {
+		cc.closeReader()
 	}
 	return
 }
@@ -127,6 +177,59 @@ func (t *processhandle) Rename(ctx context.Context, path string, newpath string)
 	return
 }
 
+func (t *processhandle) List(ctx context.Context, prefix string, startAfter string, delimiter string, limit int32) (_r *WfsList, _err error) {
+	defer util.Recover()
+	cc := ctx2CliContext(ctx)
+	cc.mux.Lock()
+	defer cc.mux.Unlock()
+	if noAuthAndClose(cc) {
+		_err = sys.ERR_AUTH.Error()
+		return
+	}
+	_r = &WfsList{Entries: make([]*WfsEntry, 0), Prefixes: make([]string, 0)}
+	if lr := sys.ListPaths(prefix, startAfter, delimiter, int(limit)); lr != nil {
+		for _, e := range lr.Entries {
+			_r.Entries = append(_r.Entries, &WfsEntry{Path: e.Path, Size: e.Size, Timestramp: e.Timestramp, Compress: int8(e.CompressType)})
+		}
+		_r.Prefixes = lr.Prefixes
+		if lr.Next != "" {
+			_r.Next = &lr.Next
+		}
+	}
+	return
+}
+
+func (t *processhandle) Stat(ctx context.Context, path string) (_r *WfsStat, _err error) {
+	defer util.Recover()
+	cc := ctx2CliContext(ctx)
+	cc.mux.Lock()
+	defer cc.mux.Unlock()
+	if noAuthAndClose(cc) {
+		_err = sys.ERR_AUTH.Error()
+		return
+	}
+	_r = &WfsStat{}
+	if path != "" {
+		if sb := sys.StatData(path); sb != nil {
+			compress := int8(sb.CompressType)
+			_r.Exist = true
+			_r.Size, _r.StoredSize, _r.Compress = &sb.Size, &sb.StoredSize, &compress
+			_r.Refercount, _r.Fingerprint, _r.Timestramp = &sb.Refercount, sb.Fingerprint, &sb.Timestramp
+			if sb.Node != "" {
+				_r.Node, _r.Offset = &sb.Node, &sb.Offset
+			}
+			if sb.ContentType != "" {
+				_r.ContentType = &sb.ContentType
+			}
+			_r.Meta = sb.Meta
+			if sb.Expire > 0 {
+				_r.Expire = &sb.Expire
+			}
+		}
+	}
+	return
+}
+
 func auth(name, pwd string) (b bool) {
 	if _r, ok := Admin.GetAdmin(name); ok {
 		b = strings.EqualFold(_r.Pwd, goutil.Md5Str(pwd))
@@ -0,0 +1,413 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// The sealed nodes whose fragments take at least sys.DefragRatio of their file are defragmented by a job that
+// runs unattended within the windows of sys.DefragWindow, sys.DefragNodes nodes at a time, writing at most
+// sys.DefragRate MB per second and busy for at most sys.DefragCPU percent of its time. The job then removes the
+// sealed nodes whose blocks are all deleted, and merges the ones whose live blocks take less than sys.DefragMerge
+// of sys.FileSize. Every job, and every defragmentation started from the admin, is recorded under DEFRAG_.
+const (
+	defragInterval   = 10 * time.Minute
+	maxDefragHistory = 100
+)
+
+// defragJobRun is 1 while a defragmentation job runs
+var defragJobRun int32
+var defragJobMux = &sync.Mutex{}
+var defragJobStat = &sys.DefragBean{}
+
+// defragWindows holds the windows of sys.DefragWindow in minutes of the day
+var defragWindows [][2]int
+
+func init() {
+	sys.DefragStart = func() sys.ERROR { return defragStart(false) }
+	sys.DefragStatus = defragStatus
+}
+
+func initAutoDefrag() {
+	defragWindows = nil
+	for _, w := range sys.DefragWindow {
+		if from, to, ok := parseWindow(w); ok {
+			defragWindows = append(defragWindows, [2]int{from, to})
+		} else {
+			logger.Error("invalid defrag window:", w)
+		}
+	}
+	if sys.DefragRatio > 0 || sys.DefragMerge > 0 {
+		goTask(defragTk)
+	}
+}
+
+// parseWindow parses a window of the form 01:00-05:30, it passes midnight if it ends before it starts
+func parseWindow(w string) (from, to int, ok bool) {
+	ft := strings.SplitN(strings.TrimSpace(w), "-", 2)
+	if len(ft) != 2 {
+		return
+	}
+	var err error
+	if from, err = parseClock(ft[0]); err == nil {
+		to, err = parseClock(ft[1])
+	}
+	return from, to, err == nil && from != to
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	return t.Hour()*60 + t.Minute(), err
+}
+
+// inDefragWindow reports whether t is within a window of sys.DefragWindow, any time is if there is none
+func inDefragWindow(t time.Time) bool {
+	if len(defragWindows) == 0 {
+		return true
+	}
+	m := t.Hour()*60 + t.Minute()
+	for _, w := range defragWindows {
+		if w[0] < w[1] && m >= w[0] && m < w[1] || w[0] > w[1] && (m >= w[0] || m < w[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func defragTk() {
+	ticker := time.NewTicker(defragInterval)
+	defer ticker.Stop()
+	for !stopstat {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			if inDefragWindow(time.Now()) {
+				defragStart(true)
+			}
+		}
+	}
+}
+
+func defragging() bool {
+	return atomic.LoadInt32(&defragRun) > 0
+}
+
+// defragThrottle keeps a defragmentation under its rate in bytes per second, and busy for at most
+// sys.DefragCPU percent of its time
+type defragThrottle struct {
+	rate    float64
+	start   time.Time
+	written int64
+	busy    time.Time
+}
+
+func newDefragThrottle(rate float64) *defragThrottle {
+	return &defragThrottle{rate: rate, start: time.Now(), busy: time.Now()}
+}
+
+func (t *defragThrottle) wait(n int64) {
+	if t == nil {
+		return
+	}
+	t.written += n
+	if d := time.Duration(float64(t.written)/t.rate*float64(time.Second)) - time.Since(t.start); d > 0 {
+		<-time.After(d)
+		t.busy = time.Now()
+	} else if busy := time.Since(t.busy); busy >= 10*time.Millisecond {
+		<-time.After(busy * time.Duration(100-sys.DefragCPU) / time.Duration(sys.DefragCPU))
+		t.busy = time.Now()
+	}
+}
+
+// defragStatus returns a copy of the progress of the running or the last job, with the recorded history
+func defragStatus() *sys.DefragBean {
+	defragJobMux.Lock()
+	db := *defragJobStat
+	db.Node = append([]string{}, db.Node...)
+	defragJobMux.Unlock()
+	db.History = defragHistory()
+	return &db
+}
+
+func setDefragJobStat(f func(db *sys.DefragBean)) {
+	defragJobMux.Lock()
+	defer defragJobMux.Unlock()
+	f(defragJobStat)
+}
+
+// defragStart starts a job on the nodes over sys.DefragRatio and the sparse nodes, an automatic one stops at the
+// end of its window
+func defragStart(auto bool) sys.ERROR {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if !atomic.CompareAndSwapInt32(&defragJobRun, 0, 1) {
+		return sys.ERR_DEFRAG_JOB_UNDERWAY
+	}
+	setDefragJobStat(func(db *sys.DefragBean) {
+		*db = sys.DefragBean{Running: true, Auto: auto, StartTime: time.Now().UnixNano()}
+	})
+	goTask(func() { defragJob(auto) })
+	return nil
+}
+
+func defragJob(auto bool) {
+	defer util.Recover()
+	start := time.Now().UnixNano()
+	run := &stub.WfsDefragBean{Starttime: &start, Auto: &auto, Nodes: new(int32), Reclaimed: new(int64)}
+	defer func() {
+		setDefragJobStat(func(db *sys.DefragBean) { db.Running, db.Node, db.EndTime = false, nil, time.Now().UnixNano() })
+		atomic.StoreInt32(&defragJobRun, 0)
+	}()
+	var nodes []string
+	if !auto || sys.DefragRatio > 0 {
+		nodes = defragCandidates()
+	}
+	setDefragJobStat(func(db *sys.DefragBean) { db.NodeTotal = len(nodes) })
+	mux := &sync.Mutex{}
+	done := func(nodes []string, n int, reclaimed int64, err sys.ERROR) {
+		mux.Lock()
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprint(strings.Join(nodes, ","), ": ", err.WfsError().GetInfo()))
+		}
+		*run.Nodes += int32(n)
+		*run.Reclaimed += reclaimed
+		mux.Unlock()
+		setDefragJobStat(func(db *sys.DefragBean) {
+			db.Nodes += len(nodes)
+			db.Reclaimed += reclaimed
+			for _, node := range nodes {
+				for i, n := range db.Node {
+					if n == node {
+						db.Node = append(db.Node[:i], db.Node[i+1:]...)
+						break
+					}
+				}
+			}
+		})
+	}
+	ch, wg := make(chan string), &sync.WaitGroup{}
+	for i := 0; i < sys.DefragNodes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			th := newDefragThrottle(float64(sys.DefragRate*sys.MB) / float64(sys.DefragNodes))
+			for node := range ch {
+				setDefragJobStat(func(db *sys.DefragBean) { db.Node = append(db.Node, node) })
+				reclaimed, err := defragReclaim(node, th)
+				n := 1
+				if err != nil {
+					n = 0
+				}
+				done([]string{node}, n, reclaimed, err)
+			}
+		}()
+	}
+	for _, node := range nodes {
+		if stopstat || auto && !inDefragWindow(time.Now()) {
+			break
+		}
+		ch <- node
+	}
+	close(ch)
+	wg.Wait()
+	merged := mergeSparse(auto, done)
+	if len(nodes)+merged > 0 {
+		end := time.Now().UnixNano()
+		run.Endtime = &end
+		saveDefragRun(run)
+		logger.Warn("defrag nodes:", run.GetNodes(), ", reclaimed bytes:", run.GetReclaimed(), ", errors:", len(run.Errors))
+	}
+}
+
+// mergeSparse removes the sealed nodes whose blocks are all deleted, and merges the sparse ones if sys.DefragMerge
+// is set. It returns the number of nodes it worked on.
+func mergeSparse(auto bool, done func(nodes []string, n int, reclaimed int64, err sys.ERROR)) (total int) {
+	dead, groups := mergeCandidates()
+	total = len(dead)
+	for _, nodes := range groups {
+		total += len(nodes)
+	}
+	setDefragJobStat(func(db *sys.DefragBean) { db.NodeTotal += total })
+	for _, node := range dead {
+		if stopstat || auto && !inDefragWindow(time.Now()) {
+			return
+		}
+		setDefragJobStat(func(db *sys.DefragBean) { db.Node = append(db.Node, node) })
+		size := nodeFileSize(node)
+		if err := dropNode(node); err != nil {
+			done([]string{node}, 0, 0, err)
+		} else {
+			done([]string{node}, 1, size, nil)
+		}
+	}
+	th := newDefragThrottle(float64(sys.DefragRate * sys.MB))
+	for _, nodes := range groups {
+		if stopstat || auto && !inDefragWindow(time.Now()) {
+			return
+		}
+		setDefragJobStat(func(db *sys.DefragBean) { db.Node = append(db.Node, nodes...) })
+		var before, after int64
+		for _, node := range nodes {
+			before += nodeFileSize(node)
+		}
+		target, merged, err := mergeNodes(nodes, th)
+		for _, node := range nodes {
+			after += nodeFileSize(node)
+		}
+		if after += nodeFileSize(target); after > before {
+			after = before
+		}
+		done(nodes, len(merged), before-after, err)
+	}
+	return
+}
+
+// mergeCandidates returns the sealed nodes whose blocks are all deleted, and the groups of sealed nodes whose live
+// blocks take less than sys.DefragMerge of sys.FileSize, the live blocks of a group fit in one node
+func mergeCandidates() (dead []string, groups [][]string) {
+	lives, hashes := map[string]int64{}, map[int32][]string{}
+	for _, node := range storedNodes(true) {
+		if _, ok := relocating.Load(node); ok || exist(compactKey(node)) {
+			continue
+		}
+		if fb, err := fe.fragAnalysis(node); err == nil && fb.ActualSize > 0 {
+			if live := fb.ActualSize - fb.RmSize; live <= 0 {
+				dead = append(dead, node)
+			} else if float64(live) < sys.DefragMerge*float64(sys.FileSize) {
+				lives[node] = live
+				hashes[nodeHash(node)] = append(hashes[nodeHash(node)], node)
+			}
+		}
+	}
+	for _, nodes := range hashes {
+		sort.Slice(nodes, func(i, j int) bool { return lives[nodes[i]] < lives[nodes[j]] })
+		var group []string
+		var size int64
+		for _, node := range nodes {
+			if size+lives[node] > sys.FileSize {
+				if len(group) > 1 {
+					groups = append(groups, group)
+				}
+				group, size = nil, 0
+			}
+			group, size = append(group, node), size+lives[node]
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return
+}
+
+// defragCandidates returns the sealed nodes whose fragments take at least sys.DefragRatio of their file,
+// the most fragmented first
+func defragCandidates() (_r []string) {
+	frags := map[string]int64{}
+	for _, node := range storedNodes(true) {
+		if _, ok := relocating.Load(node); ok {
+			continue
+		}
+		if fb, err := fe.fragAnalysis(node); err == nil && fb.FileSize > 0 {
+			if frag := fb.FileSize - fb.ActualSize + fb.RmSize; frag > 0 && float64(frag)/float64(fb.FileSize) >= sys.DefragRatio {
+				frags[node] = frag
+				_r = append(_r, node)
+			}
+		}
+	}
+	sort.Slice(_r, func(i, j int) bool { return frags[_r[i]] > frags[_r[j]] })
+	return
+}
+
+// defragReclaim defragments node and returns the bytes its files shrank by
+func defragReclaim(node string, th *defragThrottle) (reclaimed int64, err sys.ERROR) {
+	before := nodeFileSize(node)
+	var target string
+	if target, err = fe.defragNode(node, th); err == nil {
+		if after := nodeFileSize(node) + nodeFileSize(target); after < before {
+			reclaimed = before - after
+		}
+	}
+	return
+}
+
+func nodeFileSize(node string) (size int64) {
+	if fb, err := fe.fragAnalysis(node); err == nil {
+		size = fb.FileSize
+	}
+	return
+}
+
+// defragOne defragments node as the admin asks, the run is recorded
+func defragOne(node string) (err sys.ERROR) {
+	auto, start := false, time.Now().UnixNano()
+	run := &stub.WfsDefragBean{Starttime: &start, Auto: &auto, Nodes: new(int32)}
+	reclaimed, err := defragReclaim(node, nil)
+	if err != nil {
+		run.Errors = []string{fmt.Sprint(node, ": ", err.WfsError().GetInfo())}
+	} else {
+		*run.Nodes = 1
+	}
+	end := time.Now().UnixNano()
+	run.Reclaimed, run.Endtime = &reclaimed, &end
+	saveDefragRun(run)
+	return
+}
+
+func bytesToWfsDefragBean(bs []byte) (wdb *stub.WfsDefragBean) {
+	wdb = &stub.WfsDefragBean{}
+	if util.PDecode(bs, wdb) != nil || wdb.GetStarttime() == 0 {
+		wdb = nil
+	}
+	return
+}
+
+func wfsDefragBeanToBytes(b *stub.WfsDefragBean) (bs []byte) {
+	bs, _ = util.PEncode(b)
+	return
+}
+
+// saveDefragRun records run, only the last maxDefragHistory runs are kept
+func saveDefragRun(run *stub.WfsDefragBean) {
+	if err := wfsdb.Put(append(DEFRAG_, goutil.Int64ToBytes(run.GetStarttime())...), wfsDefragBeanToBytes(run)); err != nil {
+		logger.Error(err)
+		return
+	}
+	if keys, err := wfsdb.GetKeysPrefixLimit(DEFRAG_, DEFRAG_, 2*maxDefragHistory); err == nil && len(keys) > maxDefragHistory {
+		wfsdb.Batch(nil, keys[:len(keys)-maxDefragHistory])
+	}
+}
+
+// defragHistory returns the recorded runs, the last first
+func defragHistory() (_r []*sys.DefragRun) {
+	keys, err := wfsdb.GetKeysPrefixLimit(DEFRAG_, DEFRAG_, 2*maxDefragHistory)
+	if err != nil {
+		return
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if v, err := wfsdb.Get(keys[i]); err == nil && v != nil {
+			if wdb := bytesToWfsDefragBean(v); wdb != nil {
+				_r = append(_r, &sys.DefragRun{Auto: wdb.GetAuto(), Nodes: int(wdb.GetNodes()), Reclaimed: wdb.GetReclaimed(), StartTime: wdb.GetStarttime(), EndTime: wdb.GetEndtime(), Errors: wdb.Errors})
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+func TestDefragWindow(t *testing.T) {
+	defer func() { defragWindows = nil }()
+	for w, ok := range map[string]bool{"01:00-05:30": true, " 22:00 - 02:00 ": true, "01:00": false, "25:00-02:00": false, "03:00-03:00": false} {
+		if _, _, b := parseWindow(w); b != ok {
+			t.Fatalf("window %q: %v", w, b)
+		}
+	}
+	at := func(clock string) time.Time {
+		c, _ := time.Parse("15:04", clock)
+		return c
+	}
+	if defragWindows = nil; !inDefragWindow(at("12:00")) {
+		t.Fatal("any time is within no window")
+	}
+	// the second window passes midnight
+	defragWindows = [][2]int{{60, 330}, {22 * 60, 2 * 60}}
+	for clock, in := range map[string]bool{"00:30": true, "01:00": true, "05:29": true, "05:30": false, "12:00": false, "21:59": false, "22:00": true, "23:59": true} {
+		if inDefragWindow(at(clock)) != in {
+			t.Fatal("window at", clock)
+		}
+	}
+}
+
+// sealedNodes fills sealed nodes with the files f<i> and returns them with the files of every node
+func sealedNodes(n int) (nodes []string, paths map[string][]string) {
+	paths = make(map[string][]string)
+	for i := 0; len(storedNodes(true)) < n; i++ {
+		path := fmt.Sprint("f", i)
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		node := fe.stat(path).Node
+		if len(paths[node]) == 0 {
+			nodes = append(nodes, node)
+		}
+		paths[node] = append(paths[node], path)
+	}
+	return nodes[:n], paths
+}
+
+func TestDefragJob(t *testing.T) {
+	fileSize, ratio := sys.FileSize, sys.DefragRatio
+	defer func() { sys.FileSize, sys.DefragRatio = fileSize, ratio }()
+	sys.FileSize, sys.DefragRatio = 1<<16, 0.5
+	startStore(t.TempDir())
+	defer CloseAll()
+	nodes, paths := sealedNodes(2)
+	// the first node is fragmented, every block of the second one is deleted
+	var kept []string
+	for i, path := range paths[nodes[0]] {
+		if i%4 == 0 {
+			kept = append(kept, path)
+		} else {
+			fe.delData(path)
+		}
+	}
+	for _, path := range paths[nodes[1]] {
+		fe.delData(path)
+	}
+	// the most fragmented node is defragmented first
+	if cs := defragCandidates(); len(cs) != 2 || cs[0] != nodes[1] || cs[1] != nodes[0] {
+		t.Fatal("candidates:", cs)
+	}
+	if err := defragStart(false); err != nil {
+		t.Fatal(err)
+	}
+	stopTasks()
+	for _, node := range nodes {
+		if exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+			t.Fatal("node is not defragmented:", node)
+		}
+	}
+	for _, path := range kept {
+		if string(fe.getData(path)) != "the data of "+path {
+			t.Fatal("data of", path)
+		}
+	}
+	db := defragStatus()
+	if db.Running || db.Nodes != 2 || len(db.History) != 1 {
+		t.Fatalf("%+v", db)
+	}
+	if run := db.History[0]; run.Auto || run.Nodes != 2 || run.Reclaimed <= 0 || len(run.Errors) > 0 {
+		t.Fatalf("%+v", run)
+	}
+	consistent(t)
+}
+
+func TestDefragOutOfWindow(t *testing.T) {
+	fileSize, ratio := sys.FileSize, sys.DefragRatio
+	defer func() { sys.FileSize, sys.DefragRatio, defragWindows = fileSize, ratio, nil }()
+	sys.FileSize, sys.DefragRatio = 1<<16, 0.5
+	startStore(t.TempDir())
+	defer CloseAll()
+	nodes, paths := sealedNodes(1)
+	for _, path := range paths[nodes[0]] {
+		fe.delData(path)
+	}
+	// an automatic job stops at the end of its window
+	m := time.Now().Hour()*60 + time.Now().Minute()
+	defragWindows = [][2]int{{(m + 60) % 1440, (m + 120) % 1440}}
+	defragStart(true)
+	stopTasks()
+	if !exist(append(ENDOFFSET_, nodeBytes(nodes[0])...)) {
+		t.Fatal("node is defragmented out of the window")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/donnie4w/gofer/compress"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/pierrec/lz4/v4"
+)
+
+// The codecs of the compress types. 0 stores the data as it is, 1 is snappy, 2 is zstd and 3 to 11
+// are the zlib levels 1 to 9.
+const (
+	// COMPRESS_AUTO picks the codec of every block by its data, the codec applied is the one recorded
+	COMPRESS_AUTO = -1
+	COMPRESS_LZ4  = 12
+	// the zstd levels fastest, default, better and best
+	COMPRESS_ZSTD_FASTEST = 13
+	COMPRESS_ZSTD_BEST    = 16
+	// the brotli qualities 0 to 11
+	COMPRESS_BROTLI     = 17
+	COMPRESS_BROTLI_MAX = 28
+)
+
+const (
+	autoMinSize    = 256
+	autoSampleSize = 64 << 10
+	// the sample must shrink below this ratio to be compressed
+	autoRatio = 0.9
+)
+
+// compressedTypes are the formats detected by http.DetectContentType whose data is compressed already
+var compressedTypes = map[string]bool{
+	"image/gif": true, "image/png": true, "image/jpeg": true, "image/webp": true,
+	"audio/mpeg": true, "application/ogg": true, "video/avi": true, "video/mp4": true, "video/webm": true,
+	"font/woff": true, "font/woff2": true, "application/x-gzip": true, "application/zip": true, "application/x-rar-compressed": true,
+}
+
+func init() {
+	sys.CompressOf = compressOf
+}
+
+// compressOf returns the compress type of the longest prefix of path configured in sys.CompressRules, or sys.CompressType
+func compressOf(path string) (_r int32) {
+	_r = sys.CompressType
+	pre := ""
+	for k, c := range sys.CompressRules {
+		if strings.HasPrefix(path, k) && len(k) >= len(pre) {
+			pre, _r = k, c
+		}
+	}
+	return
+}
+
+func validCodec(compressType int32) bool {
+	return compressType >= 0 && compressType <= COMPRESS_BROTLI_MAX
+}
+
+// compressBlock compresses bs of path by compressType and returns the compress type applied, and the
+// dictionary if the data is compressed with one. COMPRESS_AUTO is resolved by the data, and keeps the data
+// as it is when it does not shrink. The data of an unknown compress type or of a failing codec is kept as it is.
+func compressBlock(path string, bs []byte, compressType int32) (int32, int32, []byte) {
+	auto := compressType == COMPRESS_AUTO
+	if auto {
+		compressType = autoCodec(bs)
+	}
+	if compressType != 0 && validCodec(compressType) {
+		id, cbs := dictCompress(path, bs, compressType)
+		if id == 0 {
+			cbs = praseCompress(bs, compressType)
+		}
+		if len(cbs) > 0 && (!auto || len(cbs) < len(bs)) {
+			return compressType, id, cbs
+		}
+	}
+	return 0, 0, bs
+}
+
+// autoCodec stores the small data, the compressed formats and the data whose sample does not shrink
+// with snappy as they are, the others with zstd. The sample is taken from the middle of the data,
+// the header of a format does not tell how its content compresses. The small data is tried with zstd
+// if it may be compressed with a dictionary.
+func autoCodec(bs []byte) int32 {
+	if compressedTypes[http.DetectContentType(bs)] {
+		return 0
+	}
+	if len(bs) < autoMinSize {
+		if sys.ZstdDict {
+			return 2
+		}
+		return 0
+	}
+	sample := bs
+	if len(sample) > autoSampleSize {
+		sample = bs[(len(bs)-autoSampleSize)/2:][:autoSampleSize]
+	}
+	if float64(len(compress.Snappy(sample))) > autoRatio*float64(len(sample)) {
+		return 0
+	}
+	return 2
+}
+
+func lz4Compress(bs []byte) []byte {
+	var buf bytes.Buffer
+	w := lz4.NewWriter(&buf)
+	if _, err := w.Write(bs); err != nil || w.Close() != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func lz4Uncompress(bs []byte) (_r []byte) {
+	_r, _ = io.ReadAll(lz4.NewReader(bytes.NewReader(bs)))
+	return
+}
+
+func brotliCompress(bs []byte, quality int) []byte {
+	var buf bytes.Buffer
+	w := brotli.NewWriterLevel(&buf, quality)
+	if _, err := w.Write(bs); err != nil || w.Close() != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func brotliUncompress(bs []byte) (_r []byte) {
+	_r, _ = io.ReadAll(brotli.NewReader(bytes.NewReader(bs)))
+	return
+}
+
+func zlibCompress(bs []byte, level int) []byte {
+	var buf bytes.Buffer
+	w, err := zlib.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil
+	}
+	if _, err = w.Write(bs); err != nil || w.Close() != nil {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// zlibUncompress also reads the blocks written before the zlib stream was closed, they hold the whole data
+// but end without checksum
+func zlibUncompress(bs []byte) []byte {
+	r, err := zlib.NewReader(bytes.NewReader(bs))
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+func TestCodecs(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	for ct := int32(0); ct <= COMPRESS_BROTLI_MAX; ct++ {
+		path := fmt.Sprint("c/", ct)
+		data := bytes.Repeat([]byte("the data compressed by the codec of "+path+" "), 512)
+		if _, err := fe.append(path, data, ct, nil); err != nil {
+			t.Fatal(err)
+		}
+		sb := fe.stat(path)
+		if sb == nil || sb.CompressType != ct || ct != 0 && sb.StoredSize >= sb.Size {
+			t.Fatalf("compress type %d is stored as %v", ct, sb)
+		}
+		if !bytes.Equal(fe.getData(path), data) {
+			t.Fatalf("data of compress type %d", ct)
+		}
+		db := fe.getReader(path)
+		db.Reader.Seek(int64(len(data)/2), io.SeekStart)
+		got, err := io.ReadAll(db.Reader)
+		if db.Close(); err != nil || !bytes.Equal(got, data[len(data)/2:]) {
+			t.Fatalf("reader of compress type %d reads %d bytes, %v", ct, len(got), err)
+		}
+	}
+	consistent(t)
+}
+
+func TestAutoCodec(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	compressType, rules := sys.CompressType, sys.CompressRules
+	defer func() { sys.CompressType, sys.CompressRules = compressType, rules }()
+	sys.CompressType, sys.CompressRules = 1, map[string]int32{"img/": 0, "log/": COMPRESS_AUTO, "log/zstd/": 2}
+	for path, ct := range map[string]int32{"a": 1, "img/a.png": 0, "log/a": COMPRESS_AUTO, "log/zstd/a": 2, "logs": 1} {
+		if compressOf(path) != ct {
+			t.Fatalf("compress type of %s: %d", path, compressOf(path))
+		}
+	}
+	random := make([]byte, 4096)
+	rand.New(rand.NewSource(1)).Read(random)
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte("the pixels "), 512)...)
+	// the small data, the compressed formats and the data that does not shrink are kept as they are
+	for path, c := range map[string]struct {
+		data []byte
+		ct   int32
+	}{
+		"log/text":   {bytes.Repeat([]byte("a line of the log "), 512), 2},
+		"log/small":  {[]byte("a line of the log"), 0},
+		"log/random": {random, 0},
+		"log/png":    {png, 0},
+	} {
+		if _, err := fe.append(path, c.data, compressOf(path), nil); err != nil {
+			t.Fatal(err)
+		}
+		if sb := fe.stat(path); sb.CompressType != c.ct || !bytes.Equal(fe.getData(path), c.data) {
+			t.Fatalf("%s is stored with compress type %d", path, sb.CompressType)
+		}
+	}
+	consistent(t)
+}
@@ -0,0 +1,400 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/donnie4w/go-logger/logger"
+	. "github.com/donnie4w/gofer/mmap"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// A node is defragmented by copying its live blocks to a new node, the target. The beans of the copied blocks are
+// pointed to the target every compactBatch blocks, in one batch with the checkpoint of the copy under COMPACT_, so
+// that a block is read from the node or from the target by its bean all along. Once no live block is left in the
+// node, it is removed. Sparse nodes are merged the same way, one after another into a shared target.
+const (
+	compactBatch  = 256
+	compactPasses = 3
+)
+
+type compaction struct {
+	node   string
+	target string
+	mm     *Mmap
+	step   int64
+	end    int64
+	offset int64
+	length int64
+	reseal bool
+}
+
+type compactMove struct {
+	bidBs []byte
+	from  int64
+	to    int64
+	size  int64
+}
+
+func compactKey(node string) []byte {
+	return append(COMPACT_, nodeBytes(node)...)
+}
+
+func bytesToWfsCompactBean(bs []byte) (wcb *stub.WfsCompactBean) {
+	wcb = &stub.WfsCompactBean{}
+	if util.PDecode(bs, wcb) != nil || wcb.GetNode() == "" {
+		wcb = nil
+	}
+	return
+}
+
+func wfsCompactBeanToBytes(b *stub.WfsCompactBean) (bs []byte) {
+	bs, _ = util.PEncode(b)
+	return
+}
+
+// resumeCompactions finishes the defragmentations interrupted by a stop
+func resumeCompactions() {
+	keys, err := wfsdb.GetKeysPrefixLimit(COMPACT_, COMPACT_, maxListLimit)
+	if err != nil {
+		return
+	}
+	for _, k := range keys {
+		if len(k) == len(COMPACT_)+8 {
+			if node := nodeName(k[len(COMPACT_):]); node != "" {
+				if _, err := fe.defragNode(node, nil); err != nil {
+					logger.Error("resume defrag of node ", node, " failed:", err)
+				}
+			}
+		}
+	}
+}
+
+// openCompaction returns the compaction of node from its checkpoint, or a new one into the target of into, or
+// into a new target of size bytes at least, it returns nil if node does not exist
+func openCompaction(node string, into *compaction, size int64) (c *compaction, err error) {
+	c = &compaction{node: node, step: int64(hashLen(nodeHash(node)))}
+	if endBs, err := wfsdb.Get(append(ENDOFFSET_, nodeBytes(node)...)); err == nil && endBs != nil {
+		c.end = goutil.BytesToInt64(endBs)
+	}
+	if v, err := wfsdb.Get(compactKey(node)); err == nil && v != nil {
+		if wcb := bytesToWfsCompactBean(v); wcb != nil {
+			c.target, c.offset, c.length = wcb.GetNode(), wcb.GetOffset(), wcb.GetLength()
+		}
+	}
+	if c.target == "" {
+		if c.end <= 0 {
+			return nil, nil
+		}
+		if into != nil {
+			c.target, c.mm, c.length = into.target, into.mm, into.length
+			err = wfsdb.Put(compactKey(node), c.checkpoint())
+		} else {
+			err = c.create()
+		}
+		if err != nil {
+			return
+		}
+	}
+	c.reseal = !bytes.Equal(nodeSecret(node).wrapped, nodeSecret(c.target).wrapped)
+	if c.end > 0 {
+		if c.mm != nil {
+			err = os.Truncate(getpathBynode(c.target), c.mm.FileSize())
+		} else {
+			if size < c.length+c.end {
+				size = c.length + c.end
+			}
+			err = c.open(size)
+		}
+	}
+	return
+}
+
+// create records a new target in the directory of the node, with its hash and data key
+func (c *compaction) create() (err error) {
+	c.target = intToStr(uint64(util.CreateNodeId()))
+	nodeDirs.Store(c.target, nodeDir(c.node))
+	nodeHashes.Store(c.target, nodeHash(c.node))
+	if nk := nodeSecret(c.node); len(nk.wrapped) > 0 {
+		nodeKeys.Store(c.target, nk)
+		if err = writeKeyFile(c.target, nk.wrapped); err != nil {
+			return
+		}
+	}
+	tidbs := nodeBytes(c.target)
+	endBs, ckBs := append(ENDOFFSET_, tidbs...), compactKey(c.node)
+	am := map[*[]byte][]byte{&endBs: goutil.Int64ToBytes(0), &tidbs: wfsNodeBeanToBytes(newNodeBean(c.target, 0)), &ckBs: c.checkpoint()}
+	return wfsdb.BatchPut(am)
+}
+
+// open maps the file of the target, it is at least size bytes so that the copied blocks fit in it
+func (c *compaction) open(size int64) (err error) {
+	path := getpathBynode(c.target)
+	if err = os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return
+	}
+	var f *os.File
+	if f, err = util.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666); err != nil {
+		return
+	}
+	if fi, e := f.Stat(); e != nil || fi.Size() < size {
+		if err = f.Truncate(size); err != nil {
+			f.Close()
+			return
+		}
+	}
+	if c.mm, err = NewMMAP(f, c.length); err != nil {
+		f.Close()
+		return
+	}
+	dataEg.reSetMMap(c.target, c.mm)
+	return
+}
+
+func (c *compaction) checkpoint() []byte {
+	return wfsCompactBeanToBytes(&stub.WfsCompactBean{Node: &c.target, Offset: &c.offset, Length: &c.length})
+}
+
+// run copies the live blocks of the node to the target. The blocks whose beans are written again with the node
+// meanwhile are copied by the next pass, the node is kept if some are left after compactPasses.
+func (c *compaction) run(th *defragThrottle) (err error) {
+	retire := true
+	if c.end > 0 {
+		full := c.offset == 0
+		for pass := 0; ; pass++ {
+			var copied int
+			if copied, err = c.copy(th); err != nil {
+				return
+			}
+			if copied == 0 && full {
+				break
+			}
+			if pass == compactPasses-1 {
+				retire = false
+				break
+			}
+			c.offset, full = 0, true
+		}
+	}
+	return c.finish(retire)
+}
+
+// copy copies the live blocks from the offset of the checkpoint to the end of the node
+func (c *compaction) copy(th *defragThrottle) (copied int, err error) {
+	moves := make([]*compactMove, 0, compactBatch)
+	for c.offset+c.step+4 <= c.end {
+		if stopstat {
+			err = errors.New("the service is stopped")
+			break
+		}
+		hd, ok := dataEg.readData(c.node, c.offset, c.step+4)
+		if !ok {
+			err = errors.New("the node cannot be read")
+			break
+		}
+		size := int64(goutil.BytesToInt32(hd[c.step:]))
+		if size <= 0 {
+			break
+		}
+		bidBs := bytes.Clone(hd[:c.step])
+		if liveBean(bidBs, c.node, c.offset) != nil {
+			bs, ok := dataEg.readData(c.node, c.offset, c.step+4+size)
+			if !ok {
+				err = errors.New("the node cannot be read")
+				break
+			}
+			if c.reseal {
+				if bs, err = c.resealBlock(bidBs, bs[c.step+4:]); err != nil {
+					break
+				}
+			}
+			to, e := c.mm.Append(bs)
+			if e != nil {
+				err = e
+				break
+			}
+			c.length = to + int64(len(bs))
+			moves = append(moves, &compactMove{bidBs: bidBs, from: c.offset, to: to, size: int64(len(bs)) - c.step - 4})
+			copied++
+			th.wait(int64(len(bs)))
+		}
+		c.offset += c.step + 4 + size
+		if len(moves) >= compactBatch {
+			if err = c.swap(moves); err != nil {
+				return
+			}
+			moves = moves[:0]
+		}
+	}
+	if e := c.swap(moves); err == nil {
+		err = e
+	}
+	return
+}
+
+// resealBlock returns the block bidBs of the node encrypted as the target is
+func (c *compaction) resealBlock(bidBs, bs []byte) (_r []byte, err error) {
+	data := openBlock(c.node, bidBs, bs)
+	if data == nil {
+		return nil, errors.New("the block cannot be decrypted")
+	}
+	if data, err = sealBlock(c.target, bidBs, data); err == nil {
+		_r = append(append(bytes.Clone(bidBs), goutil.Int32ToBytes(int32(len(data)))...), data...)
+	}
+	return
+}
+
+// swap points the beans of moves to the target in one batch with the checkpoint
+func (c *compaction) swap(moves []*compactMove) (err error) {
+	if len(moves) > 0 {
+		if err = c.mm.Flush(); err != nil {
+			return
+		}
+	}
+	// the beans are read and moved with no batch committed meanwhile, which would put them back with the node
+	commitMux.Lock()
+	defer commitMux.Unlock()
+	am := make(map[*[]byte][]byte, len(moves)+2)
+	for _, m := range moves {
+		if bs := c.moved(m); bs != nil {
+			am[&m.bidBs] = bs
+		}
+	}
+	endBs, ckBs := append(ENDOFFSET_, nodeBytes(c.target)...), compactKey(c.node)
+	am[&endBs], am[&ckBs] = goutil.Int64ToBytes(c.length), c.checkpoint()
+	if err = wfsdb.BatchPut(am); err == nil {
+		for _, m := range moves {
+			cacheDel(m.bidBs)
+		}
+		fault("swap")
+	}
+	return
+}
+
+// moved returns the bean of the block of m at its offset in the target, or nil if the block is not live at its
+// offset in the node anymore. It runs under commitMux and takes no lock of the block: an append holds the lock of
+// its block while it commits, and a batch that read the bean before is put at the target by follow.
+func (c *compaction) moved(m *compactMove) []byte {
+	if wfb := liveBean(m.bidBs, c.node, m.from); wfb != nil {
+		target, to, size := c.target, m.to, m.size
+		wfb.Storenode, wfb.Offset, wfb.Size = &target, &to, &size
+		return wfsFileBeanToBytes(wfb)
+	}
+	return nil
+}
+
+// finish cuts the file of the target to its length and removes the node if retire is set, a target without
+// blocks is removed
+func (c *compaction) finish(retire bool) (err error) {
+	if c.length == 0 {
+		removeNode(c.target)
+	} else if err = os.Truncate(getpathBynode(c.target), c.length); err != nil {
+		logger.Error(err)
+	}
+	if retire && c.end > 0 {
+		if a := lastAccess(c.node); tierOn() && (!exist(atimeKey(c.target)) || a > lastAccess(c.target)) {
+			wfsdb.Put(atimeKey(c.target), goutil.Int64ToBytes(a))
+		}
+		removeNode(c.node)
+	}
+	return wfsdb.Del(compactKey(c.node))
+}
+
+// mergeNodes packs the live blocks of the sparse nodes into one new node, one node after another, and removes
+// them. It returns the target and the nodes merged into it.
+func mergeNodes(nodes []string, th *defragThrottle) (target string, merged []string, err sys.ERROR) {
+	var size int64
+	for _, node := range nodes {
+		if fb, e := fe.fragAnalysis(node); e == nil {
+			size += fb.ActualSize
+		}
+	}
+	atomic.AddInt32(&defragRun, 1)
+	defer atomic.AddInt32(&defragRun, -1)
+	var into *compaction
+	for _, node := range nodes {
+		if stopstat {
+			break
+		}
+		c, e := compactNode(node, into, size, th)
+		if e != nil {
+			if err = e; c != nil {
+				break
+			}
+			continue
+		}
+		merged = append(merged, node)
+		if into == nil && c.length > 0 {
+			defragmap.Store(c.target, c.target)
+			defer defragmap.Delete(c.target)
+		}
+		if c.length > 0 {
+			into, target = c, c.target
+		}
+	}
+	if target != "" {
+		dataEg.sealNode(target)
+	}
+	return
+}
+
+// dropNode removes the sealed node whose blocks are all deleted. A node whose removed size is counted wrong may
+// still have a live block, it is defragmented instead.
+func dropNode(node string) (err sys.ERROR) {
+	if _, ok := defragmap.LoadOrStore(node, ""); ok {
+		return sys.ERR_DEFRAG_UNDERWAY
+	}
+	if currentNode(node) {
+		defragmap.Delete(node)
+		return sys.ERR_DEFRAG_FORBID
+	}
+	if _, ok := relocating.Load(node); ok {
+		defragmap.Delete(node)
+		return sys.ERR_DEFRAG_UNDERWAY
+	}
+	if liveBlocks(node) {
+		defragmap.Delete(node)
+		_, err = fe.defragNode(node, nil)
+		return
+	}
+	removeNode(node)
+	defragmap.Delete(node)
+	return
+}
+
+// liveBlocks tells whether a block of node before its end offset still has its bean, or the node cannot be read
+func liveBlocks(node string) bool {
+	var end int64
+	if endBs, err := wfsdb.Get(append(ENDOFFSET_, nodeBytes(node)...)); err == nil && endBs != nil {
+		end = goutil.BytesToInt64(endBs)
+	}
+	step := int64(hashLen(nodeHash(node)))
+	for offset := int64(0); offset+step+4 <= end; {
+		hd, ok := dataEg.readData(node, offset, step+4)
+		if !ok {
+			return true
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size <= 0 {
+			break
+		}
+		if liveBean(bytes.Clone(hd[:step]), node, offset) != nil {
+			return true
+		}
+		offset += step + 4 + size
+	}
+	return false
+}
@@ -17,9 +17,27 @@ var (
 	APPENDLOCK_    = []byte{2}
 	RESETMMAPLOCK_ = []byte{3}
 	OPENMMAPLOCK_  = []byte{4}
+	MANIFEST_      = []byte{5}
 	PATH_PRE       = []byte{0, 0}
 	CURRENT        = append([]byte{6}, goutil.Int64ToBytes(1<<50)...)
 	SEQ            = append([]byte{7}, goutil.Int64ToBytes(1<<51)...)
 	PATH_SEQ       = append([]byte{8}, goutil.Int64ToBytes(1<<52)...)
 	COUNT          = append([]byte{9}, goutil.Int64ToBytes(1<<53)...)
+	// the prefixes below are clipped to their length, the keys appended to them never share their array
+	UPLOAD_     = append([]byte{10}, goutil.Int64ToBytes(1<<54)...)[:9:9]
+	TTL_        = append([]byte{11}, goutil.Int64ToBytes(1<<55)...)[:9:9]
+	EXPIRE_     = append([]byte{12}, goutil.Int64ToBytes(1<<56)...)[:9:9]
+	QUARANTINE_ = append([]byte{13}, goutil.Int64ToBytes(1<<57)...)[:9:9]
+	INTENT_     = append([]byte{14}, goutil.Int64ToBytes(1<<58)...)[:9:9]
+	HASH        = append([]byte{15}, goutil.Int64ToBytes(1<<59)...)[:9:9]
+	DICT_       = append([]byte{16}, goutil.Int64ToBytes(1<<60)...)[:9:9]
+	ATIME_      = append([]byte{17}, goutil.Int64ToBytes(1<<61)...)[:9:9]
+	DEFRAG_     = append([]byte{18}, goutil.Int64ToBytes(1<<62)...)[:9:9]
+	COMPACT_    = append([]byte{19}, goutil.Int64ToBytes(1<<49)...)[:9:9]
+)
+
+const (
+	maxListLimit   = 1000
+	maxExpireSweep = 1000
+	maxFindings    = 1000
 )
@@ -0,0 +1,579 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/donnie4w/go-logger/logger"
+	gkeystore "github.com/donnie4w/gofer/keystore"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/keystore"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// The blocks of an encrypted node are sealed with AES-256-GCM by the data key of the node, the fingerprint of
+// a block authenticates its payload. The data key is kept in the node bean wrapped by the master key, as the id of
+// the master key, the nonce and the sealed data key. A copy of the wrapped key is kept in wfsdata/wfskey for rebuild.
+const (
+	masterKeyName = "WFSMASTERKEY"
+	// the suffix of the master key that a rotation wraps the data keys with before it becomes the current one
+	pendingSuffix = ".new"
+	masterIdLen   = 8
+	dataKeyLen    = 32
+)
+
+type masterKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+type nodeKey struct {
+	wrapped []byte
+	aead    cipher.AEAD
+}
+
+// masters are the master keys, the current one first followed by the pending one of a rotation
+var masters atomic.Value
+var masterMux = &sync.Mutex{}
+
+// nodeKeys caches the data key of every node, a node without key stores its blocks as they are
+var nodeKeys = &sync.Map{}
+
+// relocating holds the nodes whose blocks or files are moved, they are not defragmented meanwhile
+var relocating = &sync.Map{}
+
+var encrypting int32
+
+func init() {
+	sys.RotateKey = rotateKey
+}
+
+func keyDir() string {
+	return sys.WFSDATA + "/wfskey"
+}
+
+func keyFile(node string) string {
+	return keyDir() + "/" + node
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts bs with a random nonce that the result begins with
+func seal(aead cipher.AEAD, bs, ad []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(bs)+aead.Overhead())
+	rand.Read(nonce)
+	return aead.Seal(nonce, nonce, bs, ad)
+}
+
+func open(aead cipher.AEAD, bs, ad []byte) ([]byte, error) {
+	if len(bs) < aead.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	return aead.Open(nil, bs[:aead.NonceSize()], bs[aead.NonceSize():], ad)
+}
+
+func newMasterKey(key []byte) (*masterKey, error) {
+	if len(key) != dataKeyLen {
+		return nil, errors.New("the master key must be 32 bytes")
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	id := sha256.Sum256(key)
+	return &masterKey{id: id[:masterIdLen], aead: aead}, nil
+}
+
+func randomKey() []byte {
+	key := make([]byte, dataKeyLen)
+	rand.Read(key)
+	return key
+}
+
+func openKeystore() error {
+	if gkeystore.KeyStore == nil {
+		return keystore.InitAdmin(sys.WFSDATA + "/logs")
+	}
+	return nil
+}
+
+// readMasterKey reads the current or the pending master key from the key file, or from the keystore
+// if no key file is configured. The key file holds the key in hex or the 32 bytes of it.
+func readMasterKey(pending bool) (key []byte, err error) {
+	if sys.EncryptKeyFile != "" {
+		name := sys.EncryptKeyFile
+		if pending {
+			name += pendingSuffix
+		}
+		if key, err = os.ReadFile(name); err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+			}
+			return
+		}
+		if k, e := hex.DecodeString(strings.TrimSpace(string(key))); e == nil {
+			key = k
+		}
+		return
+	}
+	if err = openKeystore(); err != nil {
+		return
+	}
+	name := masterKeyName
+	if pending {
+		name += pendingSuffix
+	}
+	if v, ok := keystore.Admin.GetOther(name); ok {
+		return hex.DecodeString(v)
+	}
+	return
+}
+
+func writeMasterKey(key []byte, pending bool) (err error) {
+	if sys.EncryptKeyFile != "" {
+		name := sys.EncryptKeyFile
+		if pending {
+			name += pendingSuffix
+		}
+		return os.WriteFile(name, []byte(hex.EncodeToString(key)), 0600)
+	}
+	if err = openKeystore(); err != nil {
+		return
+	}
+	name := masterKeyName
+	if pending {
+		name += pendingSuffix
+	}
+	keystore.Admin.PutOther(name, hex.EncodeToString(key))
+	return
+}
+
+func removePendingKey() {
+	if sys.EncryptKeyFile != "" {
+		os.Remove(sys.EncryptKeyFile + pendingSuffix)
+	} else if openKeystore() == nil {
+		keystore.Admin.DelOther(masterKeyName + pendingSuffix)
+	}
+}
+
+// loadMasterKeys reads the master keys. Without a key file a master key is created in the keystore when
+// encryption is on; a configured key file must exist.
+func loadMasterKeys() (_r []*masterKey, err error) {
+	masterMux.Lock()
+	defer masterMux.Unlock()
+	if ms, ok := masters.Load().([]*masterKey); ok {
+		return ms, nil
+	}
+	key, err := readMasterKey(false)
+	if err != nil {
+		return
+	}
+	if key == nil && sys.Encrypt {
+		if sys.EncryptKeyFile != "" {
+			return nil, errors.New("the key file does not exist:" + sys.EncryptKeyFile)
+		}
+		key = randomKey()
+		if err = writeMasterKey(key, false); err != nil {
+			return
+		}
+	}
+	for i, pending := range []bool{false, true} {
+		if i > 0 {
+			if key, err = readMasterKey(pending); err != nil {
+				return
+			}
+		}
+		if key != nil {
+			var m *masterKey
+			if m, err = newMasterKey(key); err != nil {
+				return
+			}
+			_r = append(_r, m)
+		}
+	}
+	masters.Store(_r)
+	return
+}
+
+// wrapKey seals the data key with the master key, the id of the master key tells which one unwraps it
+func wrapKey(key []byte, m *masterKey) []byte {
+	return append(bytes.Clone(m.id), seal(m.aead, key, m.id)...)
+}
+
+func unwrapKey(wrapped []byte) ([]byte, error) {
+	if len(wrapped) <= masterIdLen {
+		return nil, errors.New("wrapped key is too short")
+	}
+	ms, err := loadMasterKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range ms {
+		if bytes.Equal(m.id, wrapped[:masterIdLen]) {
+			return open(m.aead, wrapped[masterIdLen:], m.id)
+		}
+	}
+	return nil, errors.New("the master key of the data key is not found")
+}
+
+// useNodeKey caches the data key of node from its wrapped key
+func useNodeKey(node string, wrapped []byte) (nk *nodeKey, err error) {
+	nk = &nodeKey{wrapped: wrapped}
+	if len(wrapped) > 0 {
+		var key []byte
+		if key, err = unwrapKey(wrapped); err != nil {
+			return
+		}
+		if nk.aead, err = newAEAD(key); err != nil {
+			return
+		}
+	}
+	nodeKeys.Store(node, nk)
+	return
+}
+
+// nodeSecret returns the data key of node, its aead is nil if node is not encrypted or its key cannot be unwrapped
+func nodeSecret(node string) *nodeKey {
+	if v, ok := nodeKeys.Load(node); ok {
+		return v.(*nodeKey)
+	}
+	var wrapped []byte
+	nid, _ := strToInt(node)
+	if v, err := wfsdb.Get(goutil.Int64ToBytes(int64(nid))); err == nil && v != nil {
+		if wnb := bytesToWfsNodeBean(v); wnb != nil {
+			wrapped = wnb.Key
+		}
+	}
+	nk, err := useNodeKey(node, wrapped)
+	if err != nil {
+		logger.Error("data key of node ", node, " cannot be unwrapped:", err)
+	}
+	return nk
+}
+
+// fits reports whether new blocks may be appended to the node of nk as encryption is configured
+func (t *nodeKey) fits() bool {
+	return (t.aead != nil) == sys.Encrypt && (t.aead != nil || len(t.wrapped) == 0)
+}
+
+// newNodeKey creates the data key of a new node wrapped by the current master key
+func newNodeKey(node string) (err error) {
+	ms, err := loadMasterKeys()
+	if err != nil {
+		return
+	}
+	if len(ms) == 0 {
+		return errors.New("no master key")
+	}
+	wrapped := wrapKey(randomKey(), ms[0])
+	if err = writeKeyFile(node, wrapped); err == nil {
+		_, err = useNodeKey(node, wrapped)
+	}
+	return
+}
+
+func writeKeyFile(node string, wrapped []byte) (err error) {
+	if err = os.MkdirAll(keyDir(), 0777); err == nil {
+		err = os.WriteFile(keyFile(node), wrapped, 0600)
+	}
+	return
+}
+
+// sealBlock encrypts the stored bytes of the block bidBs of node, the bytes of a plain node are returned as they are
+func sealBlock(node string, bidBs, bs []byte) ([]byte, error) {
+	nk := nodeSecret(node)
+	if nk.aead != nil {
+		return seal(nk.aead, bs, bidBs), nil
+	}
+	if len(nk.wrapped) > 0 {
+		return nil, errors.New("the data key of node cannot be unwrapped:" + node)
+	}
+	return bs, nil
+}
+
+// openBlock decrypts the stored bytes of the block bidBs of node, it returns nil if they cannot be authenticated
+func openBlock(node string, bidBs, bs []byte) []byte {
+	if bs == nil {
+		return nil
+	}
+	nk := nodeSecret(node)
+	if nk.aead == nil {
+		if len(nk.wrapped) > 0 {
+			return nil
+		}
+		return bs
+	}
+	data, err := open(nk.aead, bs, bidBs)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// rotateKey wraps the data keys of all nodes with a new master key in a stopped data directory. The new key
+// is kept as the pending one until every data key is wrapped by it, an interrupted rotation is resumed with it.
+func rotateKey() (err error) {
+	if err = openDB(); err != nil {
+		return errors.New("the wfs data cannot be opened, the service must be stopped:" + err.Error())
+	}
+	defer CloseAll()
+	ms, err := loadMasterKeys()
+	if err != nil {
+		return
+	}
+	if len(ms) == 0 {
+		return errors.New("no master key")
+	}
+	target := ms[len(ms)-1]
+	var key []byte
+	if len(ms) == 1 {
+		key = randomKey()
+		if target, err = newMasterKey(key); err != nil {
+			return
+		}
+		if err = writeMasterKey(key, true); err != nil {
+			return
+		}
+		masters.Store(append(ms, target))
+	} else if key, err = readMasterKey(true); err != nil {
+		return
+	}
+	var nodes, rewrapped, lost int64
+	start := ENDOFFSET_
+	for {
+		keys, e := wfsdb.GetKeysPrefixLimit(ENDOFFSET_, start, maxListLimit)
+		if e != nil {
+			return e
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if len(k) != len(ENDOFFSET_)+8 {
+				continue
+			}
+			nidbs := k[len(ENDOFFSET_):]
+			v, e := wfsdb.Get(nidbs)
+			if e != nil || v == nil {
+				continue
+			}
+			wnb := bytesToWfsNodeBean(v)
+			if wnb == nil || len(wnb.Key) == 0 {
+				continue
+			}
+			nodes++
+			if bytes.Equal(wnb.Key[:min(len(wnb.Key), masterIdLen)], target.id) {
+				continue
+			}
+			dk, e := unwrapKey(wnb.Key)
+			if e != nil {
+				lost++
+				fmt.Println("data key cannot be unwrapped:", nodeName(nidbs), e)
+				continue
+			}
+			wnb.Key = wrapKey(dk, target)
+			if err = wfsdb.Put(nidbs, wfsNodeBeanToBytes(wnb)); err != nil {
+				return
+			}
+			if err = writeKeyFile(nodeName(nidbs), wnb.Key); err != nil {
+				return
+			}
+			rewrapped++
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	fmt.Println("encrypted nodes:", nodes, ", rewrapped keys:", rewrapped, ", lost keys:", lost)
+	if lost > 0 {
+		return errors.New("the new master key is left pending")
+	}
+	if err = writeMasterKey(key, false); err == nil {
+		removePendingKey()
+	}
+	return
+}
+
+// initCrypt loads the master keys when encryption is on
+func initCrypt() (err error) {
+	if sys.Encrypt {
+		_, err = loadMasterKeys()
+	}
+	return
+}
+
+// initEncrypt re-encrypts the nodes stored before encryption was on in the background
+func initEncrypt() {
+	if sys.Encrypt {
+		goTask(encryptNodes)
+	}
+}
+
+// encryptNodes moves the blocks of the plain nodes to the current node, a node is removed when none of its
+// blocks is left. The nodes being defragmented are skipped and tried at the next start.
+func encryptNodes() {
+	defer util.Recover()
+	if !atomic.CompareAndSwapInt32(&encrypting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&encrypting, 0)
+	var nodes []string
+	start := ENDOFFSET_
+	for !stopstat {
+		keys, err := wfsdb.GetKeysPrefixLimit(ENDOFFSET_, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if len(k) == len(ENDOFFSET_)+8 {
+				if node := nodeName(k[len(ENDOFFSET_):]); node != "" && nodeSecret(node).aead == nil && len(nodeSecret(node).wrapped) == 0 {
+					nodes = append(nodes, node)
+				}
+			}
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	var moved int64
+	for _, node := range nodes {
+		if stopstat {
+			return
+		}
+		if node == fe.current() || defragging() {
+			continue
+		}
+		if n, ok := encryptNode(node); ok {
+			moved += n
+			removeNode(node)
+		}
+	}
+	if len(nodes) > 0 {
+		logger.Warn("encrypt moved blocks:", moved, ", plain nodes:", len(nodes))
+	}
+}
+
+// encryptNode moves the live blocks of node to the current node, it reports whether no block is left in node
+func encryptNode(node string) (moved int64, ok bool) {
+	relocating.Store(node, byte(0))
+	defer relocating.Delete(node)
+	if defragging() {
+		return
+	}
+	return moveBlocks(node, func(bidBs []byte, offset int64) sys.ERROR {
+		return fe.relocate(bidBs, node, offset)
+	})
+}
+
+// moveBlocks moves every live block of node by move, it reports whether no block is left in node
+func moveBlocks(node string, move func(bidBs []byte, offset int64) sys.ERROR) (moved int64, ok bool) {
+	nid, _ := strToInt(node)
+	endBs, err := wfsdb.Get(append(ENDOFFSET_, goutil.Int64ToBytes(int64(nid))...))
+	if err != nil || endBs == nil {
+		return
+	}
+	end, step := goutil.BytesToInt64(endBs), int64(hashLen(nodeHash(node)))
+	ok = true
+	for offset := int64(0); offset+step+4 <= end; {
+		if stopstat {
+			return moved, false
+		}
+		hd, b := dataEg.readData(node, offset, step+4)
+		if !b {
+			return moved, false
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size <= 0 {
+			break
+		}
+		bidBs := bytes.Clone(hd[:step])
+		if liveBean(bidBs, node, offset) != nil {
+			tasklimit()
+			if move(bidBs, offset) == nil {
+				moved++
+			} else {
+				ok = false
+			}
+		}
+		offset += step + 4 + size
+	}
+	return
+}
+
+// removeNode deletes node and its metadata, its blocks must be released or moved
+func removeNode(node string) {
+	nid, _ := strToInt(node)
+	nidbs := goutil.Int64ToBytes(int64(nid))
+	s := nodeShards(node)
+	if wfsdb.Batch(nil, [][]byte{append(ENDOFFSET_, nidbs...), nidbs, atimeKey(node)}) == nil {
+		shardSets.Delete(node)
+		accesses.Delete(node)
+		dataEg.unMmap(node)
+		if s != nil {
+			s.remove()
+		} else if err := os.Remove(getpathBynode(node)); err != nil {
+			logger.Error(err)
+		}
+		os.Remove(keyFile(node))
+		nodeKeys.Delete(node)
+		nodeHashes.Delete(node)
+	}
+}
+
+// relocate stores the block of bidBs at offset of node again in the current node
+func (t *fileEg) relocate(bidBs []byte, node string, offset int64) (_r sys.ERROR) {
+	if _r = t.handler.relocate(bidBs, node, offset); _r != nil && _r.Equal(sys.ERR_FILEAPPEND) {
+		if err := t.next(t.handler.Node); err == nil {
+			_r = t.handler.relocate(bidBs, node, offset)
+		}
+	}
+	return
+}
+
+func (t *fileHandler) relocate(bidBs []byte, node string, offset int64) (_r sys.ERROR) {
+	return t.relocateAs(bidBs, node, offset, nil)
+}
+
+// relocateAs stores the block of bidBs at offset of node again in t, compressed by compressType if it is given
+func (t *fileHandler) relocateAs(bidBs []byte, node string, offset int64, compressType *int32) (_r sys.ERROR) {
+	lockid := goutil.Hash64(append(APPENDLOCK_, bidBs...))
+	lockLevel2.Lock(int64(lockid))
+	defer lockLevel2.Unlock(int64(lockid))
+	wfb := liveBean(bidBs, node, offset)
+	if wfb == nil {
+		return
+	}
+	data := readFileBean(wfb)
+	if data == nil || !bytes.Equal(chainKey(fingerprintBy(keyHash(bidBs), data), wfb.GetChain()), bidBs) {
+		return sys.ERR_CORRUPT
+	}
+	ct := wfb.GetCompressType()
+	if compressType != nil {
+		ct = *compressType
+	}
+	_, _r = t.writeBlock("", bidBs, data, ct, wfb.GetChain(), wfb, nil)
+	return
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// useKeyFile turns encryption on with the master key in the key file of dir
+func useKeyFile(t *testing.T, dir string) string {
+	encrypt, keyFile := sys.Encrypt, sys.EncryptKeyFile
+	t.Cleanup(func() {
+		sys.Encrypt, sys.EncryptKeyFile = encrypt, keyFile
+		dropKeys()
+	})
+	sys.Encrypt, sys.EncryptKeyFile = true, dir+"/master.key"
+	if err := os.WriteFile(sys.EncryptKeyFile, []byte(hex.EncodeToString(randomKey())), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dropKeys()
+	return sys.EncryptKeyFile
+}
+
+// dropKeys forgets the loaded keys as a restart of the service does
+func dropKeys() {
+	masters, nodeKeys = atomic.Value{}, &sync.Map{}
+}
+
+// masterId is the id of the master key that the data key of node is wrapped with
+func masterId(t *testing.T, node string) []byte {
+	t.Helper()
+	nid, _ := strToInt(node)
+	v, err := wfsdb.Get(goutil.Int64ToBytes(int64(nid)))
+	if err != nil || v == nil {
+		t.Fatal("node bean of", node, err)
+	}
+	return bytesToWfsNodeBean(v).Key[:masterIdLen]
+}
+
+// nodeHead reads the beginning of the file of node, where its first blocks are
+func nodeHead(t *testing.T, node string) []byte {
+	t.Helper()
+	f, err := os.Open(getpathBynode(node))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	bs := make([]byte, 4096)
+	if _, err = f.ReadAt(bs, 0); err != nil {
+		t.Fatal(err)
+	}
+	return bs
+}
+
+func currentId(t *testing.T, keyFile string) []byte {
+	t.Helper()
+	bs, _ := os.ReadFile(keyFile)
+	key, _ := hex.DecodeString(string(bs))
+	m, err := newMasterKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.id
+}
+
+func TestEncrypt(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := useKeyFile(t, dir)
+	startStore(dir)
+	defer CloseAll()
+	data := []byte("the plain data that is never stored as it is")
+	fe.append("e/a", data, 0, nil)
+	node := fe.handler.Node
+	if bytes.Contains(nodeHead(t, node), data) {
+		t.Fatal("the node is not encrypted")
+	}
+	if !bytes.Equal(fe.getData("e/a"), data) || !bytes.Equal(masterId(t, node), currentId(t, keyFile)) {
+		t.Fatal("data of e/a")
+	}
+	consistent(t)
+	// the data key cannot be unwrapped by another master key, the data is not read
+	stopStore()
+	os.WriteFile(keyFile, []byte(hex.EncodeToString(randomKey())), 0600)
+	dropKeys()
+	startStore(dir)
+	if fe.getData("e/a") != nil {
+		t.Fatal("the data is read without its master key")
+	}
+}
+
+func TestRotateKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := useKeyFile(t, dir)
+	startStore(dir)
+	defer CloseAll()
+	old := currentId(t, keyFile)
+	var nodes []string
+	for i := 0; i < 3; i++ {
+		fe.append(fmt.Sprint("r/", i), []byte(fmt.Sprint("the data of r/", i)), 0, nil)
+		nodes = append(nodes, fe.handler.Node)
+		fe.next(fe.handler.Node)
+	}
+	stopStore()
+	if err := rotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(currentId(t, keyFile), old) {
+		t.Fatal("the master key is not rotated")
+	}
+	if _, err := os.Stat(keyFile + pendingSuffix); !os.IsNotExist(err) {
+		t.Fatal("the pending key is left:", err)
+	}
+	dropKeys()
+	startStore(dir)
+	for i, node := range nodes {
+		if !bytes.Equal(masterId(t, node), currentId(t, keyFile)) {
+			t.Fatal("the data key of node", node, "is not rewrapped")
+		}
+		if string(fe.getData(fmt.Sprint("r/", i))) != fmt.Sprint("the data of r/", i) {
+			t.Fatal("data of r/", i)
+		}
+	}
+	consistent(t)
+}
+
+func TestRotateKeyResume(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := useKeyFile(t, dir)
+	startStore(dir)
+	defer CloseAll()
+	fe.append("r/a", []byte("the data of r/a"), 0, nil)
+	stopStore()
+	// a rotation interrupted after the new key is written wraps the data keys with it
+	pending := hex.EncodeToString(randomKey())
+	os.WriteFile(keyFile+pendingSuffix, []byte(pending), 0600)
+	dropKeys()
+	if err := rotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	if bs, _ := os.ReadFile(keyFile); string(bs) != pending {
+		t.Fatal("the pending key is not the current one")
+	}
+	dropKeys()
+	startStore(dir)
+	if string(fe.getData("r/a")) != "the data of r/a" {
+		t.Fatal("data of r/a")
+	}
+}
+
+func TestEncryptNodes(t *testing.T) {
+	dir := t.TempDir()
+	startStore(dir)
+	defer CloseAll()
+	data := []byte("the data stored before encryption is on")
+	fe.append("e/a", data, 0, nil)
+	plain := fe.handler.Node
+	if !bytes.Contains(nodeHead(t, plain), data) {
+		t.Fatal("the plain node does not hold the data")
+	}
+	stopStore()
+	// the blocks of the plain node are moved to an encrypted node at the start
+	useKeyFile(t, dir)
+	startStore(dir)
+	stopTasks()
+	if _, err := os.Stat(getpathBynode(plain)); !os.IsNotExist(err) || fe.handler.Node == plain {
+		t.Fatal("the plain node is left:", err)
+	}
+	if bytes.Contains(nodeHead(t, fe.handler.Node), data) || !bytes.Equal(fe.getData("e/a"), data) {
+		t.Fatal("data of e/a")
+	}
+	consistent(t)
+}
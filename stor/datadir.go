@@ -0,0 +1,369 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// nodeDirs caches the directory of the file of every node
+var nodeDirs = &sync.Map{}
+
+// readonlyDirs holds the data directories without space for a new node, no node is placed in them
+var readonlyDirs = &sync.Map{}
+
+var rebalanceRun int32
+var rebalanceMux = &sync.Mutex{}
+var rebalanceStat = &sys.DataDirsBean{}
+
+func init() {
+	sys.DataDirStatus = dataDirStatus
+	sys.Rebalance = rebalance
+}
+
+func defaultDir() string {
+	return filepath.Clean(sys.WFSDATA + "/wfsfile")
+}
+
+// placeDirs are the directories that new nodes are placed in, the default one if no data directory is configured
+func placeDirs() []*sys.DataDirBean {
+	if len(sys.DataDirs) == 0 {
+		return []*sys.DataDirBean{{Path: defaultDir(), Weight: 1}}
+	}
+	return sys.DataDirs
+}
+
+func dirUsage(dir string) (free, total uint64) {
+	if u, err := disk.Usage(dir); err == nil {
+		free, total = u.Free, u.Total
+	}
+	return
+}
+
+func dirReadonly(dir string) bool {
+	_, ok := readonlyDirs.Load(dir)
+	return ok
+}
+
+// checkDir marks dir read-only when it has no space for two node files, the current one may still grow in it
+func checkDir(dir string) (free uint64, writable bool) {
+	free, _ = dirUsage(dir)
+	if writable = free >= 2*uint64(sys.FileSize); writable {
+		if _, ok := readonlyDirs.LoadAndDelete(dir); ok {
+			logger.Warn("data directory is writable:", dir)
+		}
+	} else if _, ok := readonlyDirs.LoadOrStore(dir, byte(0)); !ok {
+		logger.Warn("data directory is full and marked read-only:", dir)
+	}
+	return
+}
+
+// nodeDir returns the directory of the file of node, the temporary file of a defragmentation is beside its node.
+// The directory recorded in the node bean is used while the file is found there.
+func nodeDir(node string) string {
+	if i := strings.Index(node, "_"); i > 0 {
+		node = node[:i]
+	}
+	if v, ok := nodeDirs.Load(node); ok {
+		return v.(string)
+	}
+	dir := ""
+	if nid, ok := strToInt(node); ok {
+		if v, err := wfsdb.Get(goutil.Int64ToBytes(int64(nid))); err == nil && v != nil {
+			if wnb := bytesToWfsNodeBean(v); wnb != nil {
+				dir = wnb.GetDir()
+			}
+		}
+	}
+	if dir == "" || !goutil.IsFileExist(dir+"/"+node) {
+		dir = ""
+		for _, d := range sys.FileDirs() {
+			if goutil.IsFileExist(d + "/" + node) {
+				dir = d
+				break
+			}
+		}
+	}
+	if dir == "" {
+		return defaultDir()
+	}
+	nodeDirs.Store(node, dir)
+	return dir
+}
+
+// beanDir is the directory of node kept in its bean, nil for the default one
+func beanDir(node string) *string {
+	if dir := nodeDir(node); dir != defaultDir() {
+		return &dir
+	}
+	return nil
+}
+
+// placeNode chooses the directory of a new node, the hot directory while it is writable, or else the writable
+// one with the most free space by its weight
+func placeNode() (dir string, err error) {
+	if tierOn() {
+		if _, ok := checkDir(sys.TierHot); ok {
+			return sys.TierHot, nil
+		}
+	}
+	dirs := placeDirs()
+	if len(dirs) == 1 {
+		return dirs[0].Path, nil
+	}
+	var best float64
+	for _, d := range dirs {
+		if free, ok := checkDir(d.Path); ok {
+			if s := float64(free) * float64(d.Weight); s > best {
+				best, dir = s, d.Path
+			}
+		}
+	}
+	if dir == "" {
+		err = errors.New("no data directory has space for a new node")
+	}
+	return
+}
+
+// checkDirs marks the full data directories read-only, the current node leaves a read-only directory
+func checkDirs() {
+	node := fe.current()
+	if len(sys.DataDirs) < 2 || node == "" {
+		return
+	}
+	writable := false
+	for _, d := range sys.DataDirs {
+		if _, ok := checkDir(d.Path); ok {
+			writable = true
+		}
+	}
+	if writable && dirReadonly(nodeDir(node)) {
+		fe.next(node)
+	}
+}
+
+// dataDirStatus returns the data directories with their space and nodes, and the progress of the running
+// or the last rebalance
+func dataDirStatus() *sys.DataDirsBean {
+	rebalanceMux.Lock()
+	db := *rebalanceStat
+	rebalanceMux.Unlock()
+	counts := make(map[string]int)
+	for _, node := range storedNodes(false) {
+		if nodeShards(node) == nil {
+			counts[nodeDir(node)]++
+		}
+	}
+	for _, d := range placeDirs() {
+		free, total := dirUsage(d.Path)
+		db.Dirs = append(db.Dirs, &sys.DataDirItem{Path: d.Path, Weight: d.Weight, Free: free, Total: total, Nodes: counts[d.Path], ReadOnly: dirReadonly(d.Path)})
+	}
+	return &db
+}
+
+// storedNodes returns the nodes, only the ones no longer appended to if sealed is set
+func storedNodes(sealed bool) (_r []string) {
+	start := ENDOFFSET_
+	for {
+		keys, err := wfsdb.GetKeysPrefixLimit(ENDOFFSET_, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if len(k) != len(ENDOFFSET_)+8 {
+				continue
+			}
+			if node := nodeName(k[len(ENDOFFSET_):]); node != "" && !(sealed && currentNode(node)) {
+				_r = append(_r, node)
+			}
+		}
+		if len(keys) < maxListLimit {
+			return
+		}
+	}
+}
+
+func currentNode(node string) bool {
+	if fe.current() == node {
+		return true
+	}
+	if cold := coldfn; cold != nil && cold.Node == node {
+		return true
+	}
+	next := nextfn.Load()
+	return next != nil && next.Node == node
+}
+
+func rebalance() sys.ERROR {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if len(sys.DataDirs) < 2 {
+		return sys.ERR_DATADIRS
+	}
+	if !atomic.CompareAndSwapInt32(&rebalanceRun, 0, 1) {
+		return sys.ERR_REBALANCE_UNDERWAY
+	}
+	rebalanceMux.Lock()
+	rebalanceStat = &sys.DataDirsBean{Running: true, StartTime: time.Now().UnixNano()}
+	rebalanceMux.Unlock()
+	goTask(moveNodes)
+	return nil
+}
+
+type dirSpace struct {
+	path   string
+	weight float64
+	free   float64
+	nodes  []string
+	sizes  map[string]int64
+}
+
+func (t *dirSpace) score() float64 {
+	return t.free * t.weight
+}
+
+// moveNodes moves sealed node files from the data directory with the least free space by its weight to the one
+// with the most, as long as the move leaves the target with more free space by weight than the source. The
+// moves are planned on the free space measured at the start.
+func moveNodes() {
+	defer util.Recover()
+	defer func() {
+		rebalanceMux.Lock()
+		rebalanceStat.Running, rebalanceStat.EndTime = false, time.Now().UnixNano()
+		rebalanceMux.Unlock()
+		atomic.StoreInt32(&rebalanceRun, 0)
+	}()
+	spaces := make(map[string]*dirSpace)
+	for _, d := range sys.DataDirs {
+		free, _ := dirUsage(d.Path)
+		spaces[d.Path] = &dirSpace{path: d.Path, weight: float64(d.Weight), free: float64(free), sizes: make(map[string]int64)}
+	}
+	for _, node := range storedNodes(true) {
+		if nodeShards(node) != nil {
+			continue
+		}
+		if ds, ok := spaces[nodeDir(node)]; ok {
+			if v, err := wfsdb.Get(append(ENDOFFSET_, nodeBytes(node)...)); err == nil && v != nil {
+				ds.nodes = append(ds.nodes, node)
+				ds.sizes[node] = goutil.BytesToInt64(v)
+			}
+		}
+	}
+	for !stopstat {
+		var src, dst *dirSpace
+		for _, ds := range spaces {
+			if len(ds.nodes) > 0 && (src == nil || ds.score() < src.score()) {
+				src = ds
+			}
+			if !dirReadonly(ds.path) && (dst == nil || ds.score() > dst.score()) {
+				dst = ds
+			}
+		}
+		if src == nil || dst == nil || src == dst {
+			return
+		}
+		node := src.nodes[len(src.nodes)-1]
+		size := src.sizes[node]
+		if (dst.free-float64(size))*dst.weight < (src.free+float64(size))*src.weight || dst.free < float64(size+2*sys.FileSize) {
+			return
+		}
+		src.nodes = src.nodes[:len(src.nodes)-1]
+		if err := moveNode(node, dst.path); err != nil {
+			logger.Error("move node ", node, " to ", dst.path, " failed:", err)
+			continue
+		}
+		src.free, dst.free = src.free+float64(size), dst.free-float64(size)
+		rebalanceMux.Lock()
+		rebalanceStat.Moved++
+		rebalanceStat.Bytes += size
+		rebalanceMux.Unlock()
+	}
+}
+
+// moveNode copies the file of node to dir and switches node to it, the node stays readable from its previous
+// file until the copy is complete
+func moveNode(node, dir string) (err error) {
+	relocating.Store(node, byte(0))
+	defer relocating.Delete(node)
+	if _, ok := defragmap.Load(node); ok || defragging() {
+		return errors.New("the node is being defragmented")
+	}
+	nid, _ := strToInt(node)
+	nidbs := goutil.Int64ToBytes(int64(nid))
+	endBs, err := wfsdb.Get(append(ENDOFFSET_, nidbs...))
+	if err != nil || endBs == nil {
+		return errors.New("the node has no end offset")
+	}
+	src := getpathBynode(node)
+	tmp := dir + "/" + node + ".move"
+	if err = copyFile(src, tmp, goutil.BytesToInt64(endBs)); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	if err = os.Rename(tmp, dir+"/"+node); err != nil {
+		os.Remove(tmp)
+		return
+	}
+	if v, e := wfsdb.Get(nidbs); e == nil && v != nil {
+		if wnb := bytesToWfsNodeBean(v); wnb != nil {
+			wnb.Dir = &dir
+			if err = wfsdb.Put(nidbs, wfsNodeBeanToBytes(wnb)); err != nil {
+				os.Remove(dir + "/" + node)
+				return
+			}
+		}
+	}
+	unmountmap.Store(nid, byte(0))
+	dataEg.unMmap(node)
+	nodeDirs.Store(node, dir)
+	unmountmap.Delete(nid)
+	if err := os.Remove(src); err != nil {
+		logger.Error(err)
+	}
+	return nil
+}
+
+// copyFile copies the blocks of src up to end to dst, the unwritten rest of src stays sparse in dst
+func copyFile(src, dst string, end int64) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return
+	}
+	defer out.Close()
+	if _, err = io.CopyN(out, in, min(end, fi.Size())); err == nil {
+		if err = out.Truncate(fi.Size()); err == nil {
+			err = out.Sync()
+		}
+	}
+	return
+}
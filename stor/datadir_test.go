@@ -0,0 +1,94 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+// useDataDirs configures the data directories a and b of dir with their weights
+func useDataDirs(t *testing.T, dir string, wa, wb int) (a, b string) {
+	dataDirs, fileSize := sys.DataDirs, sys.FileSize
+	t.Cleanup(func() {
+		sys.DataDirs, sys.FileSize = dataDirs, fileSize
+		nodeDirs, readonlyDirs = &sync.Map{}, &sync.Map{}
+	})
+	a, b = dir+"/a", dir+"/b"
+	os.MkdirAll(a, 0777)
+	os.MkdirAll(b, 0777)
+	sys.FileSize = 1 << 16
+	sys.DataDirs = []*sys.DataDirBean{{Path: a, Weight: wa}, {Path: b, Weight: wb}}
+	return
+}
+
+func TestPlaceNode(t *testing.T) {
+	dir := t.TempDir()
+	a, b := useDataDirs(t, dir, 1, 3)
+	startStore(dir)
+	defer CloseAll()
+	// the directories share the free space of the disk, the weight decides
+	for i := 0; i < 3; i++ {
+		fe.append(fmt.Sprint("d/", i), []byte(fmt.Sprint("the data of d/", i)), 0, nil)
+		if d := nodeDir(fe.handler.Node); d != b {
+			t.Fatal("node is placed in", d)
+		}
+		fe.next(fe.handler.Node)
+	}
+	ds := dataDirStatus()
+	if len(ds.Dirs) != 2 || ds.Dirs[0].Path != a || ds.Dirs[0].Nodes != 0 || ds.Dirs[1].Nodes != 4 || ds.Dirs[1].Weight != 3 {
+		t.Fatalf("%+v %+v", ds.Dirs[0], ds.Dirs[1])
+	}
+	// a rebalance needs two data directories
+	if sys.DataDirs = sys.DataDirs[:1]; rebalance() != sys.ERR_DATADIRS {
+		t.Fatal("rebalance of one data directory")
+	}
+}
+
+func TestRebalance(t *testing.T) {
+	dir := t.TempDir()
+	a, b := useDataDirs(t, dir, 3, 1)
+	startStore(dir)
+	defer CloseAll()
+	var nodes []string
+	for i := 0; i < 3; i++ {
+		fe.append(fmt.Sprint("d/", i), []byte(fmt.Sprint("the data of d/", i)), 0, nil)
+		nodes = append(nodes, fe.handler.Node)
+		fe.next(fe.handler.Node)
+	}
+	// the sealed nodes leave the directory of the lower weight, the current one stays
+	sys.DataDirs[0].Weight, sys.DataDirs[1].Weight = 1, 3
+	if err := rebalance(); err != nil {
+		t.Fatal(err)
+	}
+	stopTasks()
+	if ds := dataDirStatus(); ds.Moved != 3 || ds.Dirs[0].Nodes != 1 || ds.Dirs[1].Nodes != 3 {
+		t.Fatalf("moved %d, %+v %+v", ds.Moved, ds.Dirs[0], ds.Dirs[1])
+	}
+	for i, node := range nodes {
+		if nodeDir(node) != b || fe.stat(fmt.Sprint("d/", i)) == nil || string(fe.getData(fmt.Sprint("d/", i))) != fmt.Sprint("the data of d/", i) {
+			t.Fatal("node", node, "in", nodeDir(node))
+		}
+		if _, err := os.Stat(a + "/" + node); !os.IsNotExist(err) {
+			t.Fatal("the moved node is left in", a)
+		}
+	}
+	// the directory of a moved node is found after a restart
+	nodeDirs = &sync.Map{}
+	restart(dir)
+	for i := range nodes {
+		if string(fe.getData(fmt.Sprint("d/", i))) != fmt.Sprint("the data of d/", i) {
+			t.Fatal("data of d/", i)
+		}
+	}
+	consistent(t)
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync/atomic"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// maxChain is the number of keys a fingerprint may chain to when different data share it
+const maxChain = 16
+
+// collisions counts the stored data found different from new data with the same fingerprint
+var collisions int64
+
+func init() {
+	sys.Collisions = func() int64 { return atomic.LoadInt64(&collisions) }
+}
+
+// digest is the strong hash kept in the bean of a block with sys.DedupVerify,
+// it tells two blocks with the same fingerprint apart without reading them
+func digest(bs []byte) []byte {
+	hash := sha256.Sum256(bs)
+	return hash[:]
+}
+
+// chainKey is the key of the data that is the chain-th to collide on bidBs, chain 0 is bidBs itself.
+// The keys of a chain are fingerprints of the hash algorithm of bidBs.
+func chainKey(bidBs []byte, chain int32) []byte {
+	if chain == 0 {
+		return bidBs
+	}
+	return fingerprintBy(keyHash(bidBs), append(bytes.Clone(bidBs), goutil.Int32ToBytes(chain)...))
+}
+
+// dedupKey returns the key that new data of fingerprint bidBs is stored or deduplicated under.
+// With sys.DedupVerify, a bean found under a key is compared with the new data by same,
+// and different data moves the new data to the next key of the chain of bidBs.
+func dedupKey(bidBs []byte, same func(key []byte, wfb *stub.WfsFileBean) bool) (key []byte, chain int32, _r sys.ERROR) {
+	if !sys.DedupVerify {
+		return bidBs, 0, nil
+	}
+	for ; chain <= maxChain; chain++ {
+		key = chainKey(bidBs, chain)
+		v, err := wfsdb.Get(key)
+		if err != nil || v == nil {
+			return
+		}
+		if wfb := bytesToWfsFileBean(v); wfb == nil || same(key, wfb) {
+			return
+		}
+		atomic.AddInt64(&collisions, 1)
+		logger.Warn("fingerprint collision:", hex.EncodeToString(key))
+	}
+	return nil, 0, sys.ERR_UNDEFINED
+}
+
+// sameBlock compares bs with a stored block by its digest, or by its data if it was stored without digest.
+// A quarantined block cannot be read and is taken as the same, so that bs heals it.
+func sameBlock(bs []byte) func(key []byte, wfb *stub.WfsFileBean) bool {
+	return func(key []byte, wfb *stub.WfsFileBean) bool {
+		if len(wfb.Parts) > 0 {
+			return false
+		}
+		if len(wfb.Digest) > 0 {
+			return bytes.Equal(wfb.Digest, digest(bs))
+		}
+		return quarantined(key) || bytes.Equal(readFileBean(wfb), bs)
+	}
+}
+
+// sameManifest compares the parts of a new manifest with a stored one
+func sameManifest(parts []*stub.WfsPartBean) func(key []byte, wfb *stub.WfsFileBean) bool {
+	return func(key []byte, wfb *stub.WfsFileBean) bool {
+		if len(wfb.Parts) != len(parts) {
+			return false
+		}
+		for i, p := range wfb.Parts {
+			if !bytes.Equal(p.Fingerprint, parts[i].Fingerprint) || p.GetSize() != parts[i].GetSize() {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"math/bits"
+	"sync/atomic"
+	"testing"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// collide returns data different from bs with the same crc64, some bits of its last 9 bytes are flipped.
+// The change of the crc by a flipped bit is linear, so 72 bits have a combination that changes nothing.
+func collide(bs []byte) []byte {
+	type row struct {
+		delta uint64
+		flip  []byte
+	}
+	base, basis := goutil.CRC64(bs), map[int]row{}
+	for i := 0; i < 72; i++ {
+		r := row{flip: make([]byte, len(bs))}
+		r.flip[len(bs)-9+i/8] = 1 << (i % 8)
+		b := bytes.Clone(bs)
+		b[len(bs)-9+i/8] ^= 1 << (i % 8)
+		r.delta = goutil.CRC64(b) ^ base
+		for r.delta != 0 {
+			p := 63 - bits.LeadingZeros64(r.delta)
+			br, ok := basis[p]
+			if !ok {
+				basis[p] = r
+				break
+			}
+			r.delta ^= br.delta
+			for j := range r.flip {
+				r.flip[j] ^= br.flip[j]
+			}
+		}
+		if r.delta == 0 {
+			for j := range b {
+				b[j] = bs[j] ^ r.flip[j]
+			}
+			return b
+		}
+	}
+	return nil
+}
+
+func TestDedupCollision(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	fileHash, verify := sys.FileHash, sys.DedupVerify
+	defer func() { sys.FileHash, sys.DedupVerify = fileHash, verify }()
+	sys.FileHash, sys.DedupVerify = 0, true
+	a := []byte("the data of d/a, that d/b collides with")
+	b := collide(a)
+	if b == nil || bytes.Equal(a, b) || !bytes.Equal(fingerprint(a), fingerprint(b)) {
+		t.Fatal("no collision of the fingerprint")
+	}
+	n := atomic.LoadInt64(&collisions)
+	for i, path := range []string{"d/a", "d/b", "d/c"} {
+		if _, err := fe.append(path, [][]byte{a, b, b}[i], 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the colliding data is stored under the next key of the chain, the same data is deduplicated on it
+	if !bytes.Equal(fe.getData("d/a"), a) || !bytes.Equal(fe.getData("d/b"), b) || !bytes.Equal(fe.getData("d/c"), b) {
+		t.Fatal("the colliding data are mixed up")
+	}
+	sa, sb, sc := fe.stat("d/a"), fe.stat("d/b"), fe.stat("d/c")
+	if bytes.Equal(sa.Fingerprint, sb.Fingerprint) || !bytes.Equal(sb.Fingerprint, chainKey(fingerprint(b), 1)) || !bytes.Equal(sb.Fingerprint, sc.Fingerprint) || sb.Refercount != 2 || sa.Refercount != 1 {
+		t.Fatal("keys of the colliding data:", sa, sb, sc)
+	}
+	if atomic.LoadInt64(&collisions) == n {
+		t.Fatal("the collision is not counted")
+	}
+	fe.delData("d/a")
+	if !bytes.Equal(fe.getData("d/b"), b) {
+		t.Fatal("the chained data is lost with the data it collided with")
+	}
+	consistent(t)
+}
@@ -0,0 +1,386 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	"github.com/donnie4w/gofer/compress"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+	"github.com/klauspost/compress/dict"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// dictMaxData is the size of the largest data compressed with a dictionary and taken as a sample
+	dictMaxData = 64 << 10
+	dictSize    = 64 << 10
+	dictSamples = 1000
+	// a scope is trained with dictMinSamples at least, one sample in dictEvalStep is kept out of the training
+	// to compare the compression with and without the dictionary
+	dictMinSamples = 16
+	dictEvalStep   = 5
+	// dictScan is the number of paths read for the samples of a training
+	dictScan = 100000
+)
+
+// zdict is a trained zstd dictionary with the encoders of its levels and its decoder
+type zdict struct {
+	wdb  *stub.WfsDictBean
+	encs sync.Map
+	dec  *zstd.Decoder
+}
+
+func newZdict(wdb *stub.WfsDictBean) (_r *zdict, err error) {
+	_r = &zdict{wdb: wdb}
+	_r.dec, err = zstd.NewReader(nil, zstd.WithDecoderDicts(wdb.Data))
+	return
+}
+
+func (t *zdict) encoder(level zstd.EncoderLevel) (enc *zstd.Encoder, err error) {
+	if v, ok := t.encs.Load(level); ok {
+		return v.(*zstd.Encoder), nil
+	}
+	if enc, err = zstd.NewWriter(nil, zstd.WithEncoderDict(t.wdb.Data), zstd.WithEncoderLevel(level), zstd.WithEncoderConcurrency(1)); err == nil {
+		t.encs.Store(level, enc)
+	}
+	return
+}
+
+// dicts holds the dictionaries by id, scopeDicts the newest one of every scope
+var dicts = &sync.Map{}
+var scopeDicts = &sync.Map{}
+
+var dictRun int32
+var dictMux = &sync.Mutex{}
+var dictStat = &sys.DictBean{}
+
+func init() {
+	sys.DictTrain = dictTrain
+	sys.DictStatus = dictStatus
+}
+
+func dictDir() string {
+	return sys.WFSDATA + "/wfsdict"
+}
+
+func dictKey(id int32) []byte {
+	return append(DICT_, goutil.Int64ToBytes(int64(id))...)
+}
+
+func bytesToWfsDictBean(bs []byte) (wdb *stub.WfsDictBean) {
+	wdb = &stub.WfsDictBean{}
+	if util.PDecode(bs, wdb) != nil || wdb.GetId() == 0 || len(wdb.Data) == 0 {
+		wdb = nil
+	}
+	return
+}
+
+func wfsDictBeanToBytes(b *stub.WfsDictBean) (bs []byte) {
+	bs, _ = util.PEncode(b)
+	return
+}
+
+// initDict loads the dictionaries, they are trained in the background if zstd.dict is set and there is none
+func initDict() {
+	start := DICT_
+	for {
+		keys, err := wfsdb.GetKeysPrefixLimit(DICT_, start, maxListLimit)
+		if err != nil {
+			break
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if v, err := wfsdb.Get(k); err == nil && v != nil {
+				if wdb := bytesToWfsDictBean(v); wdb != nil {
+					useDict(wdb)
+				}
+			}
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	if sys.ZstdDict && sys.Mode == 1 {
+		empty := true
+		dicts.Range(func(_, _ any) bool { empty = false; return false })
+		if empty {
+			dictTrain()
+		}
+	}
+}
+
+// useDict makes wdb readable, and the dictionary of its scope if it is the newest one
+func useDict(wdb *stub.WfsDictBean) (d *zdict) {
+	if v, ok := dicts.Load(wdb.GetId()); ok {
+		return v.(*zdict)
+	}
+	var err error
+	if d, err = newZdict(wdb); err != nil {
+		logger.Error("dictionary ", wdb.GetId(), " cannot be used:", err)
+		return nil
+	}
+	dicts.Store(wdb.GetId(), d)
+	if v, ok := scopeDicts.Load(wdb.GetScope()); !ok || v.(*zdict).wdb.GetTimestramp() < wdb.GetTimestramp() {
+		scopeDicts.Store(wdb.GetScope(), d)
+	}
+	return
+}
+
+// saveDict stores wdb in the metadata and in the dictionary directory, where rebuild finds it
+func saveDict(wdb *stub.WfsDictBean) (err error) {
+	bs := wfsDictBeanToBytes(wdb)
+	if err = os.MkdirAll(dictDir(), 0777); err != nil {
+		return
+	}
+	if err = os.WriteFile(dictDir()+"/"+strconv.Itoa(int(wdb.GetId())), bs, 0666); err != nil {
+		return
+	}
+	if err = wfsdb.Put(dictKey(wdb.GetId()), bs); err == nil {
+		useDict(wdb)
+	}
+	return
+}
+
+// importDict keeps a dictionary of imported data, the blocks compressed with it refer to its id
+func importDict(v []byte) (err error) {
+	if wdb := bytesToWfsDictBean(v); wdb != nil {
+		if _, ok := dicts.Load(wdb.GetId()); !ok {
+			err = saveDict(wdb)
+		}
+	}
+	return
+}
+
+// dictScopes are the scopes that the dictionary of path and bs is looked up by, the longest prefix of
+// sys.ZstdDictPrefix and then the content type of bs. The compressed formats have no dictionary.
+func dictScopes(path string, bs []byte) (_r []string) {
+	if path != "" {
+		pre := ""
+		for _, p := range sys.ZstdDictPrefix {
+			if strings.HasPrefix(path, p) && len(p) > len(pre) {
+				pre = p
+			}
+		}
+		if pre != "" {
+			_r = append(_r, "prefix:"+pre)
+		}
+	}
+	if ct := http.DetectContentType(bs); !compressedTypes[ct] {
+		_r = append(_r, "type:"+ct)
+	}
+	return
+}
+
+func zstdLevel(compressType int32) zstd.EncoderLevel {
+	if compressType >= COMPRESS_ZSTD_FASTEST && compressType <= COMPRESS_ZSTD_BEST {
+		return zstd.EncoderLevel(compressType - 12)
+	}
+	return zstd.SpeedDefault
+}
+
+// dictCompress compresses bs of path with the dictionary of its scope if zstd.dict is set and compressType is zstd,
+// it returns the id of the dictionary, 0 if bs is not compressed with one
+func dictCompress(path string, bs []byte, compressType int32) (id int32, _r []byte) {
+	if !sys.ZstdDict || len(bs) > dictMaxData || (compressType != 2 && (compressType < COMPRESS_ZSTD_FASTEST || compressType > COMPRESS_ZSTD_BEST)) {
+		return
+	}
+	for _, scope := range dictScopes(path, bs) {
+		if v, ok := scopeDicts.Load(scope); ok {
+			d := v.(*zdict)
+			if enc, err := d.encoder(zstdLevel(compressType)); err == nil {
+				return d.wdb.GetId(), enc.EncodeAll(bs, nil)
+			}
+		}
+	}
+	return
+}
+
+func dictUncompress(bs []byte, id int32) (_r []byte) {
+	if v, ok := dicts.Load(id); ok {
+		_r, _ = v.(*zdict).dec.DecodeAll(bs, nil)
+	}
+	return
+}
+
+// uncompressBean decompresses the stored bytes of the block of wfb
+func uncompressBean(bs []byte, wfb *stub.WfsFileBean) []byte {
+	if wfb.GetDict() != 0 {
+		return dictUncompress(bs, wfb.GetDict())
+	}
+	return praseUncompress(bs, wfb.GetCompressType())
+}
+
+// frameDict is the id of the dictionary that the zstd frame bs is compressed with
+func frameDict(bs []byte) int32 {
+	var h zstd.Header
+	if h.Decode(bs) == nil {
+		return int32(h.DictionaryID)
+	}
+	return 0
+}
+
+func dictTrain() sys.ERROR {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if sys.Mode != 1 {
+		return sys.ERR_DICT_MODE
+	}
+	if !atomic.CompareAndSwapInt32(&dictRun, 0, 1) {
+		return sys.ERR_DICT_UNDERWAY
+	}
+	dictMux.Lock()
+	dictStat = &sys.DictBean{Running: true, StartTime: time.Now().UnixNano()}
+	dictMux.Unlock()
+	goTask(trainDicts)
+	return nil
+}
+
+// dictStatus returns the progress of the running or the last training with the dictionaries
+func dictStatus() *sys.DictBean {
+	dictMux.Lock()
+	db := *dictStat
+	dictMux.Unlock()
+	dicts.Range(func(_, v any) bool {
+		wdb := v.(*zdict).wdb
+		cur, _ := scopeDicts.Load(wdb.GetScope())
+		db.Dicts = append(db.Dicts, &sys.DictItem{Id: wdb.GetId(), Scope: wdb.GetScope(), Samples: wdb.GetSamples(), Size: wdb.GetSize(), Plain: wdb.GetPlain(), Dicted: wdb.GetDicted(), Timestramp: wdb.GetTimestramp(), Current: cur == v})
+		return true
+	})
+	sort.Slice(db.Dicts, func(i, j int) bool {
+		if db.Dicts[i].Scope != db.Dicts[j].Scope {
+			return db.Dicts[i].Scope < db.Dicts[j].Scope
+		}
+		return db.Dicts[i].Timestramp > db.Dicts[j].Timestramp
+	})
+	return &db
+}
+
+// trainDicts samples the small files by the scopes of their paths and data, and trains a new dictionary
+// for every scope with enough samples. A dictionary that compresses its held out samples no better than
+// zstd alone is dropped. The previous dictionaries are kept for the data compressed with them.
+func trainDicts() {
+	defer util.Recover()
+	defer func() {
+		dictMux.Lock()
+		dictStat.Running, dictStat.EndTime = false, time.Now().UnixNano()
+		dictMux.Unlock()
+		atomic.StoreInt32(&dictRun, 0)
+	}()
+	samples := make(map[string][][]byte)
+	var scanned int
+	start := PATH_PRE
+	for scanned < dictScan && !stopstat {
+		keys, err := wfsdb.GetKeysPrefixLimit(PATH_PRE, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if scanned++; scanned > dictScan || stopstat {
+				break
+			}
+			tasklimit()
+			path := string(k[len(PATH_PRE):])
+			_, wfb := fe.getFileBean(path)
+			if wfb == nil || len(wfb.Parts) > 0 || dataSize(wfb) > dictMaxData {
+				continue
+			}
+			if bs := readFileBean(wfb); len(bs) > 0 {
+				if scopes := dictScopes(path, bs); len(scopes) > 0 && len(samples[scopes[0]]) < dictSamples {
+					samples[scopes[0]] = append(samples[scopes[0]], bs)
+					dictMux.Lock()
+					dictStat.Samples++
+					dictMux.Unlock()
+				}
+			}
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	for scope, ss := range samples {
+		if stopstat {
+			return
+		}
+		if len(ss) < dictMinSamples {
+			continue
+		}
+		if wdb := trainDict(scope, ss); wdb != nil {
+			if err := saveDict(wdb); err != nil {
+				logger.Error("dictionary of ", scope, " cannot be saved:", err)
+			}
+		}
+	}
+}
+
+func trainDict(scope string, ss [][]byte) *stub.WfsDictBean {
+	var train, eval [][]byte
+	for i, s := range ss {
+		if i%dictEvalStep == 0 {
+			eval = append(eval, s)
+		} else {
+			train = append(train, s)
+		}
+	}
+	id := newDictId()
+	data, err := dict.BuildZstdDict(train, dict.Options{MaxDictSize: dictSize, HashBytes: 6, ZstdDictID: uint32(id)})
+	if err != nil {
+		logger.Warn("dictionary of ", scope, " is not trained:", err)
+		return nil
+	}
+	samples, timestramp := int32(len(ss)), time.Now().UnixNano()
+	wdb := &stub.WfsDictBean{Id: &id, Scope: &scope, Data: data, Samples: &samples, Timestramp: &timestramp}
+	d, err := newZdict(wdb)
+	if err != nil {
+		return nil
+	}
+	defer d.dec.Close()
+	enc, err := d.encoder(zstd.SpeedDefault)
+	if err != nil {
+		return nil
+	}
+	var size, plain, dicted int64
+	for _, s := range eval {
+		size += int64(len(s))
+		if bs, err := compress.Zstd(s); err == nil {
+			plain += int64(len(bs))
+		}
+		dicted += int64(len(enc.EncodeAll(s, nil)))
+	}
+	if dicted >= plain {
+		logger.Warn("dictionary of ", scope, " is dropped, it does not compress better")
+		return nil
+	}
+	wdb.Size, wdb.Plain, wdb.Dicted = &size, &plain, &dicted
+	return wdb
+}
+
+// newDictId returns an unused id of the range of zstd for private dictionaries, random so that
+// the dictionaries of imported data keep their ids
+func newDictId() (id int32) {
+	for {
+		if id = 32768 + rand.Int31n(1<<31-32768); !exist(dictKey(id)) {
+			return
+		}
+	}
+}
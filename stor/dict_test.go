@@ -0,0 +1,86 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+// record is a small json document like the ones a dictionary is trained for
+func record(i int) []byte {
+	return []byte(fmt.Sprintf(`{"id":%d,"name":"user%d","email":"user%d@example.com","status":"active","roles":["reader","writer"],"address":{"city":"city%d","street":"street %d","zip":"%05d"},"created":"2023-01-%02dT10:00:00Z"}`, i, i, i, i%7, i%13, i*7, i%28+1))
+}
+
+func TestDictTrain(t *testing.T) {
+	dir := t.TempDir()
+	startStore(dir)
+	defer CloseAll()
+	zstdDict, prefix := sys.ZstdDict, sys.ZstdDictPrefix
+	defer func() {
+		sys.ZstdDict, sys.ZstdDictPrefix = zstdDict, prefix
+		dicts, scopeDicts = &sync.Map{}, &sync.Map{}
+	}()
+	sys.ZstdDict, sys.ZstdDictPrefix = true, []string{"j/"}
+	dicts, scopeDicts = &sync.Map{}, &sync.Map{}
+	for i := 0; i < 200; i++ {
+		if _, err := fe.append(fmt.Sprint("j/", i), record(i), 2, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, wfb := fe.getFileBean("j/0"); wfb.GetDict() != 0 {
+		t.Fatal("data is compressed with a dictionary before the training")
+	}
+	if err := dictTrain(); err != nil {
+		t.Fatal(err)
+	}
+	stopTasks()
+	ds := dictStatus()
+	if ds.Running || ds.Samples != 200 || len(ds.Dicts) != 1 {
+		t.Fatalf("%+v", ds)
+	}
+	if di := ds.Dicts[0]; di.Scope != "prefix:j/" || !di.Current || di.Dicted >= di.Plain || di.Samples != 200 {
+		t.Fatalf("%+v", di)
+	}
+	// the new data of the scope is compressed with the dictionary, the data of the other scopes is not
+	fe.append("j/new", record(1000), 2, nil)
+	fe.append("k/new", record(1001), 2, nil)
+	_, wfb := fe.getFileBean("j/new")
+	if wfb.GetDict() != ds.Dicts[0].Id || !bytes.Equal(fe.getData("j/new"), record(1000)) {
+		t.Fatal("dictionary of j/new:", wfb.GetDict())
+	}
+	// the content type is the scope of the data out of the prefixes, it has too few samples for a dictionary
+	if _, wfb = fe.getFileBean("k/new"); wfb.GetDict() != 0 {
+		t.Fatal("dictionary of k/new:", wfb.GetDict())
+	}
+	// the dictionaries are loaded from the metadata
+	dicts, scopeDicts = &sync.Map{}, &sync.Map{}
+	restart(dir)
+	if !bytes.Equal(fe.getData("j/new"), record(1000)) || !bytes.Equal(fe.getData("j/7"), record(7)) {
+		t.Fatal("data after restart")
+	}
+	if ds = dictStatus(); len(ds.Dicts) != 1 || !ds.Dicts[0].Current {
+		t.Fatalf("%+v", ds)
+	}
+	consistent(t)
+}
+
+func TestDictMode(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	mode := sys.Mode
+	defer func() { sys.Mode = mode }()
+	sys.Mode = 0
+	if err := dictTrain(); err != sys.ERR_DICT_MODE {
+		t.Fatal(err)
+	}
+}
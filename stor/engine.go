@@ -11,6 +11,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -40,24 +41,34 @@ func (t *servie) Serve() (err error) {
 
 func (t *servie) Close() (err error) {
 	stopstat = true
+	stopTasks()
 	<-time.After(2 * time.Second)
+	if journal != nil {
+		journal.close()
+	}
 	for _, ldb := range dbMap {
 		ldb.Close()
 	}
-	dataEg.mm.Range(func(k uint64, v *Mmap) bool {
-		v.Unmap()
-		return true
-	})
+	dataEg.mm.closeAll()
 	return
 }
 
 var wfsdb DB
 var stopstat bool
+
+// stopc is closed when the service stops, the background tasks return then
+var stopc = make(chan struct{})
+var tasks sync.WaitGroup
 var seq int64
+
+// seqMux keeps SEQ at the largest id, an id is taken and written with its path record under it
+var seqMux = &sync.Mutex{}
+
 var count int64
-var nextfn *fileHandler
-var defragStat = false
-var defragfile *os.File
+var nextfn atomic.Pointer[fileHandler]
+
+// defragRun counts the nodes being defragmented
+var defragRun int32
 var unmountmap = &sync.Map{}
 var defragmap = &sync.Map{}
 
@@ -66,6 +77,8 @@ func init() {
 	sys.AppendData = fe.append
 	sys.GetData = fe.getData
 	sys.DelData = fe.delData
+	sys.StatData = fe.stat
+	sys.ListPaths = fe.list
 	//sys.add = fe.add
 	//sys.Del = fe.del
 	sys.Count = fe.count
@@ -74,7 +87,7 @@ func init() {
 	sys.Contains = fe.has
 	sys.SearchLimit = fe.findLimit
 	sys.FragAnalysis = fe.fragAnalysis
-	sys.Defrag = fe.defragAndCover
+	sys.Defrag = defragOne
 	sys.Modify = fe.modify
 	sys.Import = importData
 	sys.ImportFile = importFile
@@ -85,17 +98,39 @@ func init() {
 	sys.IsEmptyBigFile = isEmptyBigFile
 }
 
-func initStore() (err error) {
+func openDB() (err error) {
 	if sys.Conf.DBConfig != nil {
-		if wfsdb, err = New(sys.Conf.DBConfig); err != nil {
-			fmt.Println("init error:" + err.Error())
-			os.Exit(1)
-		}
+		wfsdb, err = New(sys.Conf.DBConfig)
 	} else {
-		if wfsdb, err = NewWithDefaults(sys.Conf.DBType); err != nil {
-			fmt.Println("init error:" + err.Error())
-			os.Exit(1)
-		}
+		wfsdb, err = NewWithDefaults(sys.Conf.DBType)
+	}
+	return
+}
+
+// goTask runs f in the background, the service waits for it when it stops
+func goTask(f func()) {
+	tasks.Add(1)
+	go func() {
+		defer tasks.Done()
+		f()
+	}()
+}
+
+// stopTasks stops the background tasks and waits for them to return
+func stopTasks() {
+	select {
+	case <-stopc:
+	default:
+		close(stopc)
+	}
+	tasks.Wait()
+}
+
+func initStore() (err error) {
+	stopc = make(chan struct{})
+	if err = openDB(); err != nil {
+		fmt.Println("init error:" + err.Error())
+		os.Exit(1)
 	}
 
 	var wfsCurrent string
@@ -115,43 +150,77 @@ func initStore() (err error) {
 	} else {
 		wfsdb.Put(VERSION_, []byte(sys.VERSION))
 	}
+	if err = initJournal(); err != nil {
+		fmt.Println("init journal error:" + err.Error())
+		os.Exit(1)
+	}
+	initHash()
+	if err = initCrypt(); err != nil {
+		fmt.Println("init encryption error:" + err.Error())
+		os.Exit(1)
+	}
+	recoverIntents(wfsCurrent)
 	initDefrag()
 	if err = openFileEg(wfsCurrent); err == nil {
 		initcache()
-		go storTk()
+		initExpire()
+		initScrub()
+		initRehash()
+		initDict()
+		initEncrypt()
+		initErasure()
+		initTier()
+		initAutoDefrag()
+		initMmap()
+		goTask(storTk)
 	}
 	return
 }
 
+// initDefrag removes the temporary files and resumes the interrupted defragmentations
 func initDefrag() {
-	filepath.WalkDir(sys.WFSDATA+"/wfsfile", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() {
-			if strings.Contains(d.Name(), "_") {
-				name := d.Name()
-				name = name[:strings.Index(name, "_")]
-				if id, ok := strToInt(name); ok && util.CheckNodeId(int64(id)) {
-					os.Remove(path)
-					fe.defragAndCover(name)
-				}
-			} else if id, ok := strToInt(d.Name()); ok && util.CheckNodeId(int64(id)) {
-				if isEmptyBigFile(path) {
+	for _, dir := range sys.FileDirs() {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				if strings.Contains(d.Name(), "_") {
+					name := d.Name()
+					name = name[:strings.Index(name, "_")]
+					if id, ok := strToInt(name); ok && util.CheckNodeId(int64(id)) {
+						os.Remove(path)
+					}
+				} else if strings.HasSuffix(d.Name(), ".move") || strings.HasSuffix(d.Name(), ".tmp") {
 					os.Remove(path)
+				} else if id, ok := strToInt(d.Name()); ok && util.CheckNodeId(int64(id)) {
+					if isEmptyBigFile(path) {
+						dropEmptyNode(d.Name(), path)
+					}
 				}
 			}
-		}
-		return nil
-	})
+			return nil
+		})
+	}
+	resumeCompactions()
+}
+
+// dropEmptyNode removes the empty file of a node, with the metadata of the node if nothing was appended to it
+func dropEmptyNode(node, path string) {
+	if v, err := wfsdb.Get(append(ENDOFFSET_, nodeBytes(node)...)); err == nil && v != nil && goutil.BytesToInt64(v) == 0 {
+		removeNode(node)
+	} else {
+		os.Remove(path)
+	}
 }
 
 func isEmptyBigFile(path string) bool {
 	if f, err := os.Open(path); err == nil {
 		defer f.Close()
-		bs := make([]byte, fingerprintLen()*10)
+		step := hashLen(nodeHash(filepath.Base(path)))
+		bs := make([]byte, step*10)
 		f.Read(bs)
-		if bytes.Compare(bs, make([]byte, fingerprintLen()*10)) == 0 {
+		if bytes.Compare(bs, make([]byte, step*10)) == 0 {
 			return true
 		}
 	}
@@ -162,12 +231,12 @@ var lockLevel1 = lock.NewNumLock(1 << 9)
 
 var lockLevel2 = lock.NewNumLock(1 << 9)
 
-var dataEg = &dataHandler{mm: NewMap[uint64, *Mmap]()}
+var dataEg = &dataHandler{mm: newMmPool()}
 
 var referMap = NewLimitHashMap[string, *int32](1 << 15)
 
 type dataHandler struct {
-	mm *Map[uint64, *Mmap]
+	mm *mmPool
 }
 
 func (t *dataHandler) openMMap(node string) (_r bool) {
@@ -175,65 +244,67 @@ func (t *dataHandler) openMMap(node string) (_r bool) {
 		lockid := goutil.Hash64(append(OPENMMAPLOCK_, goutil.Int64ToBytes(int64(id))...))
 		lockLevel2.Lock(int64(lockid))
 		defer lockLevel2.Unlock(int64(lockid))
-		if !t.mm.Has(id) {
+		if !t.mm.has(id) {
 			path := getpathBynode(node)
-			if goutil.IsFileExist(path) {
+			if fi, err := os.Stat(path); err == nil && t.mm.admit(id, fi.Size()) {
 				if f, err := util.OpenFile(path, os.O_CREATE|os.O_RDWR, 0666); err == nil {
 					if n, err := NewMMAP(f, 0); err == nil {
-						t.mm.Put(id, n)
+						t.mm.put(id, n, false)
 						_r = true
+					} else {
+						f.Close()
 					}
 				}
 			}
 		}
-		_r = t.mm.Has(id)
+		_r = t.mm.has(id)
 	}
 	return
 }
 
+// getData reads the data of a block, the read is recorded as an access of node
 func (t *dataHandler) getData(node string, offset int64, size int64) (bs []byte, ok bool) {
-	if defragStat {
-		if _, b := defragmap.Load(node); b {
-			if bs, b := t.getDataByfile(offset, size); b {
-				return bs, b
-			}
-		}
+	touchNode(node)
+	return t.readData(node, offset, size)
+}
+
+// readData reads size bytes at offset of node from its map, or from its file if it is not mapped
+func (t *dataHandler) readData(node string, offset int64, size int64) (bs []byte, ok bool) {
+	if s := nodeShards(node); s != nil {
+		return s.read(offset, size)
 	}
 	if id, b := strToInt(node); b {
-		if _, ok := unmountmap.Load(id); !ok && !t.mm.Has(id) {
-			t.openMMap(node)
-		}
-		if m, b := t.mm.Get(id); b {
-			if offset+size <= int64(len(m.Bytes())) {
-				bs, ok = m.Bytes()[offset:offset+size], true
+		if _, unmount := unmountmap.Load(id); !unmount {
+			if !t.mm.has(id) {
+				t.openMMap(node)
+			}
+			if m := t.mm.acquire(id); m != nil {
+				if offset+size <= int64(len(m.mm.Bytes())) {
+					bs, ok = bytes.Clone(m.mm.Bytes()[offset:offset+size]), true
+				}
+				t.mm.release(m)
+			} else {
+				bs, ok = t.mm.pread(node, offset, size)
 			}
 		}
 	}
 	return
 }
 
-func (t *dataHandler) getDataByfile(offset int64, size int64) (bs []byte, ok bool) {
-	defer util.Recover()
-	if defragfile != nil {
-		bs = make([]byte, size)
-		if n, err := defragfile.ReadAt(bs, offset); err == nil && n == int(size) {
-			return bs, true
-		} else {
-			return nil, false
-		}
-	}
-	return
-}
-
+// reSetMMap keeps m as the map of node, it is appended to and stays mapped until sealNode
 func (t *dataHandler) reSetMMap(node string, m *Mmap) {
 	if id, b := strToInt(node); b {
 		lockid := goutil.Hash64(append(RESETMMAPLOCK_, goutil.Int64ToBytes(int64(id))...))
 		lockLevel2.Lock(int64(lockid))
 		defer lockLevel2.Unlock(int64(lockid))
-		if oldm, b := t.mm.Get(id); b {
-			oldm.UnmapAndCloseFile()
-		}
-		t.mm.Put(id, m)
+		t.mm.put(id, m, true)
+	}
+}
+
+// sealNode lets the map of node be evicted, it is not appended to anymore
+func (t *dataHandler) sealNode(node string) {
+	if id, b := strToInt(node); b {
+		t.mm.unfix(id)
 	}
 }
 
@@ -244,10 +315,7 @@ func (t *dataHandler) unMmap(node string) {
 }
 
 func (t *dataHandler) unMmapById(id uint64) {
-	if oldm, b := t.mm.Get(id); b {
-		oldm.UnmapAndCloseFile()
-	}
-	t.mm.Del(id)
+	t.mm.del(id)
 }
 
 var fe = &fileEg{mux: &sync.Mutex{}}
@@ -274,7 +342,7 @@ func (t *fileEg) count() int64 {
 	return count
 }
 func (t *fileEg) seq() int64 {
-	return seq
+	return atomic.LoadInt64(&seq)
 }
 
 func (t *fileEg) findLike(pathprx string) (_r []*sys.PathBean) {
@@ -288,10 +356,14 @@ func (t *fileEg) findLike(pathprx string) (_r []*sys.PathBean) {
 			if wpbbs, err := wfsdb.Get(pathseqkey); err == nil {
 				wpb := bytesToWfsPathBean(wpbbs)
 				if bs := t.getData(*wpb.Path); bs != nil {
-					pb := &sys.PathBean{Id: i, Path: *wpb.Path, Body: bs, Timestramp: *wpb.Timestramp}
+					pb := &sys.PathBean{Id: i, Path: *wpb.Path, Body: bs, Size: int64(len(bs)), Timestramp: *wpb.Timestramp}
 					_r = append(_r, pb)
-				} else {
+				} else if _, wfb := t.getFileBean(*wpb.Path); wfb == nil {
+					// the path is dropped only if its bean is gone or expired, a quarantined block keeps its path
 					t.delData(*wpb.Path)
+				} else if len(wfb.Parts) > 0 && wfb.GetSize() > sys.DataMaxsize {
+					// a multipart file larger than data.maxsize is listed without its body
+					_r = append(_r, &sys.PathBean{Id: i, Path: *wpb.Path, Size: wfb.GetSize(), Timestramp: *wpb.Timestramp})
 				}
 			}
 		}
@@ -300,7 +372,7 @@ func (t *fileEg) findLike(pathprx string) (_r []*sys.PathBean) {
 }
 
 func (t *fileEg) findLimit(start, limit int64) (_r []*sys.PathBean) {
-	if start-limit > seq {
+	if start-limit > atomic.LoadInt64(&seq) {
 		return
 	}
 	defer util.Recover()
@@ -311,11 +383,16 @@ func (t *fileEg) findLimit(start, limit int64) (_r []*sys.PathBean) {
 		if wpbbs, err := wfsdb.Get(pathseqkey); err == nil {
 			wpb := bytesToWfsPathBean(wpbbs)
 			if bs := t.getData(*wpb.Path); bs != nil {
-				pb := &sys.PathBean{Id: i, Path: *wpb.Path, Body: bs, Timestramp: *wpb.Timestramp}
+				pb := &sys.PathBean{Id: i, Path: *wpb.Path, Body: bs, Size: int64(len(bs)), Timestramp: *wpb.Timestramp}
 				_r = append(_r, pb)
 				count++
-			} else {
+			} else if _, wfb := t.getFileBean(*wpb.Path); wfb == nil {
+				// the path is dropped only if its bean is gone or expired, a quarantined block keeps its path
 				t.delData(*wpb.Path)
+			} else if len(wfb.Parts) > 0 && wfb.GetSize() > sys.DataMaxsize {
+				// a multipart file larger than data.maxsize is listed without its body
+				_r = append(_r, &sys.PathBean{Id: i, Path: *wpb.Path, Size: wfb.GetSize(), Timestramp: *wpb.Timestramp})
+				count++
 			}
 		} else if i > seq {
 			count++
@@ -324,7 +401,7 @@ func (t *fileEg) findLimit(start, limit int64) (_r []*sys.PathBean) {
 	return
 }
 
-func (t *fileEg) append(path string, bs []byte, compressType int32) (id int64, _r sys.ERROR) {
+func (t *fileEg) append(path string, bs []byte, compressType int32, mb *sys.MetaBean) (id int64, _r sys.ERROR) {
 	if stopstat {
 		return id, sys.ERR_STOPSERVICE
 	}
@@ -342,29 +419,72 @@ func (t *fileEg) append(path string, bs []byte, compressType int32) (id int64, _
 	lockLevel1.Lock(int64(lockid))
 	defer lockLevel1.Unlock(int64(lockid))
 
-	if nf, _r = t.handler.append(path, bs, compressType); _r != nil && _r.Equal(sys.ERR_FILEAPPEND) {
+	if expired(path) {
+		t.remove(path)
+	}
+
+	expire := expireOf(path, mb)
+	it := newIntent(bindBean(path, fingerprint(bs), nil, compressType, journalMeta(mb, expire)))
+	if nf, _r = t.handler.append(path, bs, compressType, it); _r != nil && _r.Equal(sys.ERR_FILEAPPEND) {
 		if err := t.next(node); err == nil {
-			nf, _r = t.handler.append(path, bs, compressType)
+			nf, _r = t.handler.append(path, bs, compressType, it)
 		} else {
+			it.drop()
 			return id, sys.ERR_FILECREATE
 		}
 	}
 
-	if nf && _r == nil && sys.Mode == 1 {
-		m := make(map[*[]byte][]byte, 0)
-		id = atomic.AddInt64(&seq, 1)
-
-		m[&SEQ] = goutil.Int64ToBytes(seq)
-		pathpre := append(PATH_PRE, []byte(path)...)
-		m[&pathpre] = goutil.Int64ToBytes(id)
+	if _r == nil {
+		if nf {
+			putExpire(path, expire)
+			if sys.Mode == 1 {
+				id = putPathIndex(path, mb)
+			}
+			fault("index")
+		}
+		it.done()
+	} else if !_r.Equal(sys.ERR_UNDEFINED) {
+		// the intent of a failed metadata write is kept, the block it stored is released at the next start
+		it.drop()
+	}
+	return
+}
 
-		pathseqkey := append(PATH_SEQ, goutil.Int64ToBytes(id)...)
-		t := time.Now().UnixNano()
-		wpb := &stub.WfsPathBean{Path: &path, Timestramp: &t}
-		m[&pathseqkey] = wfsPathBeanToBytes(wpb)
+func (t *fileEg) appendBlock(path string, bs []byte, compressType int32) (bidBs []byte, _r sys.ERROR) {
+	node := t.handler.Node
+	if bidBs, _r = t.handler.appendBlock(path, bs, compressType); _r != nil && _r.Equal(sys.ERR_FILEAPPEND) {
+		if err := t.next(node); err == nil {
+			bidBs, _r = t.handler.appendBlock(path, bs, compressType)
+		} else {
+			return nil, sys.ERR_FILECREATE
+		}
+	}
+	return
+}
 
-		wfsdb.BatchPut(m)
+// putPathIndex adds the path record of path, the content type and the user metadata of mb are kept in it
+func putPathIndex(path string, mb *sys.MetaBean) (id int64) {
+	m := make(map[*[]byte][]byte, 0)
+	seqMux.Lock()
+	defer seqMux.Unlock()
+	id = atomic.AddInt64(&seq, 1)
+
+	m[&SEQ] = goutil.Int64ToBytes(id)
+	pathpre := append(PATH_PRE, []byte(path)...)
+	m[&pathpre] = goutil.Int64ToBytes(id)
+
+	pathseqkey := append(PATH_SEQ, goutil.Int64ToBytes(id)...)
+	t := time.Now().UnixNano()
+	wpb := &stub.WfsPathBean{Path: &path, Timestramp: &t}
+	if mb != nil {
+		if mb.ContentType != "" {
+			wpb.ContentType = &mb.ContentType
+		}
+		wpb.Meta = mb.Meta
 	}
+	m[&pathseqkey] = wfsPathBeanToBytes(wpb)
+
+	wfsdb.BatchPut(m)
 	return
 }
 
@@ -374,12 +494,160 @@ func (t *fileEg) getData(path string) (_r []byte) {
 	}
 	defer util.Recover()
 	tasklimit()
-	fidbs := fingerprint([]byte(path))
-	if v, err := cacheGet(fidbs); err == nil {
-		if v, err = cacheGet(v); err == nil {
-			wfb := bytesToWfsFileBean(v)
-			if bs, b := dataEg.getData(*wfb.Storenode, *wfb.Offset, int64(fileoffset())+*wfb.Size); b {
-				_r = praseUncompress(bs[fileoffset():], *wfb.CompressType)
+	if bidBs, wfb := t.getFileBean(path); wfb != nil && !quarantined(bidBs) {
+		_r = readBlock(bidBs, wfb)
+	}
+	return
+}
+
+// stat reads the metadata of path from its file bean and path bean, the data is not read
+func (t *fileEg) stat(path string) (_r *sys.StatBean) {
+	if stopstat {
+		return nil
+	}
+	defer util.Recover()
+	if bidBs, wfb := t.getFileBean(path); wfb != nil {
+		_r = &sys.StatBean{Path: path, Size: dataSize(wfb), StoredSize: wfb.GetSize(), CompressType: wfb.GetCompressType(), Refercount: wfb.GetRefercount(), Fingerprint: bidBs}
+		if wfb.Storenode != nil {
+			_r.Node, _r.Offset = wfb.GetStorenode(), wfb.GetOffset()
+		} else {
+			_r.StoredSize = 0
+			for _, p := range wfb.Parts {
+				if v, err := cacheGet(p.Fingerprint); err == nil && len(v) > 0 {
+					if pwfb := bytesToWfsFileBean(v); pwfb != nil {
+						_r.StoredSize += pwfb.GetSize()
+					}
+				}
+			}
+		}
+		if wpb := getPathBean(path); wpb != nil {
+			_r.Timestramp, _r.ContentType, _r.Meta = wpb.GetTimestramp(), wpb.GetContentType(), wpb.GetMeta()
+		}
+		_r.Expire = cachedExpire(path)
+	}
+	return
+}
+
+// list pages through the paths of prefix in order, starting after startAfter. With a delimiter, the paths
+// sharing the part of the path up to the first delimiter after prefix are collapsed into one common prefix.
+// Next is the cursor for the following page, empty when there is no more.
+func (t *fileEg) list(prefix, startAfter, delimiter string, limit int) (_r *sys.ListResult) {
+	if stopstat {
+		return nil
+	}
+	defer util.Recover()
+	_r = &sys.ListResult{Entries: make([]*sys.ListBean, 0), Prefixes: make([]string, 0)}
+	if limit <= 0 {
+		return
+	}
+	limit = min(limit, maxListLimit)
+	pathpre := append(PATH_PRE, []byte(prefix)...)
+	start := pathpre
+	if startAfter != "" {
+		start = append(append(PATH_PRE, []byte(startAfter)...), 0)
+		if cp := commonPrefix(startAfter, prefix, delimiter); cp != "" {
+			start = prefixEnd(append(PATH_PRE, []byte(cp)...))
+		}
+	}
+	var last string
+	for n := 0; n < limit; {
+		want := limit - n
+		keys, err := wfsdb.GetKeysPrefixLimit(pathpre, start, want)
+		if err != nil {
+			return
+		}
+		i := 0
+		for ; i < len(keys); i++ {
+			path := string(keys[i][len(PATH_PRE):])
+			if cp := commonPrefix(path, prefix, delimiter); cp != "" {
+				_r.Prefixes = append(_r.Prefixes, cp)
+				last, start = cp, prefixEnd(append(PATH_PRE, []byte(cp)...))
+				n++
+				break
+			}
+			if lb := listBean(path); lb != nil {
+				_r.Entries = append(_r.Entries, lb)
+				last = path
+				n++
+			}
+			start = append(keys[i], 0)
+		}
+		if i == len(keys) && len(keys) < want {
+			return
+		}
+	}
+	if keys, err := wfsdb.GetKeysPrefixLimit(pathpre, start, 1); err == nil && len(keys) > 0 {
+		_r.Next = last
+	}
+	return
+}
+
+func listBean(path string) (_r *sys.ListBean) {
+	if bidBs, wfb := fe.getFileBean(path); wfb != nil {
+		_r = &sys.ListBean{Path: path, Size: dataSize(wfb), Fingerprint: bidBs, CompressType: wfb.GetCompressType()}
+		if wpb := getPathBean(path); wpb != nil {
+			_r.Timestramp = wpb.GetTimestramp()
+		}
+	}
+	return
+}
+
+// commonPrefix returns path up to the first delimiter after prefix, or empty if there is none
+func commonPrefix(path, prefix, delimiter string) string {
+	if delimiter != "" && strings.HasPrefix(path, prefix) {
+		if i := strings.Index(path[len(prefix):], delimiter); i >= 0 {
+			return path[:len(prefix)+i+len(delimiter)]
+		}
+	}
+	return ""
+}
+
+// getFileBean returns the file bean of path, an expired path is not found even before it is swept
+func (t *fileEg) getFileBean(path string) (bidBs []byte, wfb *stub.WfsFileBean) {
+	if expired(path) {
+		return
+	}
+	fidbs := pathKey(path)
+	if v, err := cacheGet(fidbs); err == nil && len(v) > 0 {
+		if wfbbs, err := cacheGet(v); err == nil && len(wfbbs) > 0 {
+			bidBs, wfb = v, bytesToWfsFileBean(wfbbs)
+		}
+	}
+	return
+}
+
+// readBlock reads the data of the block bidBs by wfb, it is read again by the stored bean if the block is moved
+// from the node of wfb, as a defragmentation does
+func readBlock(bidBs []byte, wfb *stub.WfsFileBean) (_r []byte) {
+	if _r = readFileBean(wfb); _r == nil && len(wfb.Parts) == 0 {
+		if v, err := wfsdb.Get(bidBs); err == nil && len(v) > 0 {
+			if nwfb := bytesToWfsFileBean(v); nwfb != nil && nwfb.Storenode != nil && (nwfb.GetStorenode() != wfb.GetStorenode() || nwfb.GetOffset() != wfb.GetOffset()) {
+				cachePut(bidBs, v)
+				_r = readFileBean(nwfb)
+			}
+		}
+	}
+	return
+}
+
+func readFileBean(wfb *stub.WfsFileBean) (_r []byte) {
+	if len(wfb.Parts) > 0 {
+		return readParts(wfb)
+	}
+	offset := nodeOffset(*wfb.Storenode)
+	if bs, b := dataEg.getData(*wfb.Storenode, *wfb.Offset, offset+*wfb.Size); b {
+		if payload := openBlock(*wfb.Storenode, bs[:offset-4], bs[offset:]); payload != nil {
+			_r = uncompressBean(payload, wfb)
+		}
+	}
+	return
+}
+
+func getPathBean(path string) (wpb *stub.WfsPathBean) {
+	if sys.Mode == 1 {
+		if v, err := wfsdb.Get(append(PATH_PRE, []byte(path)...)); err == nil && v != nil {
+			if wpbbs, err := wfsdb.Get(append(PATH_SEQ, v...)); err == nil && wpbbs != nil {
+				wpb = bytesToWfsPathBean(wpbbs)
 			}
 		}
 	}
@@ -392,7 +660,10 @@ func (t *fileEg) has(path string) (b bool) {
 	}
 	defer util.Recover()
 	tasklimit()
-	if v, err := cacheGet(fingerprint([]byte(path))); err == nil {
+	if expired(path) {
+		return
+	}
+	if v, err := cacheGet(pathKey(path)); err == nil {
 		if v, err = cacheGet(v); err == nil {
 			b = true
 		}
@@ -405,43 +676,39 @@ func (t *fileEg) delData(path string) (_r sys.ERROR) {
 		return sys.ERR_STOPSERVICE
 	}
 	defer util.Recover()
-	fidbs := fingerprint([]byte(path))
-	batchmap := make(map[*[]byte][]byte, 0)
-	dels := [][]byte{fidbs}
+	lockid := goutil.Hash64(append(APPENDLOCK_, []byte(path)...))
+	lockLevel1.Lock(int64(lockid))
+	defer lockLevel1.Unlock(int64(lockid))
+	return t.remove(path)
+}
+
+// remove deletes path, the caller holds the lock of path
+func (t *fileEg) remove(path string) (_r sys.ERROR) {
+	fidbs := pathKey(path)
+	bat := newBatch()
 	if oldBidBs, err := wfsdb.Get(fidbs); err == nil && oldBidBs != nil {
-		if oldWffsBs, err := wfsdb.Get(oldBidBs); err == nil && oldWffsBs != nil {
-			oldwffs := bytesToWfsFileBean(oldWffsBs)
-			nid, _ := strToInt(*oldwffs.Storenode)
-			nidbs := goutil.Int64ToBytes(int64(nid))
-			*oldwffs.Refercount -= 1
-			if *oldwffs.Refercount <= 0 {
-				if nodebs, err := wfsdb.Get(nidbs); err == nil && nodebs != nil {
-					wnb := bytesToWfsNodeBean(nodebs)
-					*wnb.Rmsize = *wnb.Rmsize + *oldwffs.Size
-					batchmap[&nidbs] = wfsNodeBeanToBytes(wnb)
-					dels = append(dels, oldBidBs)
-				}
-			} else {
-				batchmap[&oldBidBs] = wfsFileBeanToBytes(oldwffs)
-			}
-		}
+		bat.del(fidbs)
+		bat.unrefer(oldBidBs)
 		if sys.Mode == 1 {
 			pathpre := append(PATH_PRE, []byte(path)...)
-			if v, err := wfsdb.Get(pathpre); err == nil {
-				dels = append(dels, pathpre)
-				dels = append(dels, append(PATH_SEQ, v...))
+			if v, err := wfsdb.Get(pathpre); err == nil && v != nil {
+				bat.del(pathpre)
+				bat.del(append(PATH_SEQ, v...))
 			}
 		}
-		batchmap[&COUNT] = goutil.Int64ToBytes(atomic.AddInt64(&count, -1))
+		bat.delExpire(path)
+		bat.put(COUNT, goutil.Int64ToBytes(atomic.AddInt64(&count, -1)))
 	} else {
 		return sys.ERR_NOTEXSIT
 	}
 
-	if err := wfsdb.Batch(batchmap, dels); err != nil {
+	it := newIntent(deleteBean(path))
+	if err := bat.commit(); err != nil {
+		it.drop()
 		return sys.ERR_UNDEFINED
-	} else {
-		cacheDel(fidbs)
 	}
+	fault("commit")
+	it.done()
 	return
 }
 
@@ -452,10 +719,13 @@ func (t *fileEg) modify(path, newpath string) (err sys.ERROR) {
 	if path == "" || newpath == "" || path == newpath {
 		return sys.ERR_PARAMS
 	}
+	lockid := goutil.Hash64(append(APPENDLOCK_, []byte(path)...))
+	lockLevel1.Lock(int64(lockid))
+	defer lockLevel1.Unlock(int64(lockid))
 	am := make(map[*[]byte][]byte, 0)
 	dm := make([][]byte, 0)
 
-	fidbs := fingerprint([]byte(path))
+	fidbs := pathKey(path)
 	dm = append(dm, fidbs)
 
 	newfidbs := fingerprint([]byte(newpath))
@@ -485,9 +755,21 @@ func (t *fileEg) modify(path, newpath string) (err sys.ERROR) {
 	} else {
 		return sys.ERR_NOTEXSIT
 	}
-	if _, err := wfsdb.Get(newfidbs); err != nil && len(am) > 0 && len(dm) > 0 {
+	if e := getExpire(path); e > 0 {
+		tk, ek := ttlKey(newpath), expireKey(e, newpath)
+		am[&tk], am[&ek] = goutil.Int64ToBytes(e), []byte{0}
+		dm = append(dm, ttlKey(path), expireKey(e, path))
+	}
+	if _, err := wfsdb.Get(pathKey(newpath)); err != nil && len(am) > 0 && len(dm) > 0 {
+		it := newIntent(renameBean(path, newpath))
 		if wfsdb.Batch(am, dm) == nil {
 			cacheDel(fidbs)
+			cacheDel(ttlKey(path))
+			cacheDel(ttlKey(newpath))
+			fault("commit")
+			it.done()
+		} else {
+			it.drop()
 		}
 	} else {
 		return sys.ERR_NEWPATHEXIST
@@ -497,17 +779,29 @@ func (t *fileEg) modify(path, newpath string) (err sys.ERROR) {
 
 var atomicflag int32 = 0
 
+// current returns the node appended to, the background tasks read it while next replaces it
+func (t *fileEg) current() string {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.handler == nil {
+		return ""
+	}
+	return t.handler.Node
+}
+
 func (t *fileEg) next(node string) (err error) {
 	t.mux.Lock()
 	defer t.mux.Unlock()
 	if node == t.handler.Node {
-		if nextfn != nil {
-			t.handler = nextfn
+		defer dataEg.sealNode(node)
+		if next := nextfn.Load(); next != nil && !dirReadonly(nodeDir(next.Node)) {
+			t.handler = next
 			usefileHandler(t.handler)
-			nextfn = nil
+			nextfn.Store(nil)
 		} else {
 			t.handler, err = initFileHandler("")
 		}
+		codeNodes()
 	}
 	return
 }
@@ -515,208 +809,72 @@ func (t *fileEg) next(node string) (err error) {
 func newNextfn() {
 	if atomic.CompareAndSwapInt32(&atomicflag, 0, 1) {
 		defer atomic.SwapInt32(&atomicflag, 0)
-		if nextfn == nil {
-			nextfn, _ = newFileHandler()
+		if nextfn.Load() == nil {
+			if next, _ := newFileHandler(); next != nil {
+				nextfn.Store(next)
+			}
 		}
 	}
 }
 
-// Deprecated
-func (t *fileEg) defrag(node string) (err sys.ERROR) {
-	if stopstat {
-		return sys.ERR_STOPSERVICE
-	}
-	defragStat = true
-	defer func() {
-		if e := recover(); e != nil {
-			err = sys.ERR_UNDEFINED
-		}
-		defragStat = false
-		defragmap.Delete(node)
-	}()
-	if v, err := wfsdb.Get(CURRENT); err == nil && v != nil {
-		if string(v) == node {
-			return sys.ERR_DEFRAG_FORBID
-		}
-	}
-
-	nid, b := strToInt(node)
-	if !b {
-		return sys.ERR_NOTEXSIT
-	}
-	dataEg.openMMap(node)
-	mm, b := dataEg.mm.Get(nid)
-	if !b {
-		return sys.ERR_NOTEXSIT
-	}
-
-	newnid := util.CreateNodeId()
-	newnode := intToStr(uint64(newnid))
-	newnidbs := goutil.Int64ToBytes(newnid)
-	newnodepath := getpathBynode(newnode)
-	if err := os.MkdirAll(filepath.Dir(newnodepath), 0777); err != nil {
-		return sys.ERR_UNDEFINED
-	}
-	if f, err := util.OpenFile(newnodepath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666); err == nil {
-		defragfile = f
-		defer f.Close()
-		wl := new(int64)
-		if err = defragFB(mm.Bytes(), f, 0, &newnode, wl, new(int32)); err == nil {
-			var size int64
-			if fs, _ := f.Stat(); fs != nil {
-				size = fs.Size()
-			} else {
-				size = *wl
-			}
-			oldOffsBs := append(ENDOFFSET_, goutil.Int64ToBytes(int64(nid))...)
-			newOffset := append(ENDOFFSET_, goutil.Int64ToBytes(int64(newnid))...)
-			newbat := make(map[*[]byte][]byte, 0)
-			if *wl == 0 && size == 0 {
-				f.Close()
-				os.Remove(newnodepath)
-				f = nil
-			} else {
-				newbat[&newOffset] = goutil.Int64ToBytes(size)
-				newbat[&newnidbs] = wfsNodeBeanToBytes(&stub.WfsNodeBean{Rmsize: new(int64)})
-			}
-			wfsdb.Batch(newbat, [][]byte{oldOffsBs})
-			dataEg.unMmap(node)
-			if err := os.Remove(getpathBynode(node)); err != nil {
-				logger.Error(err)
-			}
-			dataEg.unMmap(newnode)
-			dataEg.openMMap(newnode)
-
-		} else {
-			if fs, e := f.Stat(); e == nil {
-				if fs.Size() == 0 && *wl == 0 {
-					f.Close()
-					os.Remove(newnodepath)
-					f = nil
-				}
-			}
-			return sys.ERR_UNDEFINED
-		}
+// defragNode compacts the live blocks of node into a new node, the target, that replaces it, at the pace of th if
+// it is given. The blocks are read from both nodes meanwhile, a restart resumes from the last checkpoint.
+func (t *fileEg) defragNode(node string, th *defragThrottle) (target string, err sys.ERROR) {
+	var c *compaction
+	if c, err = compactNode(node, nil, 0, th); c != nil {
+		target = c.target
+		dataEg.sealNode(target)
 	}
 	return
 }
 
-func (t *fileEg) defragAndCover(node string) (err sys.ERROR) {
+// compactNode compacts the live blocks of node into the target of into, or into a new target of size bytes at least
+func compactNode(node string, into *compaction, size int64, th *defragThrottle) (c *compaction, err sys.ERROR) {
 	if stopstat {
-		return sys.ERR_STOPSERVICE
+		return nil, sys.ERR_STOPSERVICE
+	}
+	if _, ok := defragmap.LoadOrStore(node, ""); ok {
+		return nil, sys.ERR_DEFRAG_UNDERWAY
 	}
-	defragStat = true
+	atomic.AddInt32(&defragRun, 1)
 	defer func() {
 		if e := recover(); e != nil {
 			err = sys.ERR_UNDEFINED
 		}
-		defragStat = false
+		atomic.AddInt32(&defragRun, -1)
 		defragmap.Delete(node)
 	}()
+	if _, ok := relocating.Load(node); ok {
+		return nil, sys.ERR_DEFRAG_UNDERWAY
+	}
 	if v, err := wfsdb.Get(CURRENT); err == nil && v != nil {
 		if string(v) == node {
-			return sys.ERR_DEFRAG_FORBID
+			return nil, sys.ERR_DEFRAG_FORBID
 		}
 	}
-	nid, b := strToInt(node)
-	if !b {
-		return sys.ERR_NOTEXSIT
+	if joinShards(node) != nil {
+		return nil, sys.ERR_UNDEFINED
 	}
-	dataEg.openMMap(node)
-	mm, b := dataEg.mm.Get(nid)
-	if !b {
-		return sys.ERR_NOTEXSIT
+	c, e := openCompaction(node, into, size)
+	if e != nil {
+		logger.Error("defrag node ", node, " failed:", e)
+		return nil, sys.ERR_UNDEFINED
 	}
-	nidbs := goutil.Int64ToBytes(int64(nid))
-	nodepath := getpathBynode(node)
-
-	newnode := fmt.Sprint(node, "_", util.CreateNodeId())
-	newnodepath := getpathBynode(newnode)
-	if err := os.MkdirAll(filepath.Dir(newnodepath), 0777); err != nil {
-		return sys.ERR_UNDEFINED
+	if c == nil {
+		return nil, sys.ERR_NOTEXSIT
 	}
-	if f, err := util.OpenFile(newnodepath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0666); err == nil {
-		defragfile = f
-		defer f.Close()
-		wl := new(int64)
-		defragmap.Store(node, newnode)
-		if err = defragFB(mm.Bytes(), f, 0, &node, wl, new(int32)); err == nil {
-			var size int64
-			if fs, _ := f.Stat(); fs != nil {
-				size = fs.Size()
-			} else {
-				size = *wl
-			}
-			if *wl == 0 && size == 0 {
-				f.Close()
-				os.Remove(newnodepath)
-				f = nil
-				return sys.ERR_UNDEFINED
-			} else {
-				offsBs := append(ENDOFFSET_, goutil.Int64ToBytes(int64(nid))...)
-				newbat := make(map[*[]byte][]byte)
-				newbat[&offsBs] = goutil.Int64ToBytes(size)
-				newbat[&nidbs] = wfsNodeBeanToBytes(&stub.WfsNodeBean{Rmsize: new(int64)})
-				wfsdb.BatchPut(newbat)
-			}
-			unmountmap.Store(nid, byte(0))
-			defer unmountmap.Delete(nid)
-			dataEg.unMmap(node)
-			dataEg.unMmap(newnode)
-			f.Close()
-			if os.Rename(newnodepath, nodepath) == nil {
-				dataEg.openMMap(node)
-			}
-		} else {
-			if fs, e := f.Stat(); e == nil {
-				if fs.Size() == 0 && *wl == 0 {
-					f.Close()
-					os.Remove(newnodepath)
-					f = nil
-				}
-			}
-			return sys.ERR_UNDEFINED
-		}
+	defragmap.Store(node, c.target)
+	relocating.Store(c.target, byte(0))
+	defer relocating.Delete(c.target)
+	if e = c.run(th); e != nil {
+		logger.Error("defrag node ", node, " failed:", e)
+		return c, sys.ERR_UNDEFINED
 	}
-	return
+	return c, nil
 }
 
 func getpathBynode(node string) string {
-	return sys.WFSDATA + "/wfsfile/" + node
-}
-
-func defragFB(bs []byte, f *os.File, offset int64, node *string, wl *int64, rl *int32) (err error) {
-	defer func() {
-		if e := recover(); e != nil {
-			err = errors.New(fmt.Sprint(e))
-		}
-	}()
-	step := fingerprintLen()
-	if len(bs) > step+4 {
-		if v, e := wfsdb.Get(bs[:step]); e == nil && v != nil {
-			wfb := bytesToWfsFileBean(v)
-			wfb.Storenode = node
-			*wfb.Offset = offset
-			size := goutil.BytesToInt32(bs[step : step+4])
-			if n, e := f.Write(bs[:step+4+int(size)]); e == nil {
-				atomic.AddInt64(wl, int64(n))
-				cacheDel(bs[:step])
-				if err = wfsdb.Put(bs[:step], wfsFileBeanToBytes(wfb)); err == nil {
-					return defragFB(bs[n:], f, offset+int64(n), node, wl, rl)
-				}
-			} else {
-				return e
-			}
-		} else {
-			if size := goutil.BytesToInt32(bs[step : step+4]); size > 0 || *rl < 4 {
-				if size == 0 {
-					atomic.AddInt32(rl, 1)
-				}
-				err = defragFB(bs[step+4+int(size):], f, offset, node, wl, rl)
-			}
-		}
-	}
-	return
+	return nodeDir(node) + "/" + node
 }
 
 func (t *fileEg) fragAnalysis(node string) (fb *sys.FragBean, err sys.ERROR) {
@@ -746,7 +904,9 @@ func (t *fileEg) fragAnalysis(node string) (fb *sys.FragBean, err sys.ERROR) {
 		wnb := bytesToWfsNodeBean(nodebs)
 		fb.RmSize = *wnb.Rmsize
 	}
-	if f, err := os.Stat(getpathBynode(node)); err == nil {
+	if s := nodeShards(node); s != nil {
+		fb.FileSize = s.size
+	} else if f, err := os.Stat(getpathBynode(node)); err == nil {
 		fb.FileSize = f.Size()
 	}
 	return
@@ -770,7 +930,8 @@ func initFileHandler(node string) (fh *fileHandler, err error) {
 	} else {
 		nid, _ := strToInt(node)
 		nidbs := goutil.Int64ToBytes(int64(nid))
-		if endoffsetBs, err := wfsdb.Get(append(ENDOFFSET_, nidbs...)); err == nil {
+		// a node of a previous hash algorithm, or not encrypted as configured, is not appended to
+		if endoffsetBs, err := wfsdb.Get(append(ENDOFFSET_, nidbs...)); err == nil && nodeHash(node) == currentHash() && nodeSecret(node).fits() {
 			if endOffset := goutil.BytesToInt64(endoffsetBs); endOffset < sys.FileSize {
 				nodepath := getpathBynode(node)
 				if goutil.IsFileExist(nodepath) {
@@ -786,8 +947,7 @@ func initFileHandler(node string) (fh *fileHandler, err error) {
 		}
 
 		if !fine {
-			initFileHandler("")
-			return
+			return initFileHandler("")
 		}
 	}
 	if !fine {
@@ -797,8 +957,18 @@ func initFileHandler(node string) (fh *fileHandler, err error) {
 }
 
 func newFileHandler() (fh *fileHandler, err error) {
+	var dir string
+	if dir, err = placeNode(); err != nil {
+		return
+	}
+	return newNodeIn(dir)
+}
+
+// newNodeIn creates the file of a new node in dir
+func newNodeIn(dir string) (fh *fileHandler, err error) {
 	nid := util.CreateNodeId()
 	node := intToStr(uint64(nid))
+	nodeDirs.Store(node, dir)
 	var f *os.File
 	nodepath := getpathBynode(node)
 	if err = os.MkdirAll(filepath.Dir(nodepath), 0777); err != nil {
@@ -817,13 +987,26 @@ func newFileHandler() (fh *fileHandler, err error) {
 }
 
 func usefileHandler(fh *fileHandler) (err error) {
-	if nid, b := strToInt(fh.Node); b {
+	return registerNode(fh.Node, true)
+}
+
+// registerNode records the metadata of a new node, it becomes the current node if current is set
+func registerNode(node string, current bool) (err error) {
+	if nid, b := strToInt(node); b {
 		nidbs := goutil.Int64ToBytes(int64(nid))
 		fmap := make(map[*[]byte][]byte, 0)
 		ofsBs := append(ENDOFFSET_, nidbs...)
 		fmap[&ofsBs] = []byte{0}
-		fmap[&nidbs] = wfsNodeBeanToBytes(&stub.WfsNodeBean{Rmsize: new(int64)})
-		fmap[&CURRENT] = []byte(fh.Node)
+		nodeHashes.Store(node, currentHash())
+		if sys.Encrypt {
+			if err = newNodeKey(node); err != nil {
+				return
+			}
+		}
+		fmap[&nidbs] = wfsNodeBeanToBytes(newNodeBean(node, 0))
+		if current {
+			fmap[&CURRENT] = []byte(node)
+		}
 		err = wfsdb.BatchPut(fmap)
 	} else {
 		return errors.New("format error")
@@ -831,115 +1014,358 @@ func usefileHandler(fh *fileHandler) (err error) {
 	return
 }
 
-func (t *fileHandler) append(path string, bs []byte, compressType int32) (nf bool, _r sys.ERROR) {
+func (t *fileHandler) append(path string, bs []byte, compressType int32, it *intent) (nf bool, _r sys.ERROR) {
 	if path != "" && bs != nil && len(bs) > 0 {
-		fidBs := fingerprint([]byte(path))
+		fidBs := pathKey(path)
 		bidBs := fingerprint(bs)
-		nid, _ := strToInt(t.Node)
-		nidbs := goutil.Int64ToBytes(int64(nid))
-		var wfbbs []byte
 
 		lockid := goutil.Hash64(append(APPENDLOCK_, bidBs...))
 		lockLevel2.Lock(int64(lockid))
 		defer lockLevel2.Unlock(int64(lockid))
 
-		if v, err := wfsdb.Get(bidBs); err != nil || v == nil {
-			storeBytes := praseCompress(bs, compressType)
-			if cl := atomic.AddInt64(&t.length, int64(len(storeBytes)+fileoffset())); cl < sys.FileSize {
-
-				//when the ratio(90%) is exceeded, an empty big file will be created to avoid lock contention
-				//that occurs when files are created at high concurrency.
-				if nextfn == nil && float32(cl)/float32(sys.FileSize) > 0.9 {
-					go newNextfn()
-				}
+		var chain int32
+		if bidBs, chain, _r = dedupKey(bidBs, sameBlock(bs)); _r != nil {
+			return
+		}
+		it.wib.Journal.Fingerprint = bidBs
+		var wfbbs []byte
+		if wfbbs, _r = t.storeBlock(path, bidBs, bs, compressType, chain, it); _r != nil {
+			return
+		}
+		bat := newBatch()
+		if nf, _r = bat.bindPath(fidBs, bidBs, wfbbs); _r == nil {
+			bat.put(it.key, it.bound(nf))
+			if err := bat.commit(); err != nil {
+				return nf, sys.ERR_UNDEFINED
+			} else {
+				cachePut(fidBs, bidBs)
+			}
+			fault("bind")
+		}
+	} else {
+		return nf, sys.ERR_PARAMS
+	}
+	return
+}
 
-				size, refer := int64(len(storeBytes)), new(int32)
-				*refer = 1
+// appendBlock stores bs without binding it to path, the caller owns one reference of the block
+func (t *fileHandler) appendBlock(path string, bs []byte, compressType int32) (bidBs []byte, _r sys.ERROR) {
+	bidBs = fingerprint(bs)
+	lockid := goutil.Hash64(append(APPENDLOCK_, bidBs...))
+	lockLevel2.Lock(int64(lockid))
+	defer lockLevel2.Unlock(int64(lockid))
 
-				if r, ok := referMap.Put(string(bidBs), refer); ok {
-					refer = r
-					atomic.AddInt32(refer, 1)
-				}
+	var chain int32
+	if bidBs, chain, _r = dedupKey(bidBs, sameBlock(bs)); _r != nil {
+		return
+	}
+	var wfbbs []byte
+	if wfbbs, _r = t.storeBlock(path, bidBs, bs, compressType, chain, nil); _r == nil && wfbbs != nil {
+		bat := newBatch()
+		bat.refer(bidBs, wfbbs)
+		if err := bat.commit(); err != nil {
+			_r = sys.ERR_UNDEFINED
+		}
+	}
+	return
+}
 
-				wfb := &stub.WfsFileBean{Storenode: &t.Node, Size: &size, CompressType: &compressType, Refercount: refer}
+// storeBlock writes bs to the node file under bidBs, the chain-th key of its fingerprint, if it is not stored yet.
+// It returns the stored bean when the block already exists, otherwise the new block
+// is saved with one reference and wfbbs is nil, it is marked stored in it if it is given.
+// A quarantined block is written again with the references it had, and its new bean is returned.
+func (t *fileHandler) storeBlock(path string, bidBs, bs []byte, compressType, chain int32, it *intent) (wfbbs []byte, _r sys.ERROR) {
+	var old *stub.WfsFileBean
+	if v, err := wfsdb.Get(bidBs); err == nil && v != nil {
+		if old = bytesToWfsFileBean(v); old == nil || !quarantined(bidBs) {
+			return v, nil
+		}
+	}
+	return t.writeBlock(path, bidBs, bs, compressType, chain, old, it)
+}
 
-				fmap := make(map[*[]byte][]byte, 0)
+// writeBlock appends bs to the node file under bidBs. The block replaces the one of old, with the references
+// of old, if it is given.
+func (t *fileHandler) writeBlock(path string, bidBs, bs []byte, compressType, chain int32, old *stub.WfsFileBean, it *intent) (wfbbs []byte, _r sys.ERROR) {
+	nid, _ := strToInt(t.Node)
+	nidbs := goutil.Int64ToBytes(int64(nid))
+	compressType, dict, storeBytes := compressBlock(path, bs, compressType)
+	storeBytes, err := sealBlock(t.Node, bidBs, storeBytes)
+	if err != nil {
+		logger.Error(err)
+		return nil, sys.ERR_UNDEFINED
+	}
+	if cl := atomic.AddInt64(&t.length, int64(len(storeBytes)+fileoffset())); cl < sys.FileSize {
+
+		//when the ratio(90%) is exceeded, an empty big file will be created to avoid lock contention
+		//that occurs when files are created at high concurrency.
+		if nextfn.Load() == nil && float32(cl)/float32(sys.FileSize) > 0.9 {
+			go newNextfn()
+		}
 
-				bs := append(bidBs, goutil.Int32ToBytes(int32(size))...)
-				if !sys.SYNC {
-					if n, err := t.mm.Append(append(bs, storeBytes...)); err == nil {
-						wfb.Offset = &n
-					} else {
-						return nf, sys.ERR_FILEAPPEND
-					}
-				} else {
-					if n, err := t.mm.AppendSync(append(bs, storeBytes...)); err == nil {
-						wfb.Offset = &n
-					} else {
-						return nf, sys.ERR_FILEAPPEND
-					}
-				}
+		size, datasize, refer := int64(len(storeBytes)), int64(len(bs)), new(int32)
+		*refer = 1
 
-				wfbbytes := wfsFileBeanToBytes(wfb)
-				fmap[&bidBs] = wfbbytes
+		if old != nil {
+			referMap.Del(string(bidBs))
+			*refer = old.GetRefercount()
+		} else if r := sharedRefer(bidBs, refer); r != refer {
+			refer = r
+			atomic.AddInt32(refer, 1)
+		}
 
-				ofsBs := append(ENDOFFSET_, nidbs...)
-				fmap[&ofsBs] = goutil.Int64ToBytes(t.length)
+		wfb := &stub.WfsFileBean{Storenode: &t.Node, Size: &size, Datasize: &datasize, CompressType: &compressType, Refercount: refer}
+		if sys.DedupVerify {
+			wfb.Digest = digest(bs)
+		}
+		if chain > 0 {
+			wfb.Chain = &chain
+		}
+		if dict != 0 {
+			wfb.Dict = &dict
+		}
 
-				if err := wfsdb.BatchPut(fmap); err != nil {
-					return nf, sys.ERR_UNDEFINED
-				} else {
-					cachePut(bidBs, wfbbytes)
-				}
+		fmap := make(map[*[]byte][]byte, 0)
 
+		bs := append(bidBs, goutil.Int32ToBytes(int32(size))...)
+		if !sys.SYNC {
+			if n, err := t.mm.Append(append(bs, storeBytes...)); err == nil {
+				wfb.Offset = &n
 			} else {
-				return nf, sys.ERR_FILEAPPEND
+				return nil, sys.ERR_FILEAPPEND
 			}
 		} else {
-			wfbbs = v
-		}
-		batchmap := make(map[*[]byte][]byte, 0)
-		var dels [][]byte
-		if oldBidBs, err := wfsdb.Get(fidBs); err == nil && oldBidBs != nil {
-			if bytes.Equal(oldBidBs, bidBs) {
-				return nf, sys.ERR_EXSIT
+			if n, err := t.mm.AppendSync(append(bs, storeBytes...)); err == nil {
+				wfb.Offset = &n
+			} else {
+				return nil, sys.ERR_FILEAPPEND
 			}
-			if oldWffsBs, err := wfsdb.Get(oldBidBs); err == nil && oldWffsBs != nil {
-				oldwffs := bytesToWfsFileBean(oldWffsBs)
-				*oldwffs.Refercount -= 1
-				if *oldwffs.Refercount <= 0 {
-					if nodebs, err := wfsdb.Get(nidbs); err == nil && nodebs != nil {
-						wnb := bytesToWfsNodeBean(nodebs)
-						*wnb.Rmsize = *wnb.Rmsize + *oldwffs.Size
-						batchmap[&nidbs] = wfsNodeBeanToBytes(wnb)
-						dels = [][]byte{oldBidBs}
-					}
-				} else {
-					batchmap[&oldBidBs] = wfsFileBeanToBytes(oldwffs)
-				}
+		}
+
+		fault("block")
+		wfbbytes := wfsFileBeanToBytes(wfb)
+		fmap[&bidBs] = wfbbytes
+
+		ofsBs := append(ENDOFFSET_, nidbs...)
+		fmap[&ofsBs] = goutil.Int64ToBytes(atomic.LoadInt64(&t.length))
+
+		if old != nil {
+			bat := newBatch()
+			bat.release(old)
+			bat.put(bidBs, wfbbytes)
+			bat.put(ofsBs, fmap[&ofsBs])
+			bat.del(quarantineKey(bidBs))
+			if bat.commit() != nil {
+				return nil, sys.ERR_UNDEFINED
 			}
+			return wfbbytes, nil
+		}
+
+		if it != nil {
+			fmap[&it.key] = it.stored()
+		}
+		if err := wfsdb.BatchPut(fmap); err != nil {
+			return nil, sys.ERR_UNDEFINED
 		} else {
-			nf = true
-			batchmap[&COUNT] = goutil.Int64ToBytes(atomic.AddInt64(&count, 1))
+			cachePut(bidBs, wfbbytes)
 		}
+		fault("store")
+	} else {
+		return nil, sys.ERR_FILEAPPEND
+	}
+	return
+}
 
-		if wfbbs != nil {
-			wfb := bytesToWfsFileBean(wfbbs)
-			if r, ok := referMap.Put(string(bidBs), wfb.Refercount); ok {
-				wfb.Refercount = r
+// batch collects the puts and deletes of one metadata update, so that reference
+// counting of blocks and manifests is committed atomically.
+// commitMux orders the commits of batches with the moves of blocks by compaction. A batch puts a bean it has read
+// at the place it is moved to meanwhile, and compaction moves the beans as they are committed.
+var commitMux = &sync.Mutex{}
+
+type batch struct {
+	puts  map[string][]byte
+	dels  map[string]bool
+	nodes map[string]*stub.WfsNodeBean
+	// seen is the place of the blocks whose beans are read by the batch
+	seen map[string]*stub.WfsFileBean
+}
+
+func newBatch() *batch {
+	return &batch{puts: make(map[string][]byte), dels: make(map[string]bool), nodes: make(map[string]*stub.WfsNodeBean), seen: make(map[string]*stub.WfsFileBean)}
+}
+
+// see records the place of the block of the bean wfb of bidBs when the batch reads it first
+func (t *batch) see(bidBs []byte, wfb *stub.WfsFileBean) {
+	if _, ok := t.seen[string(bidBs)]; !ok && wfb.Storenode != nil {
+		node, offset := wfb.GetStorenode(), wfb.GetOffset()
+		t.seen[string(bidBs)] = &stub.WfsFileBean{Storenode: &node, Offset: &offset}
+	}
+}
+
+// follow puts the beans read by the batch at the place their blocks are moved to since, and releases the moved
+// blocks it removes at their place too. The caller holds commitMux.
+func (t *batch) follow() {
+	for k, wfb := range t.seen {
+		v, put := t.puts[k]
+		if !put && !t.dels[k] {
+			continue
+		}
+		if cv, err := wfsdb.Get([]byte(k)); err == nil && cv != nil {
+			if cur := bytesToWfsFileBean(cv); cur != nil && cur.Storenode != nil && (cur.GetStorenode() != wfb.GetStorenode() || cur.GetOffset() != wfb.GetOffset()) {
+				if !put {
+					t.release(cur)
+				} else if nwfb := bytesToWfsFileBean(v); nwfb != nil {
+					nwfb.Storenode, nwfb.Offset, nwfb.Size = cur.Storenode, cur.Offset, cur.Size
+					t.puts[k] = wfsFileBeanToBytes(nwfb)
+				}
 			}
-			atomic.AddInt32(wfb.Refercount, 1)
-			batchmap[&bidBs] = wfsFileBeanToBytes(wfb)
 		}
+	}
+}
 
-		batchmap[&fidBs] = bidBs
-		if err := wfsdb.Batch(batchmap, dels); err != nil {
-			return nf, sys.ERR_UNDEFINED
-		} else {
-			cachePut(fidBs, bidBs)
+func (t *batch) put(key, value []byte) {
+	delete(t.dels, string(key))
+	t.puts[string(key)] = value
+}
+
+func (t *batch) del(key []byte) {
+	delete(t.puts, string(key))
+	t.dels[string(key)] = true
+}
+
+func (t *batch) get(key []byte) (v []byte) {
+	if t.dels[string(key)] {
+		return nil
+	}
+	if v, ok := t.puts[string(key)]; ok {
+		return v
+	}
+	if v, err := wfsdb.Get(key); err == nil && len(v) > 0 {
+		return v
+	}
+	return nil
+}
+
+// bindPath maps fidBs to bidBs, releasing the block that fidBs referred to before.
+// wfbbs is the stored bean of bidBs whose reference count must be increased, or nil
+// if the reference is already owned by the caller.
+func (t *batch) bindPath(fidBs, bidBs, wfbbs []byte) (nf bool, _r sys.ERROR) {
+	if oldBidBs := t.get(fidBs); oldBidBs != nil {
+		if bytes.Equal(oldBidBs, bidBs) {
+			return nf, sys.ERR_EXSIT
 		}
+		t.unrefer(oldBidBs)
 	} else {
-		return nf, sys.ERR_PARAMS
+		nf = true
+		t.put(COUNT, goutil.Int64ToBytes(atomic.AddInt64(&count, 1)))
+	}
+	if wfbbs != nil {
+		t.refer(bidBs, wfbbs)
+	}
+	t.put(fidBs, bidBs)
+	return
+}
+
+func (t *batch) refer(bidBs, wfbbs []byte) {
+	wfb := bytesToWfsFileBean(wfbbs)
+	t.see(bidBs, wfb)
+	wfb.Refercount = sharedRefer(bidBs, wfb.Refercount)
+	atomic.AddInt32(wfb.Refercount, 1)
+	t.put(bidBs, wfsFileBeanToBytes(wfb))
+}
+
+// sharedRefer returns the reference count of bidBs that the operations in progress share, refer becomes it if there is none.
+// Put of referMap replaces the count, so a shared count is not put again.
+func sharedRefer(bidBs []byte, refer *int32) *int32 {
+	if r, ok := referMap.Get(string(bidBs)); ok {
+		return r
+	}
+	referMap.Put(string(bidBs), refer)
+	return refer
+}
+
+// unrefer decreases the reference count of bidBs. A block without references is removed
+// and its size is counted as removed space of its node, a manifest releases its parts.
+func (t *batch) unrefer(bidBs []byte) {
+	v := t.get(bidBs)
+	if v == nil {
+		return
+	}
+	wfb := bytesToWfsFileBean(v)
+	if wfb == nil || wfb.Refercount == nil {
+		return
+	}
+	t.see(bidBs, wfb)
+	referMap.Del(string(bidBs))
+	refer := wfb.GetRefercount() - 1
+	wfb.Refercount = &refer
+	if refer > 0 {
+		t.put(bidBs, wfsFileBeanToBytes(wfb))
+		return
+	}
+	t.del(bidBs)
+	if atomic.LoadInt32(&quarantineOn) == 1 {
+		t.del(quarantineKey(bidBs))
+	}
+	if len(wfb.Parts) > 0 {
+		for _, p := range wfb.Parts {
+			t.unrefer(p.Fingerprint)
+		}
+	} else {
+		t.release(wfb)
+	}
+}
+
+// release accounts the space of the block of wfb with its header to its node, so that defragmentation reclaims it
+func (t *batch) release(wfb *stub.WfsFileBean) {
+	if wfb.Storenode != nil {
+		node := wfb.GetStorenode()
+		wnb, ok := t.nodes[node]
+		if !ok {
+			nid, _ := strToInt(node)
+			if nodebs, err := wfsdb.Get(goutil.Int64ToBytes(int64(nid))); err == nil && nodebs != nil {
+				wnb = bytesToWfsNodeBean(nodebs)
+			}
+			t.nodes[node] = wnb
+		}
+		if wnb != nil {
+			rmsize := wnb.GetRmsize() + nodeOffset(node) + wfb.GetSize()
+			wnb.Rmsize = &rmsize
+		}
+	}
+}
+
+func (t *batch) commit() (err error) {
+	commitMux.Lock()
+	defer commitMux.Unlock()
+	t.follow()
+	// the batches are committed in another order than they count their paths, the last one puts the latest count
+	if _, ok := t.puts[string(COUNT)]; ok {
+		t.puts[string(COUNT)] = goutil.Int64ToBytes(atomic.LoadInt64(&count))
+	}
+	am := make(map[*[]byte][]byte, len(t.puts)+len(t.nodes))
+	for k, v := range t.puts {
+		key := []byte(k)
+		am[&key] = v
+	}
+	for node, wnb := range t.nodes {
+		if wnb != nil {
+			nid, _ := strToInt(node)
+			nidbs := goutil.Int64ToBytes(int64(nid))
+			am[&nidbs] = wfsNodeBeanToBytes(wnb)
+		}
+	}
+	dm := make([][]byte, 0, len(t.dels))
+	for k := range t.dels {
+		dm = append(dm, []byte(k))
+	}
+	if err = wfsdb.Batch(am, dm); err == nil {
+		for k := range t.puts {
+			cacheDel([]byte(k))
+		}
+		for _, k := range dm {
+			cacheDel(k)
+		}
 	}
 	return
 }
@@ -964,26 +1390,11 @@ func exportByIncr(start, limit int64, streamfunc func(snaps *stub.SnapshotBeans)
 				snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: seqbs, Value: wpbbs})
 				wpbtb := bytesToWfsPathBean(wpbbs)
 				path := *wpbtb.Path
-				fidbs := fingerprint([]byte(path))
+				fidbs := pathKey(path)
 				if bidBs, err := wfsdb.Get(fidbs); err == nil {
 					snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: fidbs, Value: bidBs})
-					if wfbbs, err := wfsdb.Get(bidBs); err == nil {
-						snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: bidBs, Value: wfbbs})
-						wfb := bytesToWfsFileBean(wfbbs)
-						node := *wfb.Storenode
-						if _, ok := nodemap[node]; !ok {
-							nodemap[node] = ""
-							nid, _ := strToInt(node)
-							nidbs := goutil.Int64ToBytes(int64(nid))
-							if wnbbs, err := wfsdb.Get(nidbs); err == nil {
-								snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: nidbs, Value: wnbbs})
-							}
-							ofsBs := append(ENDOFFSET_, nidbs...)
-							if v, err := wfsdb.Get(ofsBs); err == nil {
-								snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: ofsBs, Value: v})
-							}
-						}
-					}
+					snaps.Beans = append(snaps.Beans, snapshotFileBean(bidBs, nodemap)...)
+					snaps.Beans = append(snaps.Beans, snapshotExpire(path)...)
 					streamfunc(snaps)
 				}
 			}
@@ -999,27 +1410,12 @@ func exportByPaths(paths []string, streamfunc func(snaps *stub.SnapshotBeans) bo
 	if len(paths) > 0 {
 		nodemap := make(map[string]string)
 		for _, path := range paths {
-			fidbs := fingerprint([]byte(path))
+			fidbs := pathKey(path)
 			if bidBs, err := wfsdb.Get(fidbs); err == nil {
 				snaps := &stub.SnapshotBeans{Id: new(int64)}
 				snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: fidbs, Value: bidBs})
-				if wfbbs, err := wfsdb.Get(bidBs); err == nil {
-					snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: bidBs, Value: wfbbs})
-					wfb := bytesToWfsFileBean(wfbbs)
-					node := *wfb.Storenode
-					if _, ok := nodemap[node]; !ok {
-						nodemap[node] = ""
-						nid, _ := strToInt(node)
-						nidbs := goutil.Int64ToBytes(int64(nid))
-						if wnbbs, err := wfsdb.Get(nidbs); err == nil {
-							snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: nidbs, Value: wnbbs})
-						}
-						ofsBs := append(ENDOFFSET_, nidbs...)
-						if v, err := wfsdb.Get(ofsBs); err == nil {
-							snaps.Beans = append(snaps.Beans, &stub.SnapshotBean{Key: ofsBs, Value: v})
-						}
-					}
-				}
+				snaps.Beans = append(snaps.Beans, snapshotFileBean(bidBs, nodemap)...)
+				snaps.Beans = append(snaps.Beans, snapshotExpire(path)...)
 				streamfunc(snaps)
 			}
 		}
@@ -1029,6 +1425,34 @@ func exportByPaths(paths []string, streamfunc func(snaps *stub.SnapshotBeans) bo
 	return
 }
 
+// snapshotFileBean returns the bean of bidBs with the parts of a manifest and the nodes that have not been exported yet
+func snapshotFileBean(bidBs []byte, nodemap map[string]string) (_r []*stub.SnapshotBean) {
+	if wfbbs, err := wfsdb.Get(bidBs); err == nil && wfbbs != nil {
+		_r = append(_r, &stub.SnapshotBean{Key: bidBs, Value: wfbbs})
+		wfb := bytesToWfsFileBean(wfbbs)
+		if len(wfb.Parts) > 0 {
+			for _, p := range wfb.Parts {
+				_r = append(_r, snapshotFileBean(p.Fingerprint, nodemap)...)
+			}
+			return
+		}
+		node := *wfb.Storenode
+		if _, ok := nodemap[node]; !ok {
+			nodemap[node] = ""
+			nid, _ := strToInt(node)
+			nidbs := goutil.Int64ToBytes(int64(nid))
+			if wnbbs, err := wfsdb.Get(nidbs); err == nil {
+				_r = append(_r, &stub.SnapshotBean{Key: nidbs, Value: wnbbs})
+			}
+			ofsBs := append(ENDOFFSET_, nidbs...)
+			if v, err := wfsdb.Get(ofsBs); err == nil {
+				_r = append(_r, &stub.SnapshotBean{Key: ofsBs, Value: v})
+			}
+		}
+	}
+	return
+}
+
 func exportFile(start, limit int64, streamfunc func(snaps *stub.SnapshotFile) bool) (err sys.ERROR) {
 	if start > 0 && limit > 0 && start <= seq {
 		count := int64(0)
@@ -1036,9 +1460,9 @@ func exportFile(start, limit int64, streamfunc func(snaps *stub.SnapshotFile) bo
 			pathseqkey := append(PATH_SEQ, goutil.Int64ToBytes(i)...)
 			if wpbbs, err := wfsdb.Get(pathseqkey); err == nil {
 				wpb := bytesToWfsPathBean(wpbbs)
-				if bs := fe.getData(*wpb.Path); bs != nil {
+				if bs := fileData(*wpb.Path); bs != nil {
 					count++
-					fidbs := fingerprint([]byte(*wpb.Path))
+					fidbs := pathKey(*wpb.Path)
 					compressType := new(int32)
 					if bidBs, err := wfsdb.Get(fidbs); err == nil {
 						if wfbtb, err := wfsdb.Get(bidBs); err == nil {
@@ -1047,7 +1471,10 @@ func exportFile(start, limit int64, streamfunc func(snaps *stub.SnapshotFile) bo
 							}
 						}
 					}
-					sf := &stub.SnapshotFile{Id: &i, Path: wpb.Path, Data: bs, CompressType: compressType}
+					sf := &stub.SnapshotFile{Id: &i, Path: wpb.Path, Data: bs, CompressType: compressType, ContentType: wpb.ContentType, Meta: wpb.Meta}
+					if e := getExpire(*wpb.Path); e > 0 {
+						sf.Expire = &e
+					}
 					streamfunc(sf)
 				}
 			}
@@ -1058,6 +1485,18 @@ func exportFile(start, limit int64, streamfunc func(snaps *stub.SnapshotFile) bo
 	return
 }
 
+// fileData reads the whole data of path for exportFile, a snapshot file carries it at once even if it is a
+// multipart file larger than data.maxsize
+func fileData(path string) (_r []byte) {
+	if db := fe.getReader(path); db != nil {
+		if bs, err := io.ReadAll(db.Reader); err == nil {
+			_r = bs
+		}
+		db.Close()
+	}
+	return
+}
+
 func importData(bean *stub.SnapshotBean, cover bool) (err error) {
 	defer util.Recover()
 	if bean == nil {
@@ -1066,6 +1505,12 @@ func importData(bean *stub.SnapshotBean, cover bool) (err error) {
 	if bytes.Equal(bean.Key, CURRENT) || bytes.Equal(bean.Key, COUNT) || bytes.Equal(bean.Key, SEQ) {
 		return
 	}
+	if bytes.Equal(bean.Key, HASH) {
+		return mergeHashes(bean.Value)
+	}
+	if len(bean.Key) == len(DICT_)+8 && bytes.HasPrefix(bean.Key, DICT_) {
+		return importDict(bean.Value)
+	}
 
 	if len(bean.Key) == 17 && bytes.Equal(bean.Key[:9], PATH_SEQ) {
 		wppb := bytesToWfsPathBean(bean.Value)
@@ -1082,8 +1527,10 @@ func importData(bean *stub.SnapshotBean, cover bool) (err error) {
 		} else {
 			am[&COUNT] = goutil.Int64ToBytes(atomic.AddInt64(&count, 1))
 		}
+		seqMux.Lock()
+		defer seqMux.Unlock()
 		id := atomic.AddInt64(&seq, 1)
-		am[&SEQ] = goutil.Int64ToBytes(seq)
+		am[&SEQ] = goutil.Int64ToBytes(id)
 		am[&pathpre] = goutil.Int64ToBytes(id)
 		pathseqkey := append(PATH_SEQ, goutil.Int64ToBytes(id)...)
 		am[&pathseqkey] = bean.Value
@@ -1103,7 +1550,11 @@ func importData(bean *stub.SnapshotBean, cover bool) (err error) {
 			return
 		}
 	}
-	if len(bean.Key) == fingerprintLen() {
+	if isFingerprint(bean.Key) {
+		cacheDel(bean.Key)
+	}
+	if bytes.HasPrefix(bean.Key, TTL_) {
+		atomic.StoreInt32(&expireOn, 1)
 		cacheDel(bean.Key)
 	}
 	err = wfsdb.LoadSnapshotBean(bean)
@@ -1113,7 +1564,12 @@ func importData(bean *stub.SnapshotBean, cover bool) (err error) {
 func importFile(snapsBean *stub.SnapshotFile) (err sys.ERROR) {
 	defer util.Recover()
 	if snapsBean.Path != nil && *snapsBean.Path != "" && len(snapsBean.Data) > 0 {
-		_, err = fe.append(snapsBean.GetPath(), snapsBean.GetData(), snapsBean.GetCompressType())
+		mb := &sys.MetaBean{ContentType: snapsBean.GetContentType(), Meta: snapsBean.GetMeta(), Expire: snapsBean.GetExpire()}
+		if int64(len(snapsBean.Data)) > sys.DataMaxsize {
+			_, err = fe.appendParts(snapsBean.GetPath(), snapsBean.GetData(), snapsBean.GetCompressType(), mb)
+		} else {
+			_, err = fe.append(snapsBean.GetPath(), snapsBean.GetData(), snapsBean.GetCompressType(), mb)
+		}
 	}
 	return
 }
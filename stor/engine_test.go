@@ -0,0 +1,139 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// listed writes the entries, the common prefixes and the next marker of a listing
+func listed(lr *sys.ListResult) string {
+	s := ""
+	for _, e := range lr.Entries {
+		s += e.Path + " "
+	}
+	for _, p := range lr.Prefixes {
+		s += p + " "
+	}
+	return s + "next:" + lr.Next
+}
+
+func TestList(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	for _, path := range []string{"a/1", "a/2", "a/3", "a/b/1", "a/b/2", "a/c/1", "b/1", "a/4"} {
+		if _, err := fe.append(path, []byte("the data of "+path), 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fe.delData("a/4")
+	// the pages of a listing go on from the last entry or common prefix of the page before
+	for _, c := range [][2]string{
+		{listed(fe.list("a/", "", "/", 2)), "a/1 a/2 next:a/2"},
+		{listed(fe.list("a/", "a/2", "/", 2)), "a/3 a/b/ next:a/b/"},
+		{listed(fe.list("a/", "a/b/", "/", 2)), "a/c/ next:"},
+		{listed(fe.list("a/", "a/b/1", "/", 2)), "a/c/ next:"},
+		{listed(fe.list("a/", "", "", 10)), "a/1 a/2 a/3 a/b/1 a/b/2 a/c/1 next:"},
+		{listed(fe.list("", "", "/", 10)), "a/ b/ next:"},
+		{listed(fe.list("c/", "", "/", 10)), "next:"},
+		{listed(fe.list("a/", "", "/", 0)), "next:"},
+	} {
+		if c[0] != c[1] {
+			t.Fatalf("listed %q, want %q", c[0], c[1])
+		}
+	}
+	if lb := fe.list("a/b/", "", "", 1).Entries[0]; lb.Size != int64(len("the data of a/b/1")) || len(lb.Fingerprint) == 0 || lb.Timestramp == 0 {
+		t.Fatal("entry of the listing:", lb)
+	}
+}
+
+func TestStat(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	data := bytes.Repeat([]byte("the data of s/a "), 256)
+	expire := time.Now().Add(time.Hour).UnixNano()
+	fe.append("s/a", data, 2, &sys.MetaBean{ContentType: "text/plain", Meta: map[string]string{"k": "v"}, Expire: expire})
+	fe.append("s/b", data, 2, nil)
+	sb := fe.stat("s/a")
+	if sb == nil || sb.Size != int64(len(data)) || sb.StoredSize <= 0 || sb.StoredSize >= sb.Size || sb.CompressType != 2 || sb.Refercount != 2 {
+		t.Fatal("stat of the sizes:", sb)
+	}
+	if sb.Node == "" || sb.Timestramp == 0 || len(sb.Fingerprint) == 0 || sb.ContentType != "text/plain" || sb.Meta["k"] != "v" || sb.Expire != expire {
+		t.Fatal("stat of the metadata:", sb)
+	}
+	// the stat is read from the metadata, a block that cannot be read is still stated
+	corruptBlock(t, "s/a")
+	if fe.getData("s/a") != nil {
+		t.Fatal("the corrupt block is read")
+	}
+	if sb2 := fe.stat("s/b"); sb2 == nil || sb2.Size != sb.Size || sb2.Offset != sb.Offset || sb2.Expire != 0 {
+		t.Fatal("stat of the corrupt block:", sb2)
+	}
+	if fe.stat("s/none") != nil {
+		t.Fatal("stat of a missing path")
+	}
+}
+
+func TestMeta(t *testing.T) {
+	startStore(t.TempDir())
+	mb := &sys.MetaBean{ContentType: "application/x-test", Meta: map[string]string{"owner": "wfs", "k": "v"}}
+	fe.append("m/a", []byte("the data of m/a"), 0, mb)
+	id, _ := fe.uploadInit("m/parts", 0, mb)
+	fe.uploadPart(id, 1, []byte("the part of m/parts"))
+	if _, err := fe.uploadComplete(id); err != nil {
+		t.Fatal(err)
+	}
+	fe.append("m/none", []byte("the data of m/none"), 0, nil)
+	if err := fe.modify("m/a", "m/b"); err != nil {
+		t.Fatal(err)
+	}
+	same := func(path string, want *sys.MetaBean) {
+		t.Helper()
+		db := fe.getReader(path)
+		if db == nil {
+			t.Fatal("no reader of", path)
+		}
+		db.Close()
+		sb := fe.stat(path)
+		if db.ContentType != want.ContentType || sb.ContentType != want.ContentType || len(db.Meta) != len(want.Meta) || len(sb.Meta) != len(want.Meta) {
+			t.Fatal("metadata of", path, ":", db.ContentType, db.Meta, sb.ContentType, sb.Meta)
+		}
+		for k, v := range want.Meta {
+			if db.Meta[k] != v || sb.Meta[k] != v {
+				t.Fatal("metadata of", path, ":", db.Meta, sb.Meta)
+			}
+		}
+	}
+	same("m/b", mb)
+	same("m/parts", mb)
+	same("m/none", &sys.MetaBean{})
+	// the metadata is kept by export and import
+	files := make([]*stub.SnapshotFile, 0)
+	if err := exportFile(1, 10, func(sf *stub.SnapshotFile) bool {
+		files = append(files, sf)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	CloseAll()
+	startStore(t.TempDir())
+	defer CloseAll()
+	for _, sf := range files {
+		if err := importFile(sf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	same("m/b", mb)
+	same("m/parts", mb)
+	same("m/none", &sys.MetaBean{})
+}
@@ -0,0 +1,609 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+	"github.com/klauspost/reedsolomon"
+)
+
+// A sealed node is erasure coded into sys.ErasureData data shards and sys.ErasureParity parity shards, each kept
+// as <node>.<index> in another data directory. The data shards hold the node up to its end offset in order, the
+// parity shards are computed chunk by chunk. A shard file is its chunks followed by the crc32 of every chunk and a
+// footer that describes the coding, rebuild finds the shards by it. A chunk that is missing or fails its crc is
+// reconstructed from the same chunk of the other shards.
+const (
+	shardChunk  = 64 << 10
+	shardMagic  = "WFSSHARD"
+	shardFooter = len(shardMagic) + 20
+)
+
+type shardSet struct {
+	node  string
+	data  int32
+	size  int64
+	unit  int64
+	dirs  []string
+	enc   reedsolomon.Encoder
+	mux   *sync.Mutex
+	files []*os.File
+	bad   []bool
+}
+
+// shardSets caches the shards of every node, nil for a node that is not erasure coded
+var shardSets = &sync.Map{}
+
+var codingRun, codingAgain int32
+var repairRun int32
+var repairMux = &sync.Mutex{}
+var repairStat = &sys.ShardsBean{}
+
+func init() {
+	sys.ShardStatus = shardStatus
+	sys.RepairShards = repairShards
+}
+
+func erasureOn() bool {
+	return sys.ErasureData > 0 && len(sys.DataDirs) >= sys.ErasureData+sys.ErasureParity
+}
+
+func initErasure() {
+	if sys.ErasureData > 0 && !erasureOn() {
+		logger.Warn("erasure coding needs ", sys.ErasureData+sys.ErasureParity, " data directories, the nodes are not coded")
+	}
+	codeNodes()
+}
+
+func shardName(node string, i int) string {
+	return fmt.Sprint(node, ".", i)
+}
+
+// shardUnit is the length of the chunks of every shard of a node of size bytes
+func shardUnit(size int64, data int32) int64 {
+	n := (size + int64(data) - 1) / int64(data)
+	return max(1, (n+shardChunk-1)/shardChunk) * shardChunk
+}
+
+func newShardSet(node string, data int32, size int64, dirs []string) (s *shardSet, err error) {
+	if data <= 0 || int(data) >= len(dirs) {
+		return nil, errors.New("invalid erasure coding of node " + node)
+	}
+	s = &shardSet{node: node, data: data, size: size, unit: shardUnit(size, data), dirs: dirs, mux: &sync.Mutex{}, files: make([]*os.File, len(dirs)), bad: make([]bool, len(dirs))}
+	if s.enc, err = reedsolomon.New(int(data), len(dirs)-int(data)); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// nodeShards returns the shards of node, nil if node is not erasure coded
+func nodeShards(node string) *shardSet {
+	if v, ok := shardSets.Load(node); ok {
+		return v.(*shardSet)
+	}
+	var s *shardSet
+	if v, err := wfsdb.Get(nodeBytes(node)); err == nil && v != nil {
+		if wnb := bytesToWfsNodeBean(v); wnb != nil && wnb.GetDatashards() > 0 {
+			if s, err = newShardSet(node, wnb.GetDatashards(), wnb.GetCodedsize(), wnb.GetSharddirs()); err != nil {
+				logger.Error(err)
+			}
+		}
+	}
+	shardSets.Store(node, s)
+	return s
+}
+
+// keep records the coding of the shards in wnb
+func (t *shardSet) keep(wnb *stub.WfsNodeBean) *stub.WfsNodeBean {
+	if t != nil {
+		t.mux.Lock()
+		defer t.mux.Unlock()
+		wnb.Datashards, wnb.Codedsize, wnb.Sharddirs = &t.data, &t.size, slices.Clone(t.dirs)
+	}
+	return wnb
+}
+
+func (t *shardSet) file(i int) *os.File {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.files[i] == nil && !t.bad[i] {
+		if f, err := os.Open(t.dirs[i] + "/" + shardName(t.node, i)); err == nil {
+			t.files[i] = f
+		} else {
+			t.bad[i] = true
+		}
+	}
+	return t.files[i]
+}
+
+// chunk reads chunk c of shard i, nil if the shard is missing or the chunk fails its crc
+func (t *shardSet) chunk(i int, c int64) []byte {
+	if f := t.file(i); f != nil {
+		bs, sum := make([]byte, shardChunk), make([]byte, 4)
+		if _, err := f.ReadAt(bs, c*shardChunk); err == nil {
+			if _, err = f.ReadAt(sum, t.unit+c*4); err == nil && goutil.BytesToInt32(sum) == int32(crc32.ChecksumIEEE(bs)) {
+				return bs
+			}
+		}
+		t.mux.Lock()
+		t.bad[i] = true
+		t.mux.Unlock()
+	}
+	return nil
+}
+
+// stripe returns chunk c of every shard, the missing and damaged ones reconstructed from the others
+func (t *shardSet) stripe(c int64) ([][]byte, error) {
+	shards := make([][]byte, len(t.dirs))
+	for i := range shards {
+		shards[i] = t.chunk(i, c)
+	}
+	return shards, t.enc.Reconstruct(shards)
+}
+
+// read returns size bytes of the node at offset
+func (t *shardSet) read(offset, size int64) (bs []byte, ok bool) {
+	if offset < 0 || size < 0 || offset+size > t.size {
+		return
+	}
+	bs = make([]byte, size)
+	for n := int64(0); n < size; {
+		pos := offset + n
+		i, c := int(pos/t.unit), pos%t.unit/shardChunk
+		chunk := t.chunk(i, c)
+		if chunk == nil {
+			shards, err := t.stripe(c)
+			if err != nil {
+				return nil, false
+			}
+			chunk = shards[i]
+		}
+		n += int64(copy(bs[n:], chunk[pos%t.unit-c*shardChunk:]))
+	}
+	return bs, true
+}
+
+func (t *shardSet) ReadAt(p []byte, off int64) (int, error) {
+	if bs, ok := t.read(off, int64(len(p))); ok {
+		return copy(p, bs), nil
+	}
+	return 0, errors.New("the shards of node " + t.node + " cannot be read")
+}
+
+func (t *shardSet) Close() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for i, f := range t.files {
+		if f != nil {
+			f.Close()
+			t.files[i] = nil
+		}
+	}
+	return nil
+}
+
+// remove deletes the shard files
+func (t *shardSet) remove() {
+	t.Close()
+	for i, dir := range t.dirs {
+		os.Remove(dir + "/" + shardName(t.node, i))
+	}
+}
+
+// damaged returns the shards that are missing or have a chunk failing its crc
+func (t *shardSet) damaged() (_r []int) {
+	t.mux.Lock()
+	for i, f := range t.files {
+		if f == nil {
+			t.bad[i] = false
+		}
+	}
+	t.mux.Unlock()
+	for i := range t.files {
+		for c := int64(0); c < t.unit/shardChunk; c++ {
+			if t.chunk(i, c) == nil {
+				_r = append(_r, i)
+				break
+			}
+		}
+	}
+	return
+}
+
+func (t *shardSet) status() *sys.ShardNodeItem {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	si := &sys.ShardNodeItem{Node: t.node, Data: int(t.data), Parity: len(t.dirs) - int(t.data), Size: t.size, Dirs: slices.Clone(t.dirs)}
+	for i, dir := range t.dirs {
+		if !goutil.IsFileExist(dir + "/" + shardName(t.node, i)) {
+			si.Missing++
+		} else if t.bad[i] {
+			si.Damaged++
+		}
+	}
+	return si
+}
+
+// writeShards writes the shards of idx to dirs from the chunks given by next, every shard to a temporary
+// file that is renamed once all of them are complete
+func (t *shardSet) writeShards(idx []int, dirs []string, next func(c int64) ([][]byte, error)) (err error) {
+	files, sums := make([]*os.File, len(idx)), make([][]byte, len(idx))
+	defer func() {
+		for _, f := range files {
+			if f != nil {
+				f.Close()
+				if err != nil {
+					os.Remove(f.Name())
+				}
+			}
+		}
+	}()
+	for k, i := range idx {
+		if files[k], err = os.OpenFile(dirs[k]+"/"+shardName(t.node, i)+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666); err != nil {
+			return
+		}
+	}
+	for c := int64(0); c < t.unit/shardChunk; c++ {
+		shards, e := next(c)
+		if e != nil {
+			return e
+		}
+		for k, i := range idx {
+			if _, err = files[k].Write(shards[i]); err != nil {
+				return
+			}
+			sums[k] = append(sums[k], goutil.Int32ToBytes(int32(crc32.ChecksumIEEE(shards[i])))...)
+		}
+	}
+	for k, i := range idx {
+		footer := append([]byte(shardMagic), goutil.Int32ToBytes(int32(i))...)
+		footer = append(footer, goutil.Int32ToBytes(t.data)...)
+		footer = append(footer, goutil.Int32ToBytes(int32(len(t.dirs))-t.data)...)
+		footer = append(footer, goutil.Int64ToBytes(t.size)...)
+		if _, err = files[k].Write(append(sums[k], footer...)); err != nil {
+			return
+		}
+		if err = files[k].Sync(); err != nil {
+			return
+		}
+	}
+	for k, i := range idx {
+		if err = os.Rename(files[k].Name(), dirs[k]+"/"+shardName(t.node, i)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// readShardFooter returns the index of the shard file at path and the coding of its node
+func readShardFooter(path string) (index, data, parity int32, size int64, ok bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || fi.Size() < int64(shardFooter) {
+		return
+	}
+	bs := make([]byte, shardFooter)
+	if _, err = f.ReadAt(bs, fi.Size()-int64(shardFooter)); err != nil || string(bs[:len(shardMagic)]) != shardMagic {
+		return
+	}
+	bs = bs[len(shardMagic):]
+	index, data, parity, size = goutil.BytesToInt32(bs[:4]), goutil.BytesToInt32(bs[4:8]), goutil.BytesToInt32(bs[8:12]), goutil.BytesToInt64(bs[12:])
+	if data > 0 && parity > 0 && index >= 0 && index < data+parity && size >= 0 {
+		unit := shardUnit(size, data)
+		ok = fi.Size() == unit+unit/shardChunk*4+int64(shardFooter)
+	}
+	return
+}
+
+// shardDirs chooses n writable data directories apart from exclude, the ones with the most free space by weight first
+func shardDirs(n int, exclude []string) (_r []string) {
+	type dirScore struct {
+		path  string
+		score float64
+	}
+	var ds []dirScore
+	for _, d := range sys.DataDirs {
+		if !slices.Contains(exclude, d.Path) {
+			if free, ok := checkDir(d.Path); ok {
+				ds = append(ds, dirScore{d.Path, float64(free) * float64(d.Weight)})
+			}
+		}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i].score > ds[j].score })
+	for i := 0; i < n && i < len(ds); i++ {
+		_r = append(_r, ds[i].path)
+	}
+	return
+}
+
+// codeNodes erasure codes the sealed nodes in the background, a node sealed while it runs is coded by another pass
+func codeNodes() {
+	if !erasureOn() {
+		return
+	}
+	atomic.StoreInt32(&codingAgain, 1)
+	if !atomic.CompareAndSwapInt32(&codingRun, 0, 1) {
+		return
+	}
+	goTask(func() {
+		defer util.Recover()
+		defer atomic.StoreInt32(&codingRun, 0)
+		for atomic.SwapInt32(&codingAgain, 0) == 1 && !stopstat {
+			for _, node := range storedNodes(true) {
+				if stopstat {
+					return
+				}
+				if nodeShards(node) != nil {
+					// the file is left if the service stopped after the shards were recorded
+					if path := getpathBynode(node); goutil.IsFileExist(path) {
+						dataEg.unMmap(node)
+						os.Remove(path)
+					}
+				} else if err := codeNode(node); err != nil {
+					logger.Error("erasure code node ", node, " failed:", err)
+				}
+			}
+		}
+	})
+}
+
+// codeNode erasure codes the file of a sealed node and removes it, the node is read from its file until the
+// shards are recorded
+func codeNode(node string) (err error) {
+	relocating.Store(node, byte(0))
+	defer relocating.Delete(node)
+	if _, ok := defragmap.Load(node); ok || defragging() {
+		return errors.New("the node is being defragmented")
+	}
+	if currentNode(node) {
+		return
+	}
+	nidbs := nodeBytes(node)
+	endBs, err := wfsdb.Get(append(ENDOFFSET_, nidbs...))
+	if err != nil || endBs == nil {
+		return errors.New("the node has no end offset")
+	}
+	total := sys.ErasureData + sys.ErasureParity
+	dirs := shardDirs(total, nil)
+	if len(dirs) < total {
+		return errors.New("not enough writable data directories for the shards")
+	}
+	s, err := newShardSet(node, int32(sys.ErasureData), goutil.BytesToInt64(endBs), dirs)
+	if err != nil {
+		return
+	}
+	src := getpathBynode(node)
+	f, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	idx, shards := make([]int, total), make([][]byte, total)
+	for i := range shards {
+		idx[i], shards[i] = i, make([]byte, shardChunk)
+	}
+	if err = s.writeShards(idx, dirs, func(c int64) ([][]byte, error) {
+		for i := 0; i < sys.ErasureData; i++ {
+			clear(shards[i])
+			offset := int64(i)*s.unit + c*shardChunk
+			if n := min(shardChunk, s.size-offset); n > 0 {
+				if _, err := f.ReadAt(shards[i][:n], offset); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return shards, s.enc.Encode(shards)
+	}); err != nil {
+		return
+	}
+	var wnb *stub.WfsNodeBean
+	if v, e := wfsdb.Get(nidbs); e == nil && v != nil {
+		wnb = bytesToWfsNodeBean(v)
+	}
+	if wnb == nil {
+		wnb = newNodeBean(node, 0)
+	}
+	wnb.Dir = nil
+	if err = wfsdb.Put(nidbs, wfsNodeBeanToBytes(s.keep(wnb))); err != nil {
+		s.remove()
+		return
+	}
+	shardSets.Store(node, s)
+	dataEg.unMmap(node)
+	nodeDirs.Delete(node)
+	if err := os.Remove(src); err != nil {
+		logger.Error(err)
+	}
+	return nil
+}
+
+// joinShards writes the node of the shards back to a single file, an erasure coded node is defragmented from it
+func joinShards(node string) (err error) {
+	s := nodeShards(node)
+	if s == nil {
+		return
+	}
+	dir, err := placeNode()
+	if err != nil {
+		return
+	}
+	tmp := dir + "/" + node + ".move"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return
+	}
+	for offset := int64(0); offset < s.size && err == nil; offset += shardChunk {
+		if bs, ok := s.read(offset, min(shardChunk, s.size-offset)); ok {
+			_, err = f.Write(bs)
+		} else {
+			err = errors.New("the shards of node " + node + " cannot be read")
+		}
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err == nil {
+		err = os.Rename(tmp, dir+"/"+node)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return
+	}
+	nidbs := nodeBytes(node)
+	wnb := &stub.WfsNodeBean{}
+	if v, e := wfsdb.Get(nidbs); e == nil && v != nil {
+		if b := bytesToWfsNodeBean(v); b != nil {
+			wnb = b
+		}
+	}
+	nodeDirs.Store(node, dir)
+	wnb.Datashards, wnb.Codedsize, wnb.Sharddirs, wnb.Dir = nil, nil, nil, beanDir(node)
+	if err = wfsdb.Put(nidbs, wfsNodeBeanToBytes(wnb)); err != nil {
+		nodeDirs.Delete(node)
+		os.Remove(dir + "/" + node)
+		return
+	}
+	shardSets.Store(node, (*shardSet)(nil))
+	s.remove()
+	return
+}
+
+func repairShards() sys.ERROR {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if !atomic.CompareAndSwapInt32(&repairRun, 0, 1) {
+		return sys.ERR_REPAIR_UNDERWAY
+	}
+	repairMux.Lock()
+	repairStat = &sys.ShardsBean{Running: true, StartTime: time.Now().UnixNano()}
+	repairMux.Unlock()
+	goTask(repairNodes)
+	return nil
+}
+
+// repairNodes regenerates the missing and damaged shards of the erasure coded nodes
+func repairNodes() {
+	defer util.Recover()
+	defer func() {
+		repairMux.Lock()
+		repairStat.Running, repairStat.EndTime = false, time.Now().UnixNano()
+		repairMux.Unlock()
+		atomic.StoreInt32(&repairRun, 0)
+	}()
+	for _, node := range storedNodes(true) {
+		if stopstat {
+			return
+		}
+		if s := nodeShards(node); s != nil {
+			n, err := repairNode(node, s)
+			if err != nil {
+				logger.Error("repair shards of node ", node, " failed:", err)
+			}
+			repairMux.Lock()
+			repairStat.Repaired += int64(n)
+			if err != nil {
+				repairStat.Failed++
+			}
+			repairMux.Unlock()
+		}
+	}
+}
+
+// repairNode regenerates the shards of node that are missing or fail a crc, in their directory while it is
+// writable, or else in a data directory without shard of node
+func repairNode(node string, s *shardSet) (n int, err error) {
+	relocating.Store(node, byte(0))
+	defer relocating.Delete(node)
+	if _, ok := defragmap.Load(node); ok || defragging() {
+		return 0, errors.New("the node is being defragmented")
+	}
+	bad := s.damaged()
+	if len(bad) == 0 {
+		return
+	}
+	if len(bad) > len(s.dirs)-int(s.data) {
+		return 0, errors.New("too many shards are lost")
+	}
+	s.mux.Lock()
+	used := slices.Clone(s.dirs)
+	s.mux.Unlock()
+	dirs := make([]string, len(bad))
+	for k, i := range bad {
+		if _, ok := checkDir(used[i]); ok {
+			dirs[k] = used[i]
+		} else if d := shardDirs(1, used); len(d) > 0 {
+			dirs[k], used[i] = d[0], d[0]
+		} else {
+			return 0, errors.New("no data directory for shard " + shardName(node, i))
+		}
+	}
+	if err = s.writeShards(bad, dirs, s.stripe); err != nil {
+		return
+	}
+	s.mux.Lock()
+	moved := !slices.Equal(used, s.dirs)
+	s.mux.Unlock()
+	if moved {
+		nidbs := nodeBytes(node)
+		if v, e := wfsdb.Get(nidbs); e == nil && v != nil {
+			if wnb := bytesToWfsNodeBean(v); wnb != nil {
+				wnb.Sharddirs = used
+				if err = wfsdb.Put(nidbs, wfsNodeBeanToBytes(wnb)); err != nil {
+					return
+				}
+			}
+		}
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for k, i := range bad {
+		if s.files[i] != nil {
+			s.files[i].Close()
+			s.files[i] = nil
+		}
+		if s.dirs[i] != dirs[k] {
+			os.Remove(s.dirs[i] + "/" + shardName(node, i))
+			s.dirs[i] = dirs[k]
+		}
+		s.bad[i] = false
+	}
+	return len(bad), nil
+}
+
+// shardStatus returns the erasure coded nodes with the health of their shards, and the progress of the running
+// or the last repair
+func shardStatus() *sys.ShardsBean {
+	repairMux.Lock()
+	sb := *repairStat
+	repairMux.Unlock()
+	for _, node := range storedNodes(true) {
+		if s := nodeShards(node); s != nil {
+			sb.Nodes = append(sb.Nodes, s.status())
+		}
+	}
+	return &sb
+}
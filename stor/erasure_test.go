@@ -0,0 +1,135 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// useErasure codes the sealed nodes into 2 data and 2 parity shards in 4 data directories of dir
+func useErasure(t *testing.T, dir string) {
+	dataDirs, fileSize, data, parity := sys.DataDirs, sys.FileSize, sys.ErasureData, sys.ErasureParity
+	t.Cleanup(func() {
+		sys.DataDirs, sys.FileSize, sys.ErasureData, sys.ErasureParity = dataDirs, fileSize, data, parity
+		nodeDirs, readonlyDirs, shardSets = &sync.Map{}, &sync.Map{}, &sync.Map{}
+	})
+	sys.DataDirs = nil
+	for _, name := range []string{"a", "b", "c", "d"} {
+		os.MkdirAll(dir+"/"+name, 0777)
+		sys.DataDirs = append(sys.DataDirs, &sys.DataDirBean{Path: dir + "/" + name, Weight: 1})
+	}
+	sys.FileSize, sys.ErasureData, sys.ErasureParity = 1<<18, 2, 2
+}
+
+// codedNode stores the files of datas in a node that is sealed and erasure coded
+func codedNode(t *testing.T, datas [][]byte) (node string) {
+	t.Helper()
+	for i, data := range datas {
+		if _, err := fe.append(fmt.Sprint("e/", i), data, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	node = fe.handler.Node
+	fe.next(node)
+	stopTasks()
+	if nodeShards(node) == nil || goutil.IsFileExist(getpathBynode(node)) {
+		t.Fatal("node is not erasure coded:", node)
+	}
+	return
+}
+
+func readCoded(t *testing.T, datas [][]byte) {
+	t.Helper()
+	for i, data := range datas {
+		if !bytes.Equal(fe.getData(fmt.Sprint("e/", i)), data) {
+			t.Fatal("data of e/", i)
+		}
+	}
+}
+
+func TestErasure(t *testing.T) {
+	dir := t.TempDir()
+	useErasure(t, dir)
+	startStore(dir)
+	defer CloseAll()
+	r := rand.New(rand.NewSource(1))
+	datas := make([][]byte, 3)
+	for i := range datas {
+		datas[i] = make([]byte, 50<<10)
+		r.Read(datas[i])
+	}
+	node := codedNode(t, datas)
+	s := nodeShards(node)
+	readCoded(t, datas)
+	// a shard is lost and a chunk of another one is damaged, they are reconstructed from the others
+	os.Remove(s.dirs[0] + "/" + shardName(node, 0))
+	f, _ := os.OpenFile(s.dirs[3]+"/"+shardName(node, 3), os.O_WRONLY, 0666)
+	f.WriteAt([]byte("damaged"), 100)
+	f.Close()
+	shardSets = &sync.Map{}
+	restart(dir)
+	stopTasks()
+	readCoded(t, datas)
+	if si := nodeShards(node).status(); si.Missing != 1 || si.Damaged != 1 {
+		t.Fatalf("%+v", si)
+	}
+	if n, err := repairNode(node, nodeShards(node)); n != 2 || err != nil {
+		t.Fatal("repaired", n, err)
+	}
+	shardSets = &sync.Map{}
+	if bad := nodeShards(node).damaged(); len(bad) > 0 {
+		t.Fatal("damaged shards after the repair:", bad)
+	}
+	readCoded(t, datas)
+	consistent(t)
+	// more shards than the parity ones are lost
+	s = nodeShards(node)
+	s.Close()
+	for i := 0; i < 3; i++ {
+		os.Remove(s.dirs[i] + "/" + shardName(node, i))
+	}
+	shardSets = &sync.Map{}
+	if fe.getData("e/0") != nil {
+		t.Fatal("data is read without enough shards")
+	}
+	if _, err := repairNode(node, nodeShards(node)); err == nil {
+		t.Fatal("three shards are repaired from one")
+	}
+}
+
+func TestJoinShards(t *testing.T) {
+	dir := t.TempDir()
+	useErasure(t, dir)
+	startStore(dir)
+	defer CloseAll()
+	datas := [][]byte{[]byte("the data of e/0"), bytes.Repeat([]byte("the data of e/1 "), 8192)}
+	node := codedNode(t, datas)
+	dirs := nodeShards(node).dirs
+	// the node is written back to a single file for its defragmentation
+	if err := joinShards(node); err != nil {
+		t.Fatal(err)
+	}
+	if nodeShards(node) != nil || !goutil.IsFileExist(getpathBynode(node)) {
+		t.Fatal("the shards of the node are not joined")
+	}
+	for i, d := range dirs {
+		if goutil.IsFileExist(d + "/" + shardName(node, i)) {
+			t.Fatal("the shard is left:", d)
+		}
+	}
+	readCoded(t, datas)
+	consistent(t)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// expireOn is set once any path may carry an expiry, reads skip the lookup before that
+var expireOn int32
+
+func ttlKey(path string) []byte {
+	return append(TTL_, []byte(path)...)
+}
+
+func expireKey(expire int64, path string) []byte {
+	return append(append(EXPIRE_, goutil.Int64ToBytes(expire)...), []byte(path)...)
+}
+
+func initExpire() {
+	if keys, err := wfsdb.GetKeysPrefixLimit(TTL_, TTL_, 1); len(sys.TTL) > 0 || (err == nil && len(keys) > 0) {
+		atomic.StoreInt32(&expireOn, 1)
+	}
+	goTask(expireTk)
+}
+
+// getExpire returns the expiry of path in unix nanoseconds, 0 if it never expires
+func getExpire(path string) (_r int64) {
+	if atomic.LoadInt32(&expireOn) == 1 {
+		if v, err := wfsdb.Get(ttlKey(path)); err == nil && len(v) == 8 {
+			_r = goutil.BytesToInt64(v)
+		}
+	}
+	return
+}
+
+// cachedExpire is getExpire through the cache for the reads. A path that never expires is cached with 0, so
+// that its reads skip the lookup as well; the writes of the expiry drop it from the cache. The writes that
+// delete the expiry of a path read it by getExpire.
+func cachedExpire(path string) (_r int64) {
+	if atomic.LoadInt32(&expireOn) == 1 {
+		tk := ttlKey(path)
+		if cache != nil {
+			if v, ok := cache.Get(string(tk)); ok {
+				return goutil.BytesToInt64(v)
+			}
+		}
+		_r = getExpire(path)
+		cachePut(tk, goutil.Int64ToBytes(_r))
+	}
+	return
+}
+
+func expired(path string) bool {
+	e := cachedExpire(path)
+	return e > 0 && e <= time.Now().UnixNano()
+}
+
+// defaultExpire applies the ttl of the longest prefix of path configured in sys.TTL
+func defaultExpire(path string) (_r int64) {
+	var pre string
+	for k, ttl := range sys.TTL {
+		if strings.HasPrefix(path, k) && len(k) >= len(pre) {
+			pre, _r = k, util.ExpireAt(ttl)
+		}
+	}
+	return
+}
+
+// expireOf returns the expiry given in mb, or the default one of path
+func expireOf(path string, mb *sys.MetaBean) int64 {
+	if mb != nil && mb.Expire > 0 {
+		return mb.Expire
+	}
+	return defaultExpire(path)
+}
+
+func putExpire(path string, expire int64) {
+	if expire <= 0 {
+		return
+	}
+	atomic.StoreInt32(&expireOn, 1)
+	m := make(map[*[]byte][]byte, 2)
+	tk, ek := ttlKey(path), expireKey(expire, path)
+	m[&tk] = goutil.Int64ToBytes(expire)
+	m[&ek] = []byte{0}
+	wfsdb.BatchPut(m)
+	cacheDel(tk)
+}
+
+// delExpire drops the expiry of path with the batch that deletes path
+func (t *batch) delExpire(path string) {
+	if e := getExpire(path); e > 0 {
+		t.del(ttlKey(path))
+		t.del(expireKey(e, path))
+	}
+}
+
+func snapshotExpire(path string) (_r []*stub.SnapshotBean) {
+	if e := getExpire(path); e > 0 {
+		_r = append(_r, &stub.SnapshotBean{Key: ttlKey(path), Value: goutil.Int64ToBytes(e)}, &stub.SnapshotBean{Key: expireKey(e, path), Value: []byte{0}})
+	}
+	return
+}
+
+func expireTk() {
+	ticker := time.NewTicker(time.Duration(sys.TTLInterval) * time.Second)
+	defer ticker.Stop()
+	for !stopstat {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			if atomic.LoadInt32(&expireOn) == 1 {
+				sweepExpired()
+			}
+			sweepUploads()
+		}
+	}
+}
+
+// sweepExpired walks the expiry index in time order and deletes the expired paths through remove,
+// so that the released space is accounted for defragmentation
+func sweepExpired() {
+	defer util.Recover()
+	for !stopstat {
+		keys, err := wfsdb.GetKeysPrefixLimit(EXPIRE_, EXPIRE_, maxExpireSweep)
+		if err != nil {
+			return
+		}
+		now := time.Now().UnixNano()
+		for _, k := range keys {
+			expire := goutil.BytesToInt64(k[len(EXPIRE_) : len(EXPIRE_)+8])
+			if expire > now || stopstat {
+				return
+			}
+			path := string(k[len(EXPIRE_)+8:])
+			reap(path, expire, k)
+		}
+		if len(keys) < maxExpireSweep {
+			return
+		}
+	}
+}
+
+func reap(path string, expire int64, key []byte) {
+	lockid := goutil.Hash64(append(APPENDLOCK_, []byte(path)...))
+	lockLevel1.Lock(int64(lockid))
+	defer lockLevel1.Unlock(int64(lockid))
+	if getExpire(path) == expire {
+		if err := fe.remove(path); err != nil && err.Equal(sys.ERR_NOTEXSIT) {
+			wfsdb.Batch(nil, [][]byte{ttlKey(path), key})
+			cacheDel(ttlKey(path))
+		}
+	} else {
+		wfsdb.Del(key)
+	}
+}
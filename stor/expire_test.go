@@ -0,0 +1,72 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+func TestSweepExpired(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	fe.append("e/a", []byte("the data of e/a"), 0, &sys.MetaBean{Expire: time.Now().Add(time.Millisecond).UnixNano()})
+	fe.append("e/b", []byte("the data of e/b"), 0, &sys.MetaBean{Expire: time.Now().Add(time.Hour).UnixNano()})
+	time.Sleep(2 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		sweepExpired()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the sweep of the expired paths does not return")
+	}
+	if fe.has("e/a") || getExpire("e/a") != 0 {
+		t.Fatal("the expired path is not deleted")
+	}
+	if string(fe.getData("e/b")) != "the data of e/b" {
+		t.Fatal("the path not expired is deleted")
+	}
+	if _, err := fe.append("e/a", []byte("the data of e/a again"), 0, nil); err != nil {
+		t.Fatal("the lock of the expired path is held:", err)
+	}
+	consistent(t)
+}
+
+func TestCachedExpire(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	fe.append("c/ttl", []byte("the data of c/ttl"), 0, &sys.MetaBean{Expire: time.Now().Add(time.Hour).UnixNano()})
+	fe.append("c/a", []byte("the data of c/a"), 0, nil)
+	// the reads of a path that never expires cache it as such
+	if !fe.has("c/a") {
+		t.Fatal("the path is not found")
+	}
+	if v, ok := cache.Get(string(ttlKey("c/a"))); !ok || len(v) != 8 || cachedExpire("c/a") != 0 {
+		t.Fatal("the path without expiry is not cached")
+	}
+	// the expiry written after is read, by the path and by the path it is renamed to
+	fe.delData("c/a")
+	fe.append("c/a", []byte("the data of c/a again"), 0, &sys.MetaBean{Expire: time.Now().Add(time.Millisecond).UnixNano()})
+	fe.has("c/b")
+	if err := fe.modify("c/a", "c/b"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if fe.has("c/b") || fe.stat("c/b") != nil {
+		t.Fatal("the expired path is found by the cached expiry")
+	}
+	if !fe.has("c/ttl") {
+		t.Fatal("the path not expired is not found")
+	}
+	consistent(t)
+}
@@ -0,0 +1,751 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+const (
+	fsckPrintLimit = 20
+	fsckBatchLimit = 10000
+)
+
+func init() {
+	sys.Fsck = fsck
+}
+
+// fsck cross-checks the metadata of a stopped data directory with its node files and prints what does
+// not agree. With repair, the count, the reference counts, the end offsets and the indexes are rebuilt
+// and the dangling records are dropped.
+func fsck(repair bool) (err error) {
+	if !goutil.IsFileExist(sys.WFSDATA) {
+		return errors.New("wfs data directory does not exist:" + sys.WFSDATA)
+	}
+	if err = openDB(); err != nil {
+		return errors.New("the wfs data cannot be opened, the service must be stopped:" + err.Error())
+	}
+	defer CloseAll()
+	loadHashes()
+	if repair {
+		if err = recoverPending(); err != nil {
+			return
+		}
+	}
+	fc := newFsckChecker()
+	defer fc.close()
+	if err = fc.load(); err != nil {
+		return
+	}
+	fc.check()
+	fc.report(repair)
+	if repair && len(fc.issues) > 0 {
+		if err = fc.commit(); err == nil {
+			fmt.Println("repaired")
+		}
+	}
+	return
+}
+
+// nodeFile is the file of a node, or the shards of an erasure coded node
+type nodeFile interface {
+	io.ReaderAt
+	io.Closer
+}
+
+type fsckNode struct {
+	end     int64
+	hasEnd  bool
+	rmsize  int64
+	hasBean bool
+	hash    int32
+	key     []byte
+	file    nodeFile
+	size    int64
+	coded   bool
+	shards  *shardSet
+}
+
+type fsckChecker struct {
+	nodes    map[string]*fsckNode
+	beans    map[string]*stub.WfsFileBean
+	fids     map[string][]byte
+	paths    map[string]int64
+	seqs     map[int64]*stub.WfsPathBean
+	uploads  map[int64]*stub.WfsUploadBean
+	ttls     map[string]int64
+	expires  [][]byte
+	quarants [][]byte
+	atimes   [][]byte
+	pres     map[string][]byte
+	nodebs   map[string][]byte
+	hashes   []byte
+	dicts    map[int32]bool
+	unknown  int
+	current  string
+	count    int64
+	seq      int64
+	puts     map[string][]byte
+	dels     map[string]bool
+	issues   map[string]int
+	repaired map[string]bool
+}
+
+func newFsckChecker() *fsckChecker {
+	return &fsckChecker{nodes: make(map[string]*fsckNode), beans: make(map[string]*stub.WfsFileBean), fids: make(map[string][]byte), paths: make(map[string]int64),
+		seqs: make(map[int64]*stub.WfsPathBean), uploads: make(map[int64]*stub.WfsUploadBean), ttls: make(map[string]int64), pres: make(map[string][]byte), nodebs: make(map[string][]byte), dicts: make(map[int32]bool),
+		puts: make(map[string][]byte), dels: make(map[string]bool), issues: make(map[string]int), repaired: make(map[string]bool)}
+}
+
+func (t *fsckChecker) close() {
+	for _, n := range t.nodes {
+		if n.file != nil {
+			n.file.Close()
+		}
+	}
+}
+
+// loadShards reads an erasure coded node from its shards, the shards that are missing or fail a crc are
+// regenerated by the repair of the service
+func (t *fsckChecker) loadShards(name string, n *fsckNode, wnb *stub.WfsNodeBean) {
+	if n.file != nil {
+		t.issue("node file beside its shards", name, false)
+		n.file.Close()
+		n.file = nil
+	}
+	n.coded = true
+	s, err := newShardSet(name, wnb.GetDatashards(), wnb.GetCodedsize(), wnb.GetSharddirs())
+	if err != nil {
+		t.issue("invalid erasure coding", name, false)
+		return
+	}
+	n.shards = s
+	bad := s.damaged()
+	for _, i := range bad {
+		t.issue("damaged shard", s.dirs[i]+"/"+shardName(name, i), false)
+	}
+	if len(bad) > len(s.dirs)-int(s.data) {
+		s.Close()
+		return
+	}
+	n.file, n.size = s, s.size
+}
+
+func (t *fsckChecker) node(name string) (n *fsckNode) {
+	if n = t.nodes[name]; n == nil {
+		n = &fsckNode{size: -1}
+		t.nodes[name] = n
+	}
+	return
+}
+
+func nodeName(nidbs []byte) string {
+	if id := goutil.BytesToInt64(nidbs); util.CheckNodeId(id) {
+		return intToStr(uint64(id))
+	}
+	return ""
+}
+
+// load opens the node files and reads every record of the metadata
+func (t *fsckChecker) load() (err error) {
+	for _, dir := range sys.FileDirs() {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, e := range entries {
+				if id, ok := strToInt(e.Name()); ok && !e.IsDir() && !strings.Contains(e.Name(), "_") && util.CheckNodeId(int64(id)) {
+					if n := t.nodes[e.Name()]; n != nil && n.file != nil {
+						t.issue("node file in several directories", e.Name()+" "+getpathBynode(e.Name()), false)
+					} else if f, err := os.Open(getpathBynode(e.Name())); err == nil {
+						fi, _ := f.Stat()
+						n := t.node(e.Name())
+						n.file, n.size = f, fi.Size()
+					}
+				}
+			}
+		}
+	}
+	if err = wfsdb.SnapshotToStream(nil, func(bean *stub.SnapshotBean) bool {
+		t.classify(bean.GetKey(), bean.GetValue())
+		return true
+	}); err != nil {
+		return
+	}
+	// the key of a node bean may also be a fingerprint of 8 bytes, it belongs to a node only if the node is known
+	for k, v := range t.nodebs {
+		if n, ok := t.nodes[nodeName([]byte(k))]; ok {
+			wnb := bytesToWfsNodeBean(v)
+			n.rmsize, n.hasBean, n.key = wnb.GetRmsize(), true, wnb.Key
+			if wnb.Hash != nil {
+				n.hash = wnb.GetHash()
+			} else {
+				n.hash = detectHash(nodeName([]byte(k)))
+			}
+			if wnb.GetDatashards() > 0 {
+				t.loadShards(nodeName([]byte(k)), n, wnb)
+			}
+		} else if !t.raw([]byte(k), v) {
+			t.unknown++
+		}
+	}
+	for name, n := range t.nodes {
+		if !n.hasBean {
+			n.hash = detectHash(name)
+			n.key, _ = os.ReadFile(keyFile(name))
+		}
+	}
+	// a key of the path index may also be a fingerprint beginning with the same bytes
+	for k, v := range t.pres {
+		path := k[len(PATH_PRE):]
+		if wpb, ok := t.seqs[goutil.BytesToInt64(v)]; ok && wpb.GetPath() == path {
+			t.paths[path] = goutil.BytesToInt64(v)
+		} else if !t.raw([]byte(k), v) {
+			t.issue("path index without record", path, true)
+			t.del([]byte(k))
+		}
+	}
+	return
+}
+
+func (t *fsckChecker) classify(k, v []byte) {
+	switch {
+	case bytes.Equal(k, CURRENT):
+		t.current = string(v)
+		return
+	case bytes.Equal(k, SEQ):
+		t.seq = goutil.BytesToInt64(v)
+		return
+	case bytes.Equal(k, COUNT):
+		t.count = goutil.BytesToInt64(v)
+		return
+	case bytes.Equal(k, VERSION_):
+		return
+	case bytes.Equal(k, HASH):
+		t.hashes = v
+		return
+	}
+	if len(k) == len(ENDOFFSET_)+8 && bytes.HasPrefix(k, ENDOFFSET_) {
+		if name := nodeName(k[len(ENDOFFSET_):]); name != "" {
+			n := t.node(name)
+			n.end, n.hasEnd = goutil.BytesToInt64(v), true
+			return
+		}
+	}
+	if len(k) == 8 && nodeName(k) != "" && bytesToWfsNodeBean(v) != nil {
+		t.nodebs[string(k)] = v
+		return
+	}
+	if len(k) == len(PATH_SEQ)+8 && bytes.HasPrefix(k, PATH_SEQ) {
+		if wpb := bytesToWfsPathBean(v); wpb != nil && wpb.Path != nil {
+			t.seqs[goutil.BytesToInt64(k[len(PATH_SEQ):])] = wpb
+			return
+		}
+	}
+	if len(k) == len(UPLOAD_)+8 && bytes.HasPrefix(k, UPLOAD_) {
+		if wub := bytesToWfsUploadBean(v); wub != nil && wub.Path != nil {
+			t.uploads[goutil.BytesToInt64(k[len(UPLOAD_):])] = wub
+			return
+		}
+	}
+	if bytes.HasPrefix(k, TTL_) && len(v) == 8 {
+		t.ttls[string(k[len(TTL_):])] = goutil.BytesToInt64(v)
+		return
+	}
+	if bytes.HasPrefix(k, EXPIRE_) && len(k) >= len(EXPIRE_)+8 {
+		t.expires = append(t.expires, k)
+		return
+	}
+	if bytes.HasPrefix(k, QUARANTINE_) && len(k) > len(QUARANTINE_) && isFingerprint(k[len(QUARANTINE_):]) {
+		t.quarants = append(t.quarants, k)
+		return
+	}
+	if len(k) == len(DICT_)+8 && bytes.HasPrefix(k, DICT_) {
+		if wdb := bytesToWfsDictBean(v); wdb != nil {
+			t.dicts[wdb.GetId()] = true
+			return
+		}
+	}
+	if len(k) == len(ATIME_)+8 && bytes.HasPrefix(k, ATIME_) && len(v) == 8 {
+		t.atimes = append(t.atimes, k)
+		return
+	}
+	if len(k) == len(DEFRAG_)+8 && bytes.HasPrefix(k, DEFRAG_) && bytesToWfsDefragBean(v) != nil {
+		return
+	}
+	if len(k) == len(COMPACT_)+8 && bytes.HasPrefix(k, COMPACT_) && bytesToWfsCompactBean(v) != nil {
+		return
+	}
+	if len(k) == len(INTENT_)+8 && bytes.HasPrefix(k, INTENT_) {
+		if wib := bytesToWfsIntentBean(v); wib != nil {
+			t.issue("pending operation", fmt.Sprint(wib.Journal.GetOp(), " ", wib.Journal.GetPath()), true)
+			return
+		}
+	}
+	if bytes.HasPrefix(k, PATH_PRE) && len(v) == 8 {
+		t.pres[string(k)] = v
+		return
+	}
+	if !t.raw(k, v) {
+		t.unknown++
+	}
+}
+
+// raw classifies the records keyed by a fingerprint, the file beans and the fingerprints of the paths
+func (t *fsckChecker) raw(k, v []byte) bool {
+	if !isFingerprint(k) {
+		return false
+	}
+	if wfb := bytesToWfsFileBean(v); wfb != nil && (len(wfb.Parts) > 0 || (wfb.Storenode != nil && nodeName(nodeBytes(wfb.GetStorenode())) != "")) {
+		t.beans[string(k)] = wfb
+		return true
+	}
+	if isFingerprint(v) {
+		t.fids[string(k)] = v
+		return true
+	}
+	return false
+}
+
+func nodeBytes(node string) []byte {
+	nid, _ := strToInt(node)
+	return goutil.Int64ToBytes(int64(nid))
+}
+
+func (t *fsckChecker) issue(kind, desc string, repairable bool) {
+	if t.issues[kind]++; t.issues[kind] <= fsckPrintLimit {
+		fmt.Println(kind+":", desc)
+	} else if t.issues[kind] == fsckPrintLimit+1 {
+		fmt.Println(kind + ": ...")
+	}
+	t.repaired[kind] = repairable
+}
+
+func (t *fsckChecker) put(k, v []byte) {
+	delete(t.dels, string(k))
+	t.puts[string(k)] = v
+}
+
+func (t *fsckChecker) del(k []byte) {
+	delete(t.puts, string(k))
+	t.dels[string(k)] = true
+}
+
+func (t *fsckChecker) check() {
+	t.checkBlocks()
+	t.checkFids()
+	if sys.Mode == 1 {
+		t.checkPaths()
+	}
+	t.checkRefers()
+	t.checkExpire()
+	t.checkNodes()
+	t.checkHashes()
+	if c := int64(len(t.fids)); c != t.count {
+		t.issue("count", fmt.Sprint(t.count, " -> ", c), true)
+		t.put(COUNT, goutil.Int64ToBytes(c))
+	}
+	var maxid int64
+	for id := range t.seqs {
+		maxid = max(maxid, id)
+	}
+	if maxid > t.seq {
+		t.issue("seq", fmt.Sprint(t.seq, " -> ", maxid), true)
+		t.put(SEQ, goutil.Int64ToBytes(maxid))
+	}
+	if t.unknown > 0 {
+		t.issue("unknown records", fmt.Sprint(t.unknown), false)
+	}
+}
+
+// located reports whether the block header at the node and offset of wfb carries bidBs
+func (t *fsckChecker) located(bidBs []byte, wfb *stub.WfsFileBean) bool {
+	n := t.nodes[wfb.GetStorenode()]
+	if n == nil || n.file == nil || wfb.GetOffset() < 0 || wfb.GetOffset()+int64(hashLen(n.hash)+4)+wfb.GetSize() > n.size {
+		return false
+	}
+	step := hashLen(n.hash)
+	hd := make([]byte, step+4)
+	if _, err := n.file.ReadAt(hd, wfb.GetOffset()); err != nil {
+		return false
+	}
+	return bytes.Equal(hd[:step], bidBs) && int64(goutil.BytesToInt32(hd[step:])) == wfb.GetSize()
+}
+
+// checkBlocks drops the beans of the blocks that are not in their node file, then the manifests
+// and uploads that lost a part
+func (t *fsckChecker) checkBlocks() {
+	for k, wfb := range t.beans {
+		if wfb.Storenode != nil && !t.located([]byte(k), wfb) {
+			t.issue("dangling block record", fmt.Sprint(hex.EncodeToString([]byte(k)), " ", wfb.GetStorenode(), ",", wfb.GetOffset()), true)
+			t.del([]byte(k))
+			delete(t.beans, k)
+		}
+	}
+	for k, wfb := range t.beans {
+		if len(wfb.Parts) > 0 && !t.hasParts(wfb.Parts) {
+			t.issue("dangling manifest", hex.EncodeToString([]byte(k)), true)
+			t.del([]byte(k))
+			delete(t.beans, k)
+		}
+	}
+	for id, wub := range t.uploads {
+		if !t.hasParts(wub.Parts) {
+			t.issue("dangling upload", fmt.Sprint(id, " ", wub.GetPath()), true)
+			t.del(uploadKey(id))
+			delete(t.uploads, id)
+		}
+	}
+	for _, k := range t.quarants {
+		if _, ok := t.beans[string(k[len(QUARANTINE_):])]; !ok {
+			t.issue("dangling quarantine", hex.EncodeToString(k[len(QUARANTINE_):]), true)
+			t.del(k)
+		}
+	}
+	for k, wfb := range t.beans {
+		if id := wfb.GetDict(); id != 0 && !t.dicts[id] {
+			t.checkDict(id, hex.EncodeToString([]byte(k)))
+		}
+	}
+}
+
+// checkDict restores the record of the dictionary id from the dictionary directory, the blocks
+// compressed with a lost dictionary cannot be read
+func (t *fsckChecker) checkDict(id int32, key string) {
+	if bs, err := os.ReadFile(dictDir() + "/" + strconv.Itoa(int(id))); err == nil && bytesToWfsDictBean(bs) != nil {
+		t.issue("missing dictionary record", fmt.Sprint(id), true)
+		t.put(dictKey(id), bs)
+		t.dicts[id] = true
+	} else {
+		t.issue("lost dictionary", fmt.Sprint(id, " ", key), false)
+	}
+}
+
+func (t *fsckChecker) hasParts(parts []*stub.WfsPartBean) bool {
+	for _, p := range parts {
+		if wfb, ok := t.beans[string(p.Fingerprint)]; !ok || wfb.Storenode == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *fsckChecker) checkFids() {
+	for k, v := range t.fids {
+		if _, ok := t.beans[string(v)]; !ok {
+			t.issue("dangling data record", hex.EncodeToString([]byte(k)), true)
+			t.del([]byte(k))
+			delete(t.fids, k)
+		}
+	}
+}
+
+// checkPaths drops the path records whose data is gone and restores the index of the path records
+// that lost it. The data without a path record is only reported, it may be stored before mode 1 was set.
+func (t *fsckChecker) checkPaths() {
+	indexed := make(map[string]bool, len(t.paths))
+	for path, id := range t.paths {
+		fid := t.fid(path)
+		if _, ok := t.fids[fid]; !ok {
+			t.issue("path without data", path, true)
+			t.dropPath(path, id)
+			continue
+		}
+		indexed[fid] = true
+	}
+	for id, wpb := range t.seqs {
+		path := wpb.GetPath()
+		if pid, ok := t.paths[path]; ok && pid == id {
+			continue
+		}
+		fid := t.fid(path)
+		if _, ok := t.fids[fid]; ok && !indexed[fid] {
+			t.issue("path record without index", path, true)
+			t.paths[path] = id
+			t.put(append(PATH_PRE, []byte(path)...), goutil.Int64ToBytes(id))
+			indexed[fid] = true
+		} else {
+			t.issue("orphan path record", fmt.Sprint(id, " ", path), true)
+			t.del(append(PATH_SEQ, goutil.Int64ToBytes(id)...))
+		}
+	}
+	for k := range t.fids {
+		if !indexed[k] {
+			t.issue("data without path record", hex.EncodeToString([]byte(k)), false)
+		}
+	}
+}
+
+// fid returns the key of the data record of path, it may be the fingerprint of any hash algorithm
+func (t *fsckChecker) fid(path string) string {
+	fid := string(fingerprint([]byte(path)))
+	if _, ok := t.fids[fid]; !ok {
+		for _, h := range []int32{0, 1, 2, 3} {
+			if k := string(fingerprintBy(h, []byte(path))); t.fids[k] != nil {
+				return k
+			}
+		}
+	}
+	return fid
+}
+
+func (t *fsckChecker) dropPath(path string, id int64) {
+	t.del(append(PATH_PRE, []byte(path)...))
+	if wpb, ok := t.seqs[id]; ok && wpb.GetPath() == path {
+		t.del(append(PATH_SEQ, goutil.Int64ToBytes(id)...))
+		delete(t.seqs, id)
+	}
+	delete(t.paths, path)
+}
+
+// checkRefers counts the references of every bean from the paths, the manifests and the uploads,
+// the beans without reference are dropped
+func (t *fsckChecker) checkRefers() {
+	refers := make(map[string]int32, len(t.beans))
+	for _, v := range t.fids {
+		refers[string(v)]++
+	}
+	for k, wfb := range t.beans {
+		if len(wfb.Parts) > 0 && refers[k] == 0 {
+			t.issue("unreferenced manifest", hex.EncodeToString([]byte(k)), true)
+			t.del([]byte(k))
+			delete(t.beans, k)
+		}
+	}
+	for _, wfb := range t.beans {
+		for _, p := range wfb.Parts {
+			refers[string(p.Fingerprint)]++
+		}
+	}
+	for _, wub := range t.uploads {
+		for _, p := range wub.Parts {
+			refers[string(p.Fingerprint)]++
+		}
+	}
+	for k, wfb := range t.beans {
+		if refers[k] == 0 {
+			t.issue("unreferenced block", hex.EncodeToString([]byte(k)), true)
+			t.del([]byte(k))
+			delete(t.beans, k)
+		} else if refers[k] != wfb.GetRefercount() {
+			t.issue("reference count", fmt.Sprint(hex.EncodeToString([]byte(k)), " ", wfb.GetRefercount(), " -> ", refers[k]), true)
+			refer := refers[k]
+			wfb.Refercount = &refer
+			t.put([]byte(k), wfsFileBeanToBytes(wfb))
+		}
+	}
+}
+
+func (t *fsckChecker) checkExpire() {
+	indexed := make(map[string]bool, len(t.expires))
+	for _, k := range t.expires {
+		path := string(k[len(EXPIRE_)+8:])
+		if e, ok := t.ttls[path]; ok && e == goutil.BytesToInt64(k[len(EXPIRE_):len(EXPIRE_)+8]) {
+			indexed[path] = true
+		} else {
+			t.issue("dangling expiry index", path, true)
+			t.del(k)
+		}
+	}
+	for path, e := range t.ttls {
+		if _, ok := t.fids[t.fid(path)]; !ok {
+			t.issue("dangling expiry", path, true)
+			t.del(ttlKey(path))
+			t.del(expireKey(e, path))
+		} else if !indexed[path] {
+			t.issue("expiry without index", path, true)
+			t.put(expireKey(e, path), []byte{0})
+		}
+	}
+}
+
+// checkNodes walks the block headers of every node file to rebuild its end offset and the size of its removed blocks
+func (t *fsckChecker) checkNodes() {
+	ends := make(map[string]int64)
+	for _, wfb := range t.beans {
+		if wfb.Storenode != nil {
+			ends[wfb.GetStorenode()] = max(ends[wfb.GetStorenode()], wfb.GetOffset()+int64(hashLen(t.nodes[wfb.GetStorenode()].hash)+4)+wfb.GetSize())
+		}
+	}
+	names := make([]string, 0, len(t.nodes))
+	for name := range t.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := t.nodes[name]
+		nidbs := nodeBytes(name)
+		if n.file == nil {
+			if n.coded {
+				t.issue("lost erasure coded node", name, false)
+			} else if name != t.current {
+				t.issue("missing node file", name, true)
+				t.del(append(ENDOFFSET_, nidbs...))
+				t.del(nidbs)
+			} else {
+				t.issue("missing current node file", name, false)
+			}
+			continue
+		}
+		if !n.hasEnd && ends[name] == 0 {
+			continue
+		}
+		end, rmsize, whole := t.walk(name, n)
+		if whole && end >= ends[name] {
+			if !n.hasBean || n.rmsize != rmsize {
+				t.issue("removed size", fmt.Sprint(name, " ", n.rmsize, " -> ", rmsize), true)
+				t.put(nidbs, wfsNodeBeanToBytes(n.shards.keep(&stub.WfsNodeBean{Rmsize: &rmsize, Hash: &n.hash, Key: n.key, Dir: beanDir(name)})))
+			}
+		}
+		if end = max(end, ends[name]); end != n.end {
+			t.issue("end offset", fmt.Sprint(name, " ", n.end, " -> ", end), true)
+			t.put(append(ENDOFFSET_, nidbs...), goutil.Int64ToBytes(end))
+		}
+	}
+	for _, k := range t.atimes {
+		if nidbs := k[len(ATIME_):]; t.nodes[nodeName(nidbs)] == nil || t.dels[string(nidbs)] {
+			t.issue("dangling access time", nodeName(nidbs), true)
+			t.del(k)
+		}
+	}
+}
+
+// checkHashes adds the hash algorithms of the data records that are missing from the recorded ones,
+// their paths would not be found
+func (t *fsckChecker) checkHashes() {
+	if t.hashes == nil {
+		return
+	}
+	hashes := bytes.Clone(t.hashes)
+	for k := range t.fids {
+		if h := byte(keyHash([]byte(k))); !bytes.Contains(hashes, []byte{h}) {
+			hashes = append(hashes, h)
+		}
+	}
+	if len(hashes) > len(t.hashes) {
+		t.issue("hash algorithms", fmt.Sprint(t.hashes, " -> ", hashes), true)
+		t.put(HASH, hashes)
+	}
+}
+
+// walk returns the end of the blocks found from the beginning of the node file, the size of the blocks
+// without bean with their headers, and whether the walk ended at an empty block header or the end of the file
+func (t *fsckChecker) walk(name string, n *fsckNode) (end, rmsize int64, whole bool) {
+	step := int64(hashLen(n.hash))
+	hd := make([]byte, step+4)
+	zero := make([]byte, step)
+	for end+step+4 <= n.size {
+		if _, err := n.file.ReadAt(hd, end); err != nil {
+			return
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size == 0 && bytes.Equal(hd[:step], zero) {
+			return end, rmsize, true
+		}
+		if size <= 0 || end+step+4+size > n.size {
+			return
+		}
+		if wfb, ok := t.beans[string(hd[:step])]; !ok || wfb.GetStorenode() != name || wfb.GetOffset() != end {
+			rmsize += step + 4 + size
+		}
+		end += step + 4 + size
+	}
+	return end, rmsize, true
+}
+
+func (t *fsckChecker) report(repair bool) {
+	if len(t.issues) == 0 {
+		fmt.Println("no inconsistency found")
+		return
+	}
+	kinds := make([]string, 0, len(t.issues))
+	for k := range t.issues {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	fmt.Println("------------------------------")
+	for _, k := range kinds {
+		s := fmt.Sprint(k, ": ", t.issues[k])
+		if !t.repaired[k] {
+			s += " (not repairable)"
+		}
+		fmt.Println(s)
+	}
+	if !repair {
+		fmt.Println("run with -repair to repair the data")
+	}
+}
+
+// recoverPending finishes or rolls back the operations that were in progress, as the start of the service does
+func recoverPending() (err error) {
+	if err = initJournal(); err != nil {
+		return
+	}
+	defer func() {
+		if journal != nil {
+			journal.close()
+			journal = nil
+		}
+	}()
+	var current string
+	if v, err := wfsdb.Get(CURRENT); err == nil && v != nil {
+		current = string(v)
+	}
+	recoverIntents(current)
+	return
+}
+
+func (t *fsckChecker) commit() error {
+	return writeBatch(t.puts, t.dels)
+}
+
+// writeBatch writes puts and dels in batches of at most fsckBatchLimit records
+func writeBatch(puts map[string][]byte, dels map[string]bool) (err error) {
+	am := make(map[*[]byte][]byte)
+	dm := make([][]byte, 0)
+	flush := func() (err error) {
+		if len(am) > 0 || len(dm) > 0 {
+			if err = wfsdb.Batch(am, dm); err == nil {
+				for k := range am {
+					cacheDel(*k)
+				}
+				for _, k := range dm {
+					cacheDel(k)
+				}
+			}
+			am, dm = make(map[*[]byte][]byte), make([][]byte, 0)
+		}
+		return
+	}
+	for k, v := range puts {
+		key := []byte(k)
+		if am[&key] = v; len(am) >= fsckBatchLimit {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+	for k := range dels {
+		if dm = append(dm, []byte(k)); len(dm) >= fsckBatchLimit {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"fmt"
+	"testing"
+
+	goutil "github.com/donnie4w/gofer/util"
+)
+
+// issues returns the kinds of inconsistency fsck finds in the open store
+func issues(t *testing.T) map[string]int {
+	t.Helper()
+	fc := newFsckChecker()
+	defer fc.close()
+	if err := fc.load(); err != nil {
+		t.Fatal(err)
+	}
+	fc.check()
+	return fc.issues
+}
+
+// stopStore stops the engine as the service stops before fsck runs
+func stopStore() {
+	stopTasks()
+	if journal != nil {
+		journal.close()
+		journal = nil
+	}
+	CloseAll()
+}
+
+func TestFsckRepair(t *testing.T) {
+	dir := t.TempDir()
+	startStore(dir)
+	for i := 0; i < 5; i++ {
+		fe.append(fmt.Sprint("f/", i), []byte(fmt.Sprint("the data of f/", i)), 0, nil)
+	}
+	fe.append("f/dup", []byte("the data of f/0"), 0, nil)
+	// the metadata is damaged as a crash or a lost write of the database leaves it
+	bidBs, wfb := fe.getFileBean("f/0")
+	refer := int32(1)
+	wfb.Refercount = &refer
+	wfsdb.Put(bidBs, wfsFileBeanToBytes(wfb))
+	wfsdb.Put(COUNT, goutil.Int64ToBytes(100))
+	wfsdb.Del(append(PATH_PRE, []byte("f/1")...))
+	wfsdb.Put(pathKey("f/lost"), fingerprint([]byte("the block that is not stored")))
+	endBs := append(ENDOFFSET_, nodeBytes(wfb.GetStorenode())...)
+	end, _ := wfsdb.Get(endBs)
+	wfsdb.Put(endBs, goutil.Int64ToBytes(goutil.BytesToInt64(end)-1))
+	kinds := []string{"reference count", "count", "path record without index", "dangling data record", "end offset"}
+	for _, kind := range kinds {
+		if issues(t)[kind] == 0 {
+			t.Fatal("fsck does not find the", kind, issues(t))
+		}
+	}
+	stopStore()
+	// fsck without repair only reports
+	if err := fsck(false); err != nil {
+		t.Fatal(err)
+	}
+	startStore(dir)
+	if issues(t)["count"] == 0 {
+		t.Fatal("fsck without -repair writes the data")
+	}
+	stopStore()
+	if err := fsck(true); err != nil {
+		t.Fatal(err)
+	}
+	startStore(dir)
+	defer CloseAll()
+	consistent(t)
+	if fe.count() != 6 || fe.has("f/lost") {
+		t.Fatal("count after the repair:", fe.count())
+	}
+	for i := 0; i < 5; i++ {
+		if path := fmt.Sprint("f/", i); string(fe.getData(path)) != "the data of "+path {
+			t.Fatal("data after the repair:", path)
+		}
+	}
+	if pbs := fe.findLike("f/1"); len(pbs) != 1 {
+		t.Fatal("the index of f/1 is not restored")
+	}
+	// the block of f/0 shared with f/dup is kept once one of them is deleted
+	fe.delData("f/dup")
+	if string(fe.getData("f/0")) != "the data of f/0" {
+		t.Fatal("the reference count is not restored")
+	}
+	consistent(t)
+}
+
+func TestFsckEmptyNode(t *testing.T) {
+	dir := t.TempDir()
+	startStore(dir)
+	defer CloseAll()
+	fe.append("f/a", []byte("the data of f/a"), 0, nil)
+	fe.next(fe.handler.Node)
+	empty := fe.handler.Node
+	// the file of the empty node is removed at the start, with its metadata
+	stopStore()
+	startStore(dir)
+	if exist(nodeBytes(empty)) || exist(append(ENDOFFSET_, nodeBytes(empty)...)) {
+		t.Fatal("the metadata of the empty node is left")
+	}
+	if is := issues(t); len(is) > 0 || string(fe.getData("f/a")) != "the data of f/a" {
+		t.Fatal(is)
+	}
+}
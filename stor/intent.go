@@ -0,0 +1,225 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// intentSeq numbers the intents, it starts from the time so that the keys of a restarted service do not collide
+var intentSeq = time.Now().UnixNano()
+
+// fault is called between the steps of an append, a delete, a rename and a defragmentation.
+// The tests replace it to stop the engine there as a crash would.
+var fault = func(step string) {}
+
+// intent is the record of an append, a delete or a rename in progress. It is written before the metadata
+// is changed and deleted once the path journal is written, so that recoverIntents can finish or roll back
+// the operations that a crash interrupted. An append marks its intent as stored with the batch that saves
+// a new block, and as bound with the batch that binds the path.
+type intent struct {
+	key []byte
+	wib *stub.WfsIntentBean
+}
+
+func newIntent(jb *stub.WfsJournalBean) (t *intent) {
+	t = &intent{key: append(INTENT_, goutil.Int64ToBytes(atomic.AddInt64(&intentSeq, 1))...), wib: &stub.WfsIntentBean{Journal: jb}}
+	wfsdb.Put(t.key, t.bytes())
+	fault("intent")
+	return
+}
+
+func bytesToWfsIntentBean(bs []byte) (wib *stub.WfsIntentBean) {
+	wib = &stub.WfsIntentBean{}
+	if util.PDecode(bs, wib) != nil || wib.Journal == nil {
+		wib = nil
+	}
+	return
+}
+
+func (t *intent) bytes() (bs []byte) {
+	bs, _ = util.PEncode(t.wib)
+	return
+}
+
+// stored marks that the append saved a new block, the block owns the reference of the path
+func (t *intent) stored() []byte {
+	stored := true
+	t.wib.Stored = &stored
+	return t.bytes()
+}
+
+// bound marks that the append bound the path, created is set when the path is new
+func (t *intent) bound(created bool) []byte {
+	bound := true
+	t.wib.Bound, t.wib.Created = &bound, &created
+	return t.bytes()
+}
+
+// done writes the path journal and drops the intent, the metadata of a path that is bound again is kept
+func (t *intent) done() {
+	jb := t.wib.Journal
+	if jb.GetOp() == JOURNAL_BIND && !t.wib.GetCreated() {
+		jb = &stub.WfsJournalBean{Op: jb.Op, Path: jb.Path, Fingerprint: jb.Fingerprint, Parts: jb.Parts, CompressType: jb.CompressType, Timestramp: jb.Timestramp}
+	}
+	journalWrite(jb)
+	fault("journal")
+	t.drop()
+}
+
+func (t *intent) drop() {
+	wfsdb.Del(t.key)
+}
+
+func exist(key []byte) bool {
+	v, err := wfsdb.Get(key)
+	return err == nil && v != nil
+}
+
+// recoverIntents finishes the operations that changed the metadata before the service stopped and rolls back
+// the others. The blocks of the appends that stopped before their bean was stored are cleared from node.
+func recoverIntents(node string) {
+	var trim bool
+	start := INTENT_
+	for {
+		keys, err := wfsdb.GetKeysPrefixLimit(INTENT_, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			if v, err := wfsdb.Get(k); err == nil && v != nil {
+				if wib := bytesToWfsIntentBean(v); wib != nil {
+					trim = (&intent{key: k, wib: wib}).recover() || trim
+				} else {
+					wfsdb.Del(k)
+				}
+			}
+			start = append(bytes.Clone(k), 0)
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	if trim && node != "" {
+		trimNode(node)
+	}
+}
+
+// recover applies the intent and reports whether the block of an append may be left in the node file without bean
+func (t *intent) recover() (trim bool) {
+	jb := t.wib.Journal
+	path := jb.GetPath()
+	logger.Warn("recover intent:", jb.GetOp(), " ", path)
+	switch jb.GetOp() {
+	case JOURNAL_BIND:
+		if t.wib.GetBound() {
+			if t.wib.GetCreated() {
+				putExpire(path, jb.GetExpire())
+				if sys.Mode == 1 && !exist(append(PATH_PRE, []byte(path)...)) {
+					putPathIndex(path, &sys.MetaBean{ContentType: jb.GetContentType(), Meta: jb.Meta})
+				}
+			}
+			t.done()
+		} else if t.wib.GetStored() {
+			bat := newBatch()
+			bat.unrefer(jb.Fingerprint)
+			bat.del(t.key)
+			bat.commit()
+		} else {
+			t.drop()
+			trim = true
+		}
+	case JOURNAL_DELETE:
+		if !exist(pathKey(path)) {
+			t.done()
+		} else {
+			t.drop()
+		}
+	case JOURNAL_RENAME:
+		if !exist(pathKey(path)) && exist(pathKey(jb.GetNewpath())) {
+			t.done()
+		} else {
+			t.drop()
+		}
+	default:
+		t.drop()
+	}
+	return
+}
+
+// trimNode clears the blocks without bean written after the end offset of node and counts its removed size again.
+// The blocks cleared in front of a live block keep their size, so that the blocks after them are still found.
+func trimNode(node string) {
+	nid, ok := strToInt(node)
+	if !ok {
+		return
+	}
+	nidbs := goutil.Int64ToBytes(int64(nid))
+	var end int64
+	if v, err := wfsdb.Get(append(ENDOFFSET_, nidbs...)); err == nil && v != nil {
+		end = goutil.BytesToInt64(v)
+	}
+	f, err := util.OpenFile(getpathBynode(node), os.O_RDWR, 0666)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fi, _ := f.Stat()
+	step := int64(hashLen(nodeHash(node)))
+	hd, zero := make([]byte, step+4), make([]byte, step)
+	var offset, rmsize int64
+	// the blocks cleared after end from tail are the hole of size hole, a live block after them keeps them removed
+	var hole int64
+	tail := int64(-1)
+	for offset+step+4 <= fi.Size() {
+		if _, err := f.ReadAt(hd, offset); err != nil {
+			return
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size == 0 && bytes.Equal(hd[:step], zero) {
+			break
+		}
+		if size <= 0 || offset+step+4+size > fi.Size() {
+			if offset >= end {
+				f.WriteAt(make([]byte, step+4), offset)
+			}
+			break
+		}
+		// the end offset written by a concurrent append may be behind a live block
+		if live := liveBean(bytes.Clone(hd[:step]), node, offset) != nil; live && offset >= end {
+			end, tail = offset+step+4+size, -1
+			rmsize, hole = rmsize+hole, 0
+		} else if offset >= end {
+			f.WriteAt(zero, offset)
+			f.WriteAt(make([]byte, size), offset+step+4)
+			if tail < 0 {
+				tail = offset
+			}
+			hole += step + 4 + size
+		} else if !live {
+			rmsize += step + 4 + size
+		}
+		offset += step + 4 + size
+	}
+	if tail >= 0 {
+		f.WriteAt(make([]byte, offset-tail), tail)
+	}
+	m := make(map[*[]byte][]byte, 2)
+	ofsBs := append(ENDOFFSET_, nidbs...)
+	m[&ofsBs], m[&nidbs] = goutil.Int64ToBytes(end), wfsNodeBeanToBytes(newNodeBean(node, rmsize))
+	wfsdb.BatchPut(m)
+}
@@ -0,0 +1,391 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/gofer/hashmap"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+)
+
+type crash string
+
+// crashAt stops the engine at step of the next operation, as a crash would
+func crashAt(step string) {
+	fault = func(s string) {
+		if s == step {
+			panic(crash(s))
+		}
+	}
+}
+
+func run(f func()) {
+	defer func() { recover() }()
+	f()
+}
+
+// startStore starts the engine on dir with the state in memory of a new process, the tasks of the engine started
+// before are stopped first
+func startStore(dir string) {
+	stopTasks()
+	dataEg.mm.closeAll()
+	sys.WFSDATA, sys.Conf, stopstat = dir, &sys.ConfBean{}, false
+	count, seq, referMap = 0, 0, hashmap.NewLimitHashMap[string, *int32](1<<15)
+	nextfn.Store(nil)
+	initStore()
+}
+
+// restart drops the engine without closing it and starts it again on the same data
+func restart(dir string) {
+	fault = func(string) {}
+	stopTasks()
+	if journal != nil {
+		journal.close()
+		journal = nil
+	}
+	CloseAll()
+	startStore(dir)
+}
+
+// consistent checks the metadata against the node files as fsck does
+func consistent(t *testing.T) {
+	t.Helper()
+	fc := newFsckChecker()
+	defer fc.close()
+	if err := fc.load(); err != nil {
+		t.Fatal(err)
+	}
+	fc.check()
+	if len(fc.issues) > 0 {
+		t.Fatal("inconsistent metadata:", fc.issues)
+	}
+}
+
+func journaled(op int32, path string) (b bool) {
+	readJournal(func(jb *stub.WfsJournalBean) {
+		if jb.GetOp() == op && jb.GetPath() == path {
+			b = true
+		}
+	})
+	return
+}
+
+func TestAppendRecovery(t *testing.T) {
+	data := []byte("the data of the append")
+	for _, c := range []struct {
+		step  string
+		bound bool
+	}{{"intent", false}, {"block", false}, {"store", false}, {"bind", true}, {"index", true}, {"journal", true}} {
+		t.Run(c.step, func(t *testing.T) {
+			dir := t.TempDir()
+			startStore(dir)
+			fe.append("a", []byte("the data of a"), 1, nil)
+			crashAt(c.step)
+			run(func() { fe.append("b", data, 1, &sys.MetaBean{ContentType: "text/plain", Expire: 1 << 62}) })
+			restart(dir)
+			consistent(t)
+			if got := fe.getData("b"); c.bound != bytes.Equal(got, data) {
+				t.Fatalf("data of b after a crash at %s: %q", c.step, got)
+			}
+			if c.bound {
+				if sb := fe.stat("b"); sb == nil || sb.ContentType != "text/plain" || sb.Expire != 1<<62 {
+					t.Fatalf("metadata of b after a crash at %s: %v", c.step, sb)
+				}
+				if !journaled(JOURNAL_BIND, "b") {
+					t.Fatal("the bind of b is not journaled")
+				}
+			}
+			if _, err := fe.append("c", []byte("the data of c"), 1, nil); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(fe.getData("c"), []byte("the data of c")) || !bytes.Equal(fe.getData("a"), []byte("the data of a")) {
+				t.Fatal("the data stored around the crash is lost")
+			}
+			consistent(t)
+		})
+	}
+}
+
+func TestDeleteRenameRecovery(t *testing.T) {
+	for _, c := range []struct {
+		step string
+		done bool
+	}{{"intent", false}, {"commit", true}, {"journal", true}} {
+		t.Run(c.step, func(t *testing.T) {
+			dir := t.TempDir()
+			startStore(dir)
+			fe.append("a", []byte("the data of a"), 0, nil)
+			fe.append("b", []byte("the data of b"), 0, nil)
+			crashAt(c.step)
+			run(func() { fe.delData("a") })
+			crashAt(c.step)
+			run(func() { fe.modify("b", "c") })
+			restart(dir)
+			consistent(t)
+			if c.done != (fe.getData("a") == nil) {
+				t.Fatalf("a after a crash at %s", c.step)
+			}
+			if c.done != bytes.Equal(fe.getData("c"), []byte("the data of b")) {
+				t.Fatalf("c after a crash at %s", c.step)
+			}
+			if c.done && (!journaled(JOURNAL_DELETE, "a") || !journaled(JOURNAL_RENAME, "b")) {
+				t.Fatal("the delete and the rename are not journaled")
+			}
+		})
+	}
+}
+
+func TestDefragRecovery(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	dir := t.TempDir()
+	startStore(dir)
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	for i := 0; i < len(paths); i += 3 {
+		fe.delData(paths[i])
+	}
+	node := storedNodes(true)[0]
+	crashAt("swap")
+	run(func() { fe.defragNode(node, nil) })
+	if _, err := wfsdb.Get(compactKey(node)); err != nil {
+		t.Fatal("the defragmentation is not checkpointed")
+	}
+	restart(dir)
+	consistent(t)
+	if _, err := wfsdb.Get(compactKey(node)); err == nil || exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+		t.Fatal("the defragmentation is not resumed")
+	}
+	for i, path := range paths {
+		if got := fe.getData(path); (i%3 == 0) != (got == nil) || got != nil && string(got) != "the data of "+path {
+			t.Fatalf("data of %s after the defragmentation: %q", path, got)
+		}
+	}
+}
+
+func TestMergeRecovery(t *testing.T) {
+	fileSize, merge := sys.FileSize, sys.DefragMerge
+	defer func() { sys.FileSize, sys.DefragMerge = fileSize, merge }()
+	sys.FileSize, sys.DefragMerge = 1<<16, 0.5
+	dir := t.TempDir()
+	startStore(dir)
+	var paths []string
+	for len(storedNodes(true)) < 4 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	nodes := storedNodes(true)
+	kept := func(i int) bool { return i%10 == 0 && i < len(paths)/2 }
+	for i, path := range paths {
+		if !kept(i) {
+			fe.delData(path)
+		}
+	}
+	dead, groups := mergeCandidates()
+	if len(dead) == 0 || len(groups) != 1 {
+		t.Fatal("dead nodes:", dead, ", merge groups:", groups)
+	}
+	crashAt("swap")
+	run(func() { mergeNodes(groups[0], nil) })
+	restart(dir)
+	mergeSparse(false, func([]string, int, int64, sys.ERROR) {})
+	consistent(t)
+	for _, node := range nodes {
+		if exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+			t.Fatal("node is not merged:", node)
+		}
+	}
+	for i, path := range paths {
+		if got := fe.getData(path); kept(i) != (got != nil) || got != nil && string(got) != "the data of "+path {
+			t.Fatalf("data of %s after the merge: %q", path, got)
+		}
+	}
+}
+
+func TestTrimHole(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	dir := t.TempDir()
+	startStore(dir)
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	node := storedNodes(true)[0]
+	// the block of f1 is left without bean after the end offset, in front of the live blocks after it
+	sb := fe.stat("f1")
+	if sb == nil || sb.Node != node {
+		t.Fatal("stat of f1:", sb)
+	}
+	fe.delData("f1")
+	wfsdb.Put(append(ENDOFFSET_, nodeBytes(node)...), goutil.Int64ToBytes(sb.Offset))
+	trimNode(node)
+	for _, f := range []func(){func() { restart(dir) }, func() { fe.defragNode(node, nil) }} {
+		f()
+		consistent(t)
+		for i, path := range paths {
+			if got := fe.getData(path); (i == 1) != (got == nil) || got != nil && string(got) != "the data of "+path {
+				t.Fatalf("data of %s after the trim: %q", path, got)
+			}
+		}
+	}
+}
+
+func TestMovedRefer(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	dir := t.TempDir()
+	startStore(dir)
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	node := storedNodes(true)[0]
+	fe.delData("f0")
+	// a batch reads the beans of f1 and f2 before their blocks are moved, and commits after
+	get := func(key []byte) []byte { v, _ := wfsdb.Get(key); return v }
+	b1, b2 := get(pathKey("f1")), get(pathKey("f2"))
+	t1, t2 := newBatch(), newBatch()
+	t1.refer(b1, get(b1))
+	t2.unrefer(b2)
+	fe.defragNode(node, nil)
+	into := fe.stat("f2").Node
+	if into == node {
+		t.Fatal("f2 is not moved")
+	}
+	nid, _ := strToInt(into)
+	rmsize := bytesToWfsNodeBean(get(goutil.Int64ToBytes(int64(nid)))).GetRmsize()
+	if err := t1.commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := t2.commit(); err != nil {
+		t.Fatal(err)
+	}
+	if wfb := bytesToWfsFileBean(get(b1)); wfb.GetStorenode() == node || wfb.GetRefercount() != 2 {
+		t.Fatalf("bean of f1 after the move: %v", wfb)
+	}
+	if got := fe.getData("f1"); string(got) != "the data of f1" {
+		t.Fatalf("data of f1 after the move: %q", got)
+	}
+	if bytesToWfsNodeBean(get(goutil.Int64ToBytes(int64(nid)))).GetRmsize() <= rmsize {
+		t.Fatal("the removed block of f2 is not counted at the node it is moved to")
+	}
+}
+
+func TestDropLiveNode(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	dir := t.TempDir()
+	startStore(dir)
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	node := storedNodes(true)[0]
+	// the blocks of f0 and f2 are counted twice, once without their headers
+	var legacy int64
+	for _, path := range paths {
+		if sb := fe.stat(path); path != "f1" && sb != nil && sb.Node == node {
+			if path == "f0" || path == "f2" {
+				legacy += sb.Size
+			}
+			fe.delData(path)
+		}
+	}
+	nid, _ := strToInt(node)
+	nidbs := goutil.Int64ToBytes(int64(nid))
+	v, _ := wfsdb.Get(nidbs)
+	rmsize := bytesToWfsNodeBean(v).GetRmsize() + legacy
+	wfsdb.Put(nidbs, wfsNodeBeanToBytes(newNodeBean(node, rmsize)))
+	if dead, _ := mergeCandidates(); len(dead) != 1 || dead[0] != node {
+		t.Fatal("dead nodes:", dead)
+	}
+	mergeSparse(false, func([]string, int, int64, sys.ERROR) {})
+	consistent(t)
+	if exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+		t.Fatal("node is not defragmented:", node)
+	}
+	if got := fe.getData("f1"); string(got) != "the data of f1" {
+		t.Fatalf("data of f1 after the drop: %q", got)
+	}
+}
+
+func TestDefragAppend(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	startStore(t.TempDir())
+	defer CloseAll()
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	for i := 0; i < len(paths); i += 3 {
+		fe.delData(paths[i])
+	}
+	node := storedNodes(true)[0]
+	// the appends bind new paths to the blocks of the node while it is compacted
+	done, stop := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 1; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				path := paths[(w+i*4)%len(paths)]
+				fe.append(fmt.Sprint("d", w, "/", path), []byte("the data of "+path), 0, nil)
+			}
+		}(w)
+	}
+	go func() {
+		fe.defragNode(node, nil)
+		close(stop)
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("the appends and the defragmentation do not return")
+	}
+	consistent(t)
+	for i, path := range paths {
+		if got := fe.getData(path); (i%3 == 0) != (got == nil) || got != nil && string(got) != "the data of "+path {
+			t.Fatalf("data of %s after the defragmentation: %q", path, got)
+		}
+	}
+}
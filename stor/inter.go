@@ -29,7 +29,8 @@ type DB interface {
 	GetKeys() ([]string, error)
 	GetKeysPrefix(prefix []byte) ([][]byte, error)
 	GetValuesPrefix(prefix []byte) ([][]byte, error)
-	GetKeysPrefixLimit(prefix []byte, limit int) ([][]byte, error)
+	// GetKeysPrefixLimit returns at most limit keys of prefix, beginning at the first key not less than start
+	GetKeysPrefixLimit(prefix, start []byte, limit int) ([][]byte, error)
 	GetValuesPrefixLimit(prefix []byte, limit int) ([][]byte, error)
 	GetIterLimit(prefix string, limit string) (map[string][]byte, error)
 	SnapshotToStream(prefix []byte, streamfunc func(bean *stub.SnapshotBean) bool) error
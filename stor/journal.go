@@ -0,0 +1,314 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+const (
+	JOURNAL_BIND   = int32(1)
+	JOURNAL_DELETE = int32(2)
+	JOURNAL_RENAME = int32(3)
+)
+
+const journalSegmentSize = 64 << 20
+
+// journalCheckpoint ends the name of a segment that holds the paths bound by the segments it replaced
+const journalCheckpoint = "c"
+
+// pathJournal keeps every change of the path to fingerprint mappings in files beside the node files,
+// so that the paths can be restored by rebuild if the metadata database is lost.
+// A record is [int32 length][int32 crc32][WfsJournalBean].
+type pathJournal struct {
+	mux     sync.Mutex
+	f       *os.File
+	size    int64
+	folding int32
+}
+
+var journal *pathJournal
+
+func journalDir() string {
+	return sys.WFSDATA + "/wfsjournal"
+}
+
+func initJournal() (err error) {
+	if !sys.Journal {
+		return
+	}
+	if err = os.MkdirAll(journalDir(), 0777); err == nil {
+		journal = &pathJournal{}
+		if err = journal.fold(""); err == nil {
+			err = journal.rotate()
+		}
+	}
+	return
+}
+
+func (t *pathJournal) rotate() (err error) {
+	var f *os.File
+	if f, err = util.OpenFile(fmt.Sprintf("%s/%020d", journalDir(), time.Now().UnixNano()), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666); err == nil {
+		if t.f != nil {
+			t.f.Close()
+			current := f.Name()
+			goTask(func() {
+				if err := t.fold(current); err != nil {
+					logger.Error("journal checkpoint error:", err)
+				}
+			})
+		}
+		t.f, t.size = f, 0
+	}
+	return
+}
+
+// fold replaces the closed segments by a checkpoint of the paths they leave bound, once the records written after
+// the last checkpoint outgrow it, so that the journal keeps a size in proportion to the paths. Empty segments are removed.
+// The segments from current on are still written.
+func (t *pathJournal) fold(current string) (err error) {
+	if !atomic.CompareAndSwapInt32(&t.folding, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&t.folding, 0)
+	var names []string
+	if names, err = journalNames(); err != nil {
+		return
+	}
+	var closed []string
+	var checkpoint, after int64
+	for _, name := range names {
+		if current != "" && name >= current {
+			break
+		}
+		fi, e := os.Stat(name)
+		if e != nil {
+			return e
+		}
+		if fi.Size() == 0 {
+			os.Remove(name)
+			continue
+		}
+		if closed = append(closed, name); strings.HasSuffix(name, journalCheckpoint) {
+			checkpoint, after = fi.Size(), 0
+		} else {
+			after += fi.Size()
+		}
+	}
+	if after <= checkpoint {
+		return
+	}
+	paths, err := journalPaths(closed)
+	if err != nil {
+		return
+	}
+	tmp := journalDir() + ".tmp"
+	var f *os.File
+	if f, err = util.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666); err != nil {
+		return
+	}
+	w := bufio.NewWriter(f)
+	for _, jb := range paths {
+		if bs, e := util.PEncode(jb); e == nil {
+			w.Write(append(append(goutil.Int32ToBytes(int32(len(bs))), goutil.Int32ToBytes(int32(goutil.CRC32(bs)))...), bs...))
+		}
+	}
+	if err = w.Flush(); err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	// the last closed segment is not a checkpoint, the checkpoint is read after it and before the segments written later
+	if err == nil {
+		err = os.Rename(tmp, closed[len(closed)-1]+journalCheckpoint)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return
+	}
+	for _, name := range closed {
+		os.Remove(name)
+	}
+	return
+}
+
+func (t *pathJournal) write(jb *stub.WfsJournalBean) {
+	bs, err := util.PEncode(jb)
+	if err != nil {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.size >= journalSegmentSize {
+		if err = t.rotate(); err != nil {
+			logger.Error("journal rotate error:", err)
+		}
+	}
+	if _, err = t.f.Write(append(append(goutil.Int32ToBytes(int32(len(bs))), goutil.Int32ToBytes(int32(goutil.CRC32(bs)))...), bs...)); err != nil {
+		logger.Error("journal write error:", err)
+		return
+	}
+	t.size += int64(len(bs) + 8)
+	if sys.SYNC {
+		t.f.Sync()
+	}
+}
+
+func (t *pathJournal) close() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.f.Close()
+}
+
+// journalBind records that path is bound to bidBs. mb is given only when path is new,
+// the metadata of a path that is bound again is kept.
+func journalBind(path string, bidBs []byte, parts []*stub.WfsPartBean, compressType int32, mb *sys.MetaBean) {
+	journalWrite(bindBean(path, bidBs, parts, compressType, mb))
+}
+
+func journalWrite(jb *stub.WfsJournalBean) {
+	if journal != nil {
+		journal.write(jb)
+	}
+}
+
+func bindBean(path string, bidBs []byte, parts []*stub.WfsPartBean, compressType int32, mb *sys.MetaBean) (jb *stub.WfsJournalBean) {
+	op, timestramp := JOURNAL_BIND, time.Now().UnixNano()
+	jb = &stub.WfsJournalBean{Op: &op, Path: &path, Fingerprint: bidBs, Parts: parts, CompressType: &compressType, Timestramp: &timestramp}
+	if mb != nil {
+		if mb.ContentType != "" {
+			jb.ContentType = &mb.ContentType
+		}
+		jb.Meta = mb.Meta
+		if mb.Expire > 0 {
+			jb.Expire = &mb.Expire
+		}
+	}
+	return
+}
+
+// journalMeta is the metadata of a new path as it is stored, expire includes the default of the path
+func journalMeta(mb *sys.MetaBean, expire int64) (_r *sys.MetaBean) {
+	_r = &sys.MetaBean{Expire: expire}
+	if mb != nil {
+		_r.ContentType, _r.Meta = mb.ContentType, mb.Meta
+	}
+	return
+}
+
+func deleteBean(path string) *stub.WfsJournalBean {
+	op := JOURNAL_DELETE
+	return &stub.WfsJournalBean{Op: &op, Path: &path}
+}
+
+func renameBean(path, newpath string) *stub.WfsJournalBean {
+	op := JOURNAL_RENAME
+	return &stub.WfsJournalBean{Op: &op, Path: &path, Newpath: &newpath}
+}
+
+// journalNames returns the journal files in the order they are written
+func journalNames() (names []string, err error) {
+	if names, err = filepath.Glob(journalDir() + "/*"); err == nil {
+		sort.Strings(names)
+	}
+	return
+}
+
+// readJournal reads the records of all journal files in order, a file ends at its first torn record
+func readJournal(f func(jb *stub.WfsJournalBean)) (err error) {
+	var names []string
+	if names, err = journalNames(); err != nil {
+		return
+	}
+	for _, name := range names {
+		if err = readJournalFile(name, f); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// journalPaths applies the records of the journal files to the paths in order and returns the bound paths in the
+// order of their first binding, the metadata of a path is the one of its first binding
+func journalPaths(names []string) (_r []*stub.WfsJournalBean, err error) {
+	paths := make(map[string]*stub.WfsJournalBean)
+	for _, name := range names {
+		err = readJournalFile(name, func(jb *stub.WfsJournalBean) {
+			path := jb.GetPath()
+			switch jb.GetOp() {
+			case JOURNAL_BIND:
+				if old, ok := paths[path]; ok {
+					old.Fingerprint, old.Parts, old.CompressType = jb.Fingerprint, jb.Parts, jb.CompressType
+				} else {
+					paths[path] = jb
+				}
+			case JOURNAL_DELETE:
+				delete(paths, path)
+			case JOURNAL_RENAME:
+				if old, ok := paths[path]; ok {
+					delete(paths, path)
+					old.Path = jb.Newpath
+					paths[jb.GetNewpath()] = old
+				}
+			}
+		})
+		if err != nil {
+			return
+		}
+	}
+	_r = make([]*stub.WfsJournalBean, 0, len(paths))
+	for _, jb := range paths {
+		_r = append(_r, jb)
+	}
+	sort.Slice(_r, func(i, j int) bool { return _r[i].GetTimestramp() < _r[j].GetTimestramp() })
+	return
+}
+
+func readJournalFile(name string, f func(jb *stub.WfsJournalBean)) (err error) {
+	var fl *os.File
+	if fl, err = os.Open(name); err != nil {
+		return
+	}
+	defer fl.Close()
+	r := bufio.NewReader(fl)
+	hd := make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(r, hd); err != nil {
+			break
+		}
+		length := goutil.BytesToInt32(hd[:4])
+		if length < 0 || length > journalSegmentSize {
+			logger.Warn("journal torn record:", name)
+			break
+		}
+		bs := make([]byte, length)
+		if _, err = io.ReadFull(r, bs); err != nil || int32(goutil.CRC32(bs)) != goutil.BytesToInt32(hd[4:]) {
+			logger.Warn("journal torn record:", name)
+			break
+		}
+		jb := &stub.WfsJournalBean{}
+		if util.PDecode(bs, jb) == nil {
+			f(jb)
+		}
+	}
+	return nil
+}
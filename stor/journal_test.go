@@ -0,0 +1,48 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestJournalFold(t *testing.T) {
+	dir := t.TempDir()
+	startStore(dir)
+	for i := 0; i < 4; i++ {
+		path := fmt.Sprint("j", i)
+		fe.append(path, []byte("the data of "+path), 0, nil)
+	}
+	fe.append("j0", []byte("the new data of j0"), 0, nil)
+	fe.delData("j1")
+	fe.modify("j2", "j4")
+	bound := map[string][]byte{}
+	for _, path := range []string{"j0", "j3", "j4"} {
+		bound[path], _ = wfsdb.Get(pathKey(path))
+	}
+	for i := 0; i < 3; i++ {
+		restart(dir)
+		names, _ := journalNames()
+		if len(names) != 2 || !strings.HasSuffix(names[0], journalCheckpoint) {
+			t.Fatal("journal files after the restart:", names)
+		}
+		jbs, err := journalPaths(names)
+		if err != nil || len(jbs) != len(bound) {
+			t.Fatal("journal paths:", jbs, err)
+		}
+		for _, jb := range jbs {
+			if !bytes.Equal(jb.Fingerprint, bound[jb.GetPath()]) {
+				t.Fatalf("journal path %s: %x", jb.GetPath(), jb.Fingerprint)
+			}
+		}
+	}
+	CloseAll()
+}
@@ -8,6 +8,7 @@
 package stor
 
 import (
+	"bytes"
 	"github.com/donnie4w/wfs/stub"
 	"github.com/donnie4w/wfs/sys"
 	"github.com/donnie4w/wfs/util"
@@ -185,11 +186,15 @@ func (t *ldb) GetValuesPrefix(prefix []byte) (bys [][]byte, err error) {
 	return
 }
 
-func (t *ldb) GetKeysPrefixLimit(prefix []byte, limit int) (bys [][]byte, err error) {
+func (t *ldb) GetKeysPrefixLimit(prefix, start []byte, limit int) (bys [][]byte, err error) {
 	if t.db == nil {
 		return nil, os.ErrInvalid
 	}
-	iter := t.db.NewIterator(levelutil.BytesPrefix(prefix), nil)
+	rg := levelutil.BytesPrefix(prefix)
+	if bytes.Compare(start, rg.Start) > 0 {
+		rg.Start = start
+	}
+	iter := t.db.NewIterator(rg, nil)
 	defer iter.Release()
 	bys = make([][]byte, 0)
 	i := 0
@@ -8,6 +8,7 @@
 package stor
 
 import (
+	"bytes"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -320,13 +321,16 @@ func (s *sqliteDB) GetValuesPrefix(prefix []byte) ([][]byte, error) {
 	return values, rows.Err()
 }
 
-func (s *sqliteDB) GetKeysPrefixLimit(prefix []byte, limit int) ([][]byte, error) {
+func (s *sqliteDB) GetKeysPrefixLimit(prefix, start []byte, limit int) ([][]byte, error) {
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
 	rows, err := s.db.Query(`
 		SELECT key FROM kv_store 
 		WHERE key >= ? AND key < ?
 		ORDER BY key
 		LIMIT ?`,
-		prefix, s.nextPrefix(prefix), limit)
+		start, s.nextPrefix(prefix), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,236 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"container/list"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/donnie4w/gofer/mmap"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// The maps of the node files are bounded by sys.MmapSize bytes and sys.MmapNodes maps, there is no bound if both
+// are 0. A node read again within mmapIdle of its last read is mapped, the maps idle for mmapIdle are evicted by
+// their last use to make room for it, and a node that is not mapped is read by pread. The maps of the nodes
+// appended to are fixed until their writer is done. A read copies its bytes out of the map, so that a map evicted
+// or replaced while it is read is unmapped by its last reader.
+const (
+	mmapIdle     = time.Minute
+	maxColdReads = 1 << 12
+)
+
+type nodeMap struct {
+	mm    *Mmap
+	refs  int32
+	last  int64
+	fixed bool
+	gone  bool
+	elem  *list.Element
+}
+
+type mmPool struct {
+	mux    *sync.Mutex
+	maps   map[uint64]*nodeMap
+	lru    *list.List
+	size   int64
+	reads  map[uint64]int64
+	mapped int64
+	unmaps int64
+	preads int64
+}
+
+func newMmPool() *mmPool {
+	return &mmPool{mux: &sync.Mutex{}, maps: make(map[uint64]*nodeMap), lru: list.New(), reads: make(map[uint64]int64)}
+}
+
+func init() {
+	sys.MmapStatus = func() *sys.MmapBean { return dataEg.mm.status() }
+}
+
+func initMmap() {
+	if sys.MmapSize > 0 || sys.MmapNodes > 0 {
+		goTask(mmapTk)
+	}
+}
+
+// mmapTk evicts the idle maps over the bounds, as the maps of the nodes appended to may exceed them
+func mmapTk() {
+	ticker := time.NewTicker(mmapIdle)
+	defer ticker.Stop()
+	for !stopstat {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			dataEg.mm.sweep()
+		}
+	}
+}
+
+func (t *mmPool) has(id uint64) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	_, ok := t.maps[id]
+	return ok
+}
+
+// acquire returns the map of id held for a read, it is given back by release
+func (t *mmPool) acquire(id uint64) (m *nodeMap) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if m = t.maps[id]; m != nil {
+		m.refs++
+		m.last = time.Now().UnixNano()
+		if m.elem != nil {
+			t.lru.MoveToBack(m.elem)
+		}
+	}
+	return
+}
+
+func (t *mmPool) release(m *nodeMap) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if m.refs--; m.gone && m.refs == 0 {
+		t.unmap(m)
+	}
+}
+
+// put keeps mm as the map of id in place of the one it had, a fixed map is not evicted until it is unfixed
+func (t *mmPool) put(id uint64, mm *Mmap, fixed bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if old := t.maps[id]; old != nil {
+		t.drop(id, old)
+	}
+	m := &nodeMap{mm: mm, last: time.Now().UnixNano(), fixed: fixed}
+	if !fixed {
+		m.elem = t.lru.PushBack(id)
+	}
+	t.maps[id] = m
+	t.size += mm.FileSize()
+	atomic.AddInt64(&t.mapped, 1)
+}
+
+// unfix makes the map of id evictable once its writer is done
+func (t *mmPool) unfix(id uint64) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if m := t.maps[id]; m != nil && m.fixed {
+		m.fixed, m.last = false, time.Now().UnixNano()
+		m.elem = t.lru.PushBack(id)
+	}
+}
+
+func (t *mmPool) del(id uint64) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if m := t.maps[id]; m != nil {
+		t.drop(id, m)
+	}
+}
+
+func (t *mmPool) drop(id uint64, m *nodeMap) {
+	delete(t.maps, id)
+	if m.elem != nil {
+		t.lru.Remove(m.elem)
+	}
+	t.size -= m.mm.FileSize()
+	if m.gone = true; m.refs == 0 {
+		t.unmap(m)
+	}
+}
+
+func (t *mmPool) unmap(m *nodeMap) {
+	m.mm.UnmapAndCloseFile()
+	atomic.AddInt64(&t.unmaps, 1)
+}
+
+// admit reports whether the node id of size bytes is mapped for its read, the idle maps are evicted to make room
+// for it. A node is not mapped on its first read within mmapIdle.
+func (t *mmPool) admit(id uint64, size int64) bool {
+	if sys.MmapSize <= 0 && sys.MmapNodes <= 0 {
+		return true
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	now := time.Now().UnixNano()
+	if last, ok := t.reads[id]; ok && now-last < int64(mmapIdle) && t.room(size, 1, now) {
+		delete(t.reads, id)
+		return true
+	}
+	if t.reads[id] = now; len(t.reads) > maxColdReads {
+		for k, last := range t.reads {
+			if now-last >= int64(mmapIdle) {
+				delete(t.reads, k)
+			}
+		}
+	}
+	return false
+}
+
+func (t *mmPool) sweep() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.room(0, 0, time.Now().UnixNano())
+}
+
+// room evicts the maps idle for mmapIdle, the least recently used first, until n maps of size bytes fit
+func (t *mmPool) room(size int64, n int, now int64) bool {
+	fits := func() bool {
+		return (sys.MmapSize <= 0 || t.size+size <= sys.MmapSize) && (sys.MmapNodes <= 0 || len(t.maps)+n <= sys.MmapNodes)
+	}
+	for e := t.lru.Front(); e != nil && !fits(); {
+		id := e.Value.(uint64)
+		m, next := t.maps[id], e.Next()
+		if now-m.last < int64(mmapIdle) {
+			break
+		}
+		if m.refs == 0 {
+			t.drop(id, m)
+		}
+		e = next
+	}
+	return fits()
+}
+
+func (t *mmPool) closeAll() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	for id, m := range t.maps {
+		delete(t.maps, id)
+		m.mm.Unmap()
+	}
+	t.lru.Init()
+	t.size = 0
+}
+
+func (t *mmPool) status() *sys.MmapBean {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return &sys.MmapBean{Maps: atomic.LoadInt64(&t.mapped), Unmaps: atomic.LoadInt64(&t.unmaps), Preads: atomic.LoadInt64(&t.preads), Nodes: len(t.maps), Bytes: t.size}
+}
+
+// pread reads the bytes of node from its file
+func (t *mmPool) pread(node string, offset int64, size int64) (bs []byte, ok bool) {
+	if f, err := os.Open(getpathBynode(node)); err == nil {
+		defer f.Close()
+		bs = make([]byte, size)
+		if _, err = f.ReadAt(bs, offset); err == nil {
+			ok = true
+			atomic.AddInt64(&t.preads, 1)
+		} else {
+			bs = nil
+		}
+	}
+	return
+}
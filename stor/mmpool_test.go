@@ -0,0 +1,127 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+// idle makes the map of node idle for mmapIdle
+func idle(node string) {
+	id, _ := strToInt(node)
+	dataEg.mm.mux.Lock()
+	defer dataEg.mm.mux.Unlock()
+	if m := dataEg.mm.maps[id]; m != nil {
+		m.last -= int64(2 * mmapIdle)
+	}
+}
+
+func mapped(node string) bool {
+	id, _ := strToInt(node)
+	return dataEg.mm.has(id)
+}
+
+func TestMmapEvict(t *testing.T) {
+	fileSize, mmapNodes := sys.FileSize, sys.MmapNodes
+	defer func() { sys.FileSize, sys.MmapNodes = fileSize, mmapNodes }()
+	// the current node and one sealed node are mapped
+	sys.FileSize, sys.MmapNodes = 1<<16, 2
+	startStore(t.TempDir())
+	defer CloseAll()
+	data := bytes.Repeat([]byte("the data of m/a "), 2048)
+	fe.append("m/a", data, 0, nil)
+	a := fe.handler.Node
+	fe.next(a)
+	fe.append("m/b", []byte("the data of m/b"), 0, nil)
+	b := fe.handler.Node
+	fe.next(b)
+	dataEg.unMmap(a)
+	dataEg.unMmap(b)
+	// a node is mapped on its second read
+	if fe.getData("m/a"); mapped(a) {
+		t.Fatal("node is mapped on its first read")
+	}
+	if fe.getData("m/a"); !mapped(a) {
+		t.Fatal("node is not mapped on its second read")
+	}
+	db := fe.getReader("m/a")
+	half := make([]byte, len(data)/2)
+	if _, err := io.ReadFull(db.Reader, half); err != nil {
+		t.Fatal(err)
+	}
+	// the idle map held by the reader is not evicted, the other node is read from its file
+	idle(a)
+	preads := dataEg.mm.status().Preads
+	fe.getData("m/b")
+	if fe.getData("m/b"); mapped(b) || !mapped(a) || dataEg.mm.status().Preads != preads+2 {
+		t.Fatalf("%+v", dataEg.mm.status())
+	}
+	// the map dropped while it is read is unmapped by its reader
+	unmaps := dataEg.mm.status().Unmaps
+	dataEg.unMmap(a)
+	if mapped(a) || dataEg.mm.status().Unmaps != unmaps {
+		t.Fatal("the map is unmapped while it is read")
+	}
+	rest, err := io.ReadAll(db.Reader)
+	if db.Close(); err != nil || !bytes.Equal(append(half, rest...), data) {
+		t.Fatal("data read beside the eviction", err)
+	}
+	if dataEg.mm.status().Unmaps != unmaps+1 {
+		t.Fatal("the map is not unmapped by its reader")
+	}
+	fe.getData("m/b")
+	if fe.getData("m/b"); !mapped(b) {
+		t.Fatal("node is not mapped in the room of the evicted one")
+	}
+}
+
+func TestMmapIdleEvict(t *testing.T) {
+	fileSize, mmapNodes := sys.FileSize, sys.MmapNodes
+	defer func() { sys.FileSize, sys.MmapNodes = fileSize, mmapNodes }()
+	sys.FileSize, sys.MmapNodes = 1<<16, 2
+	startStore(t.TempDir())
+	defer CloseAll()
+	var nodes []string
+	for i := 0; i < 3; i++ {
+		fe.append(fmt.Sprint("m/", i), []byte(fmt.Sprint("the data of m/", i)), 0, nil)
+		nodes = append(nodes, fe.handler.Node)
+		fe.next(fe.handler.Node)
+		dataEg.unMmap(nodes[i])
+	}
+	fe.getData("m/0")
+	fe.getData("m/0")
+	// a map in use within mmapIdle is kept, an idle one is evicted by the least recently used first
+	fe.getData("m/1")
+	if fe.getData("m/1"); mapped(nodes[1]) || !mapped(nodes[0]) {
+		t.Fatal("a map in use is evicted")
+	}
+	idle(nodes[0])
+	if fe.getData("m/1"); !mapped(nodes[1]) || mapped(nodes[0]) {
+		t.Fatal("the idle map is not evicted")
+	}
+	// the maps over the bounds are swept when they are idle
+	sys.MmapNodes = 1
+	dataEg.mm.sweep()
+	if !mapped(nodes[1]) {
+		t.Fatal("a map in use is swept")
+	}
+	idle(nodes[1])
+	if dataEg.mm.sweep(); mapped(nodes[1]) || !mapped(fe.handler.Node) {
+		t.Fatal("the idle map is not swept")
+	}
+	for i := range nodes {
+		if string(fe.getData(fmt.Sprint("m/", i))) != fmt.Sprint("the data of m/", i) {
+			t.Fatal("data of m/", i)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/donnie4w/gofer/lock"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+const maxPartNumber = 10000
+
+var lockUpload = lock.NewNumLock(1 << 6)
+
+func init() {
+	sys.UploadInit = fe.uploadInit
+	sys.UploadPart = fe.uploadPart
+	sys.UploadComplete = fe.uploadComplete
+	sys.UploadAbort = fe.uploadAbort
+}
+
+func uploadKey(id int64) []byte {
+	return append(UPLOAD_, goutil.Int64ToBytes(id)...)
+}
+
+func bytesToWfsUploadBean(bs []byte) (wub *stub.WfsUploadBean) {
+	wub = &stub.WfsUploadBean{}
+	if util.PDecode(bs, wub) != nil {
+		wub = nil
+	}
+	return
+}
+
+func wfsUploadBeanToBytes(b *stub.WfsUploadBean) (bs []byte) {
+	bs, _ = util.PEncode(b)
+	return
+}
+
+func getUploadBean(id int64) (wub *stub.WfsUploadBean) {
+	if v, err := wfsdb.Get(uploadKey(id)); err == nil && v != nil {
+		wub = bytesToWfsUploadBean(v)
+	}
+	return
+}
+
+// uploadInit opens an upload session of path, the parts are stored with compressType and mb is bound to path on completion
+func (t *fileEg) uploadInit(path string, compressType int32, mb *sys.MetaBean) (id int64, _r sys.ERROR) {
+	if stopstat {
+		return id, sys.ERR_STOPSERVICE
+	}
+	if path == "" {
+		return id, sys.ERR_PARAMS
+	}
+	defer util.Recover()
+	if id = goutil.UUID64(); id < 0 {
+		id = -id
+	}
+	timestramp := time.Now().UnixNano()
+	wub := &stub.WfsUploadBean{Path: &path, Timestramp: &timestramp, CompressType: &compressType}
+	if mb != nil {
+		if mb.ContentType != "" {
+			wub.ContentType = &mb.ContentType
+		}
+		wub.Meta = mb.Meta
+		if mb.Expire > 0 {
+			wub.Expire = &mb.Expire
+		}
+	}
+	if err := wfsdb.Put(uploadKey(id), wfsUploadBeanToBytes(wub)); err != nil {
+		return 0, sys.ERR_UNDEFINED
+	}
+	return
+}
+
+// uploadPart stores one part of the session, a part with the same number is replaced. The time of the session
+// becomes the one of its last part.
+func (t *fileEg) uploadPart(id int64, number int32, bs []byte) (_r sys.ERROR) {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if number < 1 || number > maxPartNumber || len(bs) == 0 {
+		return sys.ERR_PARAMS
+	}
+	if int64(len(bs)) > sys.DataMaxsize {
+		return sys.ERR_OVERSIZE
+	}
+	defer util.Recover()
+	lockUpload.Lock(id)
+	defer lockUpload.Unlock(id)
+
+	wub := getUploadBean(id)
+	if wub == nil {
+		return sys.ERR_NOTEXSIT
+	}
+	bidBs, err := t.appendBlock(wub.GetPath(), bs, wub.GetCompressType())
+	if err != nil {
+		return err
+	}
+	timestramp := time.Now().UnixNano()
+	wub.Timestramp = &timestramp
+	size := int64(len(bs))
+	part := &stub.WfsPartBean{Number: &number, Fingerprint: bidBs, Size: &size}
+	bat := newBatch()
+	i := sort.Search(len(wub.Parts), func(i int) bool { return wub.Parts[i].GetNumber() >= number })
+	if i < len(wub.Parts) && wub.Parts[i].GetNumber() == number {
+		bat.unrefer(wub.Parts[i].Fingerprint)
+		wub.Parts[i] = part
+	} else {
+		wub.Parts = append(wub.Parts, nil)
+		copy(wub.Parts[i+1:], wub.Parts[i:])
+		wub.Parts[i] = part
+	}
+	bat.put(uploadKey(id), wfsUploadBeanToBytes(wub))
+	if bat.commit() != nil {
+		return sys.ERR_UNDEFINED
+	}
+	return
+}
+
+// uploadComplete binds the parts of the session to its path in the order of the part numbers
+func (t *fileEg) uploadComplete(id int64) (seqid int64, _r sys.ERROR) {
+	if stopstat {
+		return seqid, sys.ERR_STOPSERVICE
+	}
+	defer util.Recover()
+	lockUpload.Lock(id)
+	defer lockUpload.Unlock(id)
+
+	wub := getUploadBean(id)
+	if wub == nil {
+		return seqid, sys.ERR_NOTEXSIT
+	}
+	if len(wub.Parts) == 0 {
+		return seqid, sys.ERR_PARAMS
+	}
+	path := wub.GetPath()
+	lockid := goutil.Hash64(append(APPENDLOCK_, []byte(path)...))
+	lockLevel1.Lock(int64(lockid))
+	defer lockLevel1.Unlock(int64(lockid))
+
+	if expired(path) {
+		t.remove(path)
+	}
+
+	var buf bytes.Buffer
+	var size int64
+	buf.Write(MANIFEST_)
+	for _, p := range wub.Parts {
+		buf.Write(p.Fingerprint)
+		size += p.GetSize()
+	}
+	fidBs := pathKey(path)
+	midBs := fingerprint(buf.Bytes())
+
+	mlockid := goutil.Hash64(append(APPENDLOCK_, midBs...))
+	lockLevel2.Lock(int64(mlockid))
+	defer lockLevel2.Unlock(int64(mlockid))
+
+	if midBs, _, _r = dedupKey(midBs, sameManifest(wub.Parts)); _r != nil {
+		return
+	}
+
+	bat := newBatch()
+	var wfbbs []byte
+	if v, err := wfsdb.Get(midBs); err == nil && v != nil {
+		wfbbs = v
+		for _, p := range wub.Parts {
+			bat.unrefer(p.Fingerprint)
+		}
+	} else {
+		refer, compressType := int32(1), wub.GetCompressType()
+		wfb := &stub.WfsFileBean{Size: &size, Datasize: &size, CompressType: &compressType, Refercount: &refer, Parts: wub.Parts}
+		bat.put(midBs, wfsFileBeanToBytes(wfb))
+	}
+	bat.del(uploadKey(id))
+	nf, err := bat.bindPath(fidBs, midBs, wfbbs)
+	if err != nil && !err.Equal(sys.ERR_EXSIT) {
+		return seqid, err
+	}
+	if bat.commit() != nil {
+		return seqid, sys.ERR_UNDEFINED
+	}
+	if err != nil {
+		return seqid, err
+	}
+	cachePut(fidBs, midBs)
+	if nf {
+		mb := &sys.MetaBean{ContentType: wub.GetContentType(), Meta: wub.Meta, Expire: wub.GetExpire()}
+		expire := expireOf(path, mb)
+		putExpire(path, expire)
+		if sys.Mode == 1 {
+			seqid = putPathIndex(path, mb)
+		}
+		journalBind(path, midBs, wub.Parts, wub.GetCompressType(), journalMeta(mb, expire))
+	} else {
+		journalBind(path, midBs, wub.Parts, wub.GetCompressType(), nil)
+	}
+	return
+}
+
+// uploadAbort releases the stored parts and drops the session
+func (t *fileEg) uploadAbort(id int64) (_r sys.ERROR) {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	defer util.Recover()
+	lockUpload.Lock(id)
+	defer lockUpload.Unlock(id)
+
+	wub := getUploadBean(id)
+	if wub == nil {
+		return sys.ERR_NOTEXSIT
+	}
+	return dropUpload(id, wub)
+}
+
+func dropUpload(id int64, wub *stub.WfsUploadBean) (_r sys.ERROR) {
+	bat := newBatch()
+	for _, p := range wub.Parts {
+		bat.unrefer(p.Fingerprint)
+	}
+	bat.del(uploadKey(id))
+	if bat.commit() != nil {
+		return sys.ERR_UNDEFINED
+	}
+	return
+}
+
+// sweepUploads aborts the sessions without a part for sys.UploadTTL seconds, so that their parts are released
+func sweepUploads() {
+	defer util.Recover()
+	start := UPLOAD_
+	for !stopstat {
+		keys, err := wfsdb.GetKeysPrefixLimit(UPLOAD_, start, maxExpireSweep)
+		if err != nil {
+			return
+		}
+		deadline := time.Now().UnixNano() - sys.UploadTTL*int64(time.Second)
+		for _, k := range keys {
+			if stopstat {
+				return
+			}
+			if len(k) == len(UPLOAD_)+8 {
+				abortIdle(goutil.BytesToInt64(k[len(UPLOAD_):]), deadline)
+			}
+			start = append(bytes.Clone(k), 0)
+		}
+		if len(keys) < maxExpireSweep {
+			return
+		}
+	}
+}
+
+// abortIdle aborts the session id if its last part is before deadline
+func abortIdle(id, deadline int64) {
+	lockUpload.Lock(id)
+	defer lockUpload.Unlock(id)
+	if wub := getUploadBean(id); wub != nil && wub.GetTimestramp() < deadline {
+		dropUpload(id, wub)
+	}
+}
+
+// appendParts stores bs of any size as a multipart file, used when bs exceeds DataMaxsize
+func (t *fileEg) appendParts(path string, bs []byte, compressType int32, mb *sys.MetaBean) (seqid int64, _r sys.ERROR) {
+	var id int64
+	if id, _r = t.uploadInit(path, compressType, mb); _r != nil {
+		return
+	}
+	for i := int32(1); len(bs) > 0; i++ {
+		n := min(int64(len(bs)), sys.DataMaxsize)
+		if _r = t.uploadPart(id, i, bs[:n]); _r != nil {
+			t.uploadAbort(id)
+			return
+		}
+		bs = bs[n:]
+	}
+	return t.uploadComplete(id)
+}
+
+func readPart(p *stub.WfsPartBean) (_r []byte) {
+	if quarantined(p.Fingerprint) {
+		return
+	}
+	if wfbbs, err := cacheGet(p.Fingerprint); err == nil && wfbbs != nil {
+		if wfb := bytesToWfsFileBean(wfbbs); wfb != nil && wfb.Storenode != nil {
+			_r = readBlock(p.Fingerprint, wfb)
+		}
+	}
+	return
+}
+
+// readParts reads a multipart file at once, it is nil if the file is larger than data.maxsize: such a file is read
+// by partsReader, which holds one part at a time
+func readParts(wfb *stub.WfsFileBean) (_r []byte) {
+	if wfb.GetSize() > sys.DataMaxsize {
+		return
+	}
+	_r = make([]byte, 0, wfb.GetSize())
+	for _, p := range wfb.Parts {
+		bs := readPart(p)
+		if bs == nil {
+			return nil
+		}
+		_r = append(_r, bs...)
+	}
+	return
+}
+
+// partsReader reads a multipart file one part at a time
+type partsReader struct {
+	parts  []*stub.WfsPartBean
+	ends   []int64
+	offset int64
+	index  int
+	cur    []byte
+}
+
+func newPartsReader(parts []*stub.WfsPartBean) *partsReader {
+	ends := make([]int64, len(parts))
+	var end int64
+	for i, p := range parts {
+		end += p.GetSize()
+		ends[i] = end
+	}
+	return &partsReader{parts: parts, ends: ends, index: -1}
+}
+
+func (t *partsReader) size() int64 {
+	if len(t.ends) == 0 {
+		return 0
+	}
+	return t.ends[len(t.ends)-1]
+}
+
+func (t *partsReader) Read(p []byte) (n int, err error) {
+	if t.offset >= t.size() {
+		return 0, io.EOF
+	}
+	i := sort.Search(len(t.ends), func(i int) bool { return t.ends[i] > t.offset })
+	if i != t.index {
+		bs := readPart(t.parts[i])
+		if int64(len(bs)) != t.parts[i].GetSize() {
+			return 0, io.ErrUnexpectedEOF
+		}
+		t.index, t.cur = i, bs
+	}
+	start := t.ends[i] - t.parts[i].GetSize()
+	n = copy(p, t.cur[t.offset-start:])
+	t.offset += int64(n)
+	return
+}
+
+func (t *partsReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += t.offset
+	case io.SeekEnd:
+		offset += t.size()
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	t.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+func TestSweepUploads(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	idle, _ := fe.uploadInit("u/idle", 0, nil)
+	active, _ := fe.uploadInit("u/active", 0, nil)
+	fe.uploadPart(idle, 1, []byte("the part of u/idle"))
+	fe.uploadPart(active, 1, []byte("the part of u/active"))
+	// the last part of the idle session is older than the time to live of the sessions
+	wub := getUploadBean(idle)
+	timestramp := time.Now().UnixNano() - 2*sys.UploadTTL*int64(time.Second)
+	wub.Timestramp = &timestramp
+	wfsdb.Put(uploadKey(idle), wfsUploadBeanToBytes(wub))
+	sweepUploads()
+	if getUploadBean(idle) != nil {
+		t.Fatal("the idle upload is not aborted")
+	}
+	if _, err := fe.uploadComplete(active); err != nil {
+		t.Fatal("the active upload is aborted:", err)
+	}
+	if string(fe.getData("u/active")) != "the part of u/active" || fe.has("u/idle") {
+		t.Fatal("data of the uploads after the sweep")
+	}
+	consistent(t)
+}
+
+func TestReadLargeParts(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	maxsize := sys.DataMaxsize
+	defer func() { sys.DataMaxsize = maxsize }()
+	sys.DataMaxsize = 1 << 10
+	data := bytes.Repeat([]byte("the data of a multipart file larger than data.maxsize "), 100)
+	if _, err := fe.appendParts("p/large", data, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	// the file is not read at once, it is listed with its size and read by its reader
+	if fe.getData("p/large") != nil {
+		t.Fatal("a multipart file larger than data.maxsize is read at once")
+	}
+	if pbs := fe.findLike("p/"); len(pbs) != 1 || pbs[0].Size != int64(len(data)) || pbs[0].Body != nil {
+		t.Fatal("multipart file in the list:", pbs)
+	}
+	db := fe.getReader("p/large")
+	if db == nil {
+		t.Fatal("no reader of the multipart file")
+	}
+	got, err := io.ReadAll(db.Reader)
+	if db.Close(); err != nil || !bytes.Equal(got, data) {
+		t.Fatal("the reader reads", len(got), err)
+	}
+	if !bytes.Equal(fileData("p/large"), data) {
+		t.Fatal("the multipart file is not exported whole")
+	}
+	consistent(t)
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/andybalholm/brotli"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+func init() {
+	sys.GetReader = fe.getReader
+	sys.GetStored = fe.getStored
+}
+
+// getReader returns the data bean of path whose Reader streams the data instead of reading it at once, the bean
+// is closed by the caller
+func (t *fileEg) getReader(path string) (_r *sys.DataBean) {
+	if stopstat {
+		return nil
+	}
+	defer util.Recover()
+	tasklimit()
+	if bidBs, wfb := t.getFileBean(path); wfb != nil && !quarantined(bidBs) {
+		if len(wfb.Parts) > 0 {
+			_r = &sys.DataBean{Reader: newPartsReader(wfb.Parts), Size: wfb.GetSize(), Fingerprint: bidBs}
+		} else if br := newBlockReader(bidBs, wfb, false); br != nil {
+			_r = &sys.DataBean{Reader: br, Size: br.size, Fingerprint: bidBs}
+		}
+		if wpb := getPathBean(path); _r != nil && wpb != nil {
+			_r.Timestramp, _r.ContentType, _r.Meta = wpb.GetTimestramp(), wpb.GetContentType(), wpb.GetMeta()
+		}
+	}
+	return
+}
+
+// getStored returns the data bean of path whose Reader reads the stored bytes of its block as they are, with the
+// Content-Encoding of its codec. It is nil unless the block is compressed by zlib or zstd without dictionary.
+func (t *fileEg) getStored(path string) (_r *sys.DataBean) {
+	if stopstat {
+		return nil
+	}
+	defer util.Recover()
+	tasklimit()
+	if bidBs, wfb := t.getFileBean(path); wfb != nil && !quarantined(bidBs) && len(wfb.Parts) == 0 && wfb.GetDict() == 0 && wfb.Datasize != nil {
+		if encoding := contentEncoding(wfb.GetCompressType()); encoding != "" {
+			if br := newBlockReader(bidBs, wfb, true); br != nil {
+				if encoding == "deflate" && !zlibClosed(br.stored) {
+					br.Close()
+					return
+				}
+				_r = &sys.DataBean{Reader: br, Size: br.size, Fingerprint: bidBs, Encoding: encoding}
+				if wpb := getPathBean(path); wpb != nil {
+					_r.Timestramp, _r.ContentType, _r.Meta = wpb.GetTimestramp(), wpb.GetContentType(), wpb.GetMeta()
+				}
+			}
+		}
+	}
+	return
+}
+
+// contentEncoding is the http Content-Encoding of the stored bytes of compressType, the zlib stream is deflate
+func contentEncoding(compressType int32) string {
+	switch {
+	case compressType == 2 || compressType >= COMPRESS_ZSTD_FASTEST && compressType <= COMPRESS_ZSTD_BEST:
+		return "zstd"
+	case compressType >= 3 && compressType <= 11:
+		return "deflate"
+	}
+	return ""
+}
+
+// zlibClosed tells if the zlib stream ends with its checksum. The streams written before the stream was closed
+// end with the marker of a flush instead, which is never a checksum as its sum of bytes is over the modulus.
+func zlibClosed(sr *io.SectionReader) bool {
+	tail := make([]byte, 4)
+	if _, err := sr.ReadAt(tail, sr.Size()-4); err == nil {
+		return !bytes.Equal(tail, []byte{0, 0, 0xff, 0xff})
+	}
+	return false
+}
+
+// section returns a reader of the size bytes at offset of node, from its map held until done is called, or from
+// its file if it is not mapped
+func (t *dataHandler) section(node string, offset int64, size int64) (sr *io.SectionReader, done func(), ok bool) {
+	touchNode(node)
+	if s := nodeShards(node); s != nil {
+		return io.NewSectionReader(s, offset, size), func() {}, offset >= 0 && size >= 0 && offset+size <= s.size
+	}
+	id, b := strToInt(node)
+	if !b {
+		return
+	}
+	if _, unmount := unmountmap.Load(id); unmount {
+		return
+	}
+	if !t.mm.has(id) {
+		t.openMMap(node)
+	}
+	if m := t.mm.acquire(id); m != nil {
+		if offset+size <= int64(len(m.mm.Bytes())) {
+			return io.NewSectionReader(bytes.NewReader(m.mm.Bytes()[offset:offset+size]), 0, size), func() { t.mm.release(m) }, true
+		}
+		t.mm.release(m)
+		return
+	}
+	if f, err := os.Open(getpathBynode(node)); err == nil {
+		if fi, err := f.Stat(); err == nil && offset+size <= fi.Size() {
+			atomic.AddInt64(&t.mm.preads, 1)
+			return io.NewSectionReader(f, offset, size), func() { f.Close() }, true
+		}
+		f.Close()
+	}
+	return
+}
+
+// blockReader reads the data of a block from the stored bytes in its node. The stored bytes of a block without
+// compression are read as they are, the others through the decompressor of their compress type. The blocks of
+// an encrypted node, of snappy, of a dictionary or without recorded datasize are decompressed at once.
+type blockReader struct {
+	wfb    *stub.WfsFileBean
+	size   int64
+	stored *io.SectionReader
+	done   func()
+	r      io.Reader
+	closer func()
+	pos    int64
+	offset int64
+	// bean is the bean of the block read by its stored bytes, it lets Decode read its data
+	bean *stub.WfsFileBean
+}
+
+// newBlockReader returns the reader of the block of bidBs, of its stored bytes if stored is true. The bean is read
+// again if the block has moved.
+func newBlockReader(bidBs []byte, wfb *stub.WfsFileBean, stored bool) (br *blockReader) {
+	if br = openBlockReader(wfb, stored); br == nil {
+		if v, err := wfsdb.Get(bidBs); err == nil && len(v) > 0 {
+			if nwfb := bytesToWfsFileBean(v); nwfb != nil && nwfb.Storenode != nil && (nwfb.GetStorenode() != wfb.GetStorenode() || nwfb.GetOffset() != wfb.GetOffset()) {
+				cachePut(bidBs, v)
+				br = openBlockReader(nwfb, stored)
+			}
+		}
+	}
+	return
+}
+
+func openBlockReader(wfb *stub.WfsFileBean, stored bool) *blockReader {
+	node, hd := wfb.GetStorenode(), nodeOffset(wfb.GetStorenode())
+	sr, done, ok := dataEg.section(node, wfb.GetOffset(), hd+wfb.GetSize())
+	if !ok {
+		return nil
+	}
+	br := &blockReader{wfb: wfb, stored: io.NewSectionReader(sr, hd, wfb.GetSize()), done: done}
+	if nk := nodeSecret(node); nk.aead != nil || len(nk.wrapped) > 0 {
+		bs := make([]byte, hd+wfb.GetSize())
+		if _, err := sr.ReadAt(bs, 0); err != nil {
+			br.Close()
+			return nil
+		}
+		payload := openBlock(node, bs[:hd-4], bs[hd:])
+		br.Close()
+		if payload == nil {
+			return nil
+		}
+		br.stored, br.done = io.NewSectionReader(bytes.NewReader(payload), 0, int64(len(payload))), nil
+	}
+	if stored {
+		br.bean, br.wfb = wfb, &stub.WfsFileBean{}
+	} else if wfb.GetDict() != 0 || wfb.GetCompressType() == 1 || (wfb.Datasize == nil && wfb.GetCompressType() != 0) {
+		bs := make([]byte, br.stored.Size())
+		if _, err := br.stored.ReadAt(bs, 0); err != nil {
+			br.Close()
+			return nil
+		}
+		data := uncompressBean(bs, wfb)
+		if br.Close(); data == nil {
+			return nil
+		}
+		br.stored, br.done, br.wfb = io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))), nil, &stub.WfsFileBean{}
+	}
+	if br.size = wfb.GetDatasize(); stored || wfb.Datasize == nil {
+		br.size = br.stored.Size()
+	}
+	if br.reset() != nil {
+		br.Close()
+		return nil
+	}
+	return br
+}
+
+// reset starts the decompression of the stored bytes from the beginning
+func (t *blockReader) reset() (err error) {
+	if t.closer != nil {
+		t.closer()
+		t.closer = nil
+	}
+	t.pos = 0
+	t.stored.Seek(0, io.SeekStart)
+	switch ct := t.wfb.GetCompressType(); {
+	case ct == 2 || ct >= COMPRESS_ZSTD_FASTEST && ct <= COMPRESS_ZSTD_BEST:
+		var d *zstd.Decoder
+		if d, err = zstd.NewReader(t.stored, zstd.WithDecoderConcurrency(1)); err == nil {
+			t.r, t.closer = d, d.Close
+		}
+	case ct >= 3 && ct <= 11:
+		var zr io.ReadCloser
+		if zr, err = zlib.NewReader(t.stored); err == nil {
+			t.r, t.closer = &zlibTail{zr}, func() { zr.Close() }
+		}
+	case ct == COMPRESS_LZ4:
+		t.r = lz4.NewReader(t.stored)
+	case ct >= COMPRESS_BROTLI && ct <= COMPRESS_BROTLI_MAX:
+		t.r = brotli.NewReader(t.stored)
+	default:
+		t.r = t.stored
+	}
+	return
+}
+
+// Decode turns the reader of the stored bytes into the reader of the data of the block, from its beginning. It
+// returns the size of the data.
+func (t *blockReader) Decode() (int64, error) {
+	if t.bean == nil || t.r == nil {
+		return 0, errors.New("not a reader of stored bytes")
+	}
+	t.wfb, t.size, t.offset, t.bean = t.bean, t.bean.GetDatasize(), 0, nil
+	return t.size, t.reset()
+}
+
+func (t *blockReader) Read(p []byte) (n int, err error) {
+	if t.r == nil {
+		return 0, os.ErrClosed
+	}
+	if t.offset != t.pos {
+		if err = t.skip(); err != nil {
+			return
+		}
+	}
+	if t.pos >= t.size {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > t.size-t.pos {
+		p = p[:t.size-t.pos]
+	}
+	n, err = t.r.Read(p)
+	t.pos += int64(n)
+	t.offset = t.pos
+	if err == io.EOF && t.pos < t.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+// skip moves the decompression to the offset, backwards from the beginning
+func (t *blockReader) skip() (err error) {
+	if t.r == io.Reader(t.stored) {
+		t.pos, err = t.stored.Seek(t.offset, io.SeekStart)
+		return
+	}
+	if t.offset < t.pos {
+		if err = t.reset(); err != nil {
+			return
+		}
+	}
+	var n int64
+	n, err = io.CopyN(io.Discard, t.r, t.offset-t.pos)
+	if t.pos += n; err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func (t *blockReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += t.offset
+	case io.SeekEnd:
+		offset += t.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	t.offset = offset
+	return offset, nil
+}
+
+// Close gives back the map of the node the block is read from
+func (t *blockReader) Close() error {
+	if t.closer != nil {
+		t.closer()
+		t.closer = nil
+	}
+	if t.done != nil {
+		t.done()
+		t.done = nil
+	}
+	t.r = nil
+	return nil
+}
+
+// zlibTail ends the zlib streams written before the stream was closed at their data, as zlibUncompress does
+type zlibTail struct {
+	r io.Reader
+}
+
+func (t *zlibTail) Read(p []byte) (n int, err error) {
+	if n, err = t.r.Read(p); errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/donnie4w/gofer/compress"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestGetReader(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	for _, ct := range []int32{0, 1, 2, 3, 11, COMPRESS_LZ4, COMPRESS_ZSTD_BEST, COMPRESS_BROTLI} {
+		path := fmt.Sprint("r/", ct)
+		data := bytes.Repeat([]byte("the streamed data of "+path+" "), 4096)
+		if _, err := fe.append(path, data, ct, nil); err != nil {
+			t.Fatal(err)
+		}
+		db := fe.getReader(path)
+		if sb := fe.stat(path); sb == nil || sb.CompressType != ct {
+			t.Fatalf("stat of compress type %d: %v", ct, sb)
+		}
+		if db == nil || db.Size != int64(len(data)) {
+			t.Fatalf("reader of compress type %d: %v", ct, db)
+		}
+		if got, err := io.ReadAll(db.Reader); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("data of compress type %d: %d bytes, %v", ct, len(got), err)
+		}
+		for _, offset := range []int64{int64(len(data)) / 2, 7, int64(len(data)) - 3} {
+			db.Reader.Seek(offset, io.SeekStart)
+			p := make([]byte, 3)
+			if _, err := io.ReadFull(db.Reader, p); err != nil || !bytes.Equal(p, data[offset:offset+3]) {
+				t.Fatalf("data of compress type %d at %d: %q, %v", ct, offset, p, err)
+			}
+		}
+		db.Close()
+		if _, err := db.Reader.Read(make([]byte, 1)); err == nil {
+			t.Fatalf("reader of compress type %d is read after close", ct)
+		}
+	}
+	if fe.getReader("r/none") != nil {
+		t.Fatal("reader of a missing path")
+	}
+}
+
+func TestGetStored(t *testing.T) {
+	startStore(t.TempDir())
+	defer CloseAll()
+	for ct, encoding := range map[int32]string{0: "", 1: "", 2: "zstd", 3: "deflate", 11: "deflate", COMPRESS_LZ4: "", COMPRESS_ZSTD_FASTEST: "zstd"} {
+		path := fmt.Sprint("s/", ct)
+		data := bytes.Repeat([]byte("the stored data of "+path+" "), 1024)
+		if _, err := fe.append(path, data, ct, nil); err != nil {
+			t.Fatal(err)
+		}
+		db := fe.getStored(path)
+		if encoding == "" {
+			if db != nil {
+				t.Fatalf("stored bytes of compress type %d are served as %s", ct, db.Encoding)
+			}
+			continue
+		}
+		if db == nil || db.Encoding != encoding {
+			t.Fatalf("stored bytes of compress type %d: %v", ct, db)
+		}
+		stored, _ := io.ReadAll(db.Reader)
+		db.Close()
+		var r io.Reader
+		if encoding == "deflate" {
+			r, _ = zlib.NewReader(bytes.NewReader(stored))
+		} else {
+			d, _ := zstd.NewReader(bytes.NewReader(stored))
+			defer d.Close()
+			r = d
+		}
+		if got, err := io.ReadAll(r); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("stored bytes of compress type %d decode to %d bytes, %v", ct, len(got), err)
+		}
+		if db = fe.getStored(path); !db.Decode() || db.Encoding != "" || db.Size != int64(len(data)) {
+			t.Fatalf("stored bytes of compress type %d are not decoded by their reader", ct)
+		}
+		db.Reader.Seek(100, io.SeekStart)
+		got, err := io.ReadAll(db.Reader)
+		if db.Close(); err != nil || !bytes.Equal(got, data[100:]) {
+			t.Fatalf("decoded reader of compress type %d reads %d bytes, %v", ct, len(got), err)
+		}
+	}
+	flushed, _ := compress.ZlibLevel([]byte("the data of a flushed stream"), 6)
+	if closed := zlibCompress([]byte("the data of a closed stream"), 6); !zlibClosed(io.NewSectionReader(bytes.NewReader(closed), 0, int64(len(closed)))) || zlibClosed(io.NewSectionReader(bytes.NewReader(flushed), 0, int64(len(flushed)))) {
+		t.Fatal("the end of the zlib streams is not told")
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+func init() {
+	sys.Rebuild = rebuild
+}
+
+// rebuild recreates the metadata database of a stopped data directory from the node files and the path journal.
+// The blocks of the node files give the file beans, the end offsets and the node beans; the journal gives
+// the paths. The database must be empty, so the lost database is moved away before.
+func rebuild() (err error) {
+	if !goutil.IsFileExist(sys.WFSDATA + "/wfsfile") {
+		return errors.New("wfs node files do not exist:" + sys.WFSDATA + "/wfsfile")
+	}
+	if err = openDB(); err != nil {
+		return errors.New("the wfs data cannot be opened, the service must be stopped:" + err.Error())
+	}
+	defer CloseAll()
+	empty := true
+	wfsdb.SnapshotToStream(nil, func(bean *stub.SnapshotBean) bool {
+		empty = false
+		return false
+	})
+	if !empty {
+		return errors.New("the metadata database is not empty, move it away before rebuild")
+	}
+	rb := &rebuilder{beans: make(map[string]*stub.WfsFileBean), refers: make(map[string]int32), rmsize: make(map[string]int64), hashes: make(map[string]int32), keys: make(map[string][]byte), shards: make(map[string]*shardSet), puts: make(map[string][]byte)}
+	rb.loadDicts()
+	if err = rb.scan(); err != nil {
+		return
+	}
+	if err = rb.replay(); err != nil {
+		return
+	}
+	rb.finish()
+	if err = writeBatch(rb.puts, nil); err == nil {
+		fmt.Println("nodes:", len(rb.rmsize), ", blocks:", len(rb.beans)-rb.manifests-rb.unreferenced, ", corrupt blocks:", rb.corrupt, ", paths:", rb.count, ", lost paths:", rb.lost, ", unreferenced blocks:", rb.unreferenced)
+	}
+	return
+}
+
+type rebuilder struct {
+	beans        map[string]*stub.WfsFileBean
+	refers       map[string]int32
+	rmsize       map[string]int64
+	hashes       map[string]int32
+	keys         map[string][]byte
+	shards       map[string]*shardSet
+	puts         map[string][]byte
+	manifests    int
+	corrupt      int
+	lost         int
+	unreferenced int
+	count        int64
+	seq          int64
+}
+
+// loadDicts reads the dictionaries kept in the dictionary directory, the blocks compressed with them refer to their ids
+func (t *rebuilder) loadDicts() {
+	entries, _ := os.ReadDir(dictDir())
+	for _, e := range entries {
+		if bs, err := os.ReadFile(dictDir() + "/" + e.Name()); err == nil {
+			if wdb := bytesToWfsDictBean(bs); wdb != nil && useDict(wdb) != nil {
+				t.puts[string(dictKey(wdb.GetId()))] = bs
+			}
+		}
+	}
+}
+
+// scan walks the blocks of every node file in the data directories, and of every erasure coded node whose
+// shards are found, a block is kept if one of the compress types gives back the data of its fingerprint
+func (t *rebuilder) scan() (err error) {
+	for _, dir := range sys.FileDirs() {
+		entries, e := os.ReadDir(dir)
+		if e != nil {
+			continue
+		}
+		for _, e := range entries {
+			if id, ok := strToInt(e.Name()); ok && !e.IsDir() && !strings.Contains(e.Name(), "_") && util.CheckNodeId(int64(id)) {
+				if _, ok := t.hashes[e.Name()]; ok {
+					fmt.Println("node file in several directories:", e.Name())
+					continue
+				}
+				nodeDirs.Store(e.Name(), dir)
+				f, err := os.Open(getpathBynode(e.Name()))
+				if err != nil {
+					return err
+				}
+				fi, _ := f.Stat()
+				err = t.scanNode(e.Name(), f, fi.Size())
+				f.Close()
+				if err != nil {
+					return err
+				}
+			} else if !e.IsDir() {
+				t.findShard(dir, e.Name())
+			}
+		}
+	}
+	for node, s := range t.shards {
+		if _, ok := t.hashes[node]; ok {
+			fmt.Println("node file beside its shards:", node)
+			delete(t.shards, node)
+			continue
+		}
+		found := 0
+		for _, dir := range s.dirs {
+			if dir != "" {
+				found++
+			}
+		}
+		if found >= int(s.data) {
+			err = t.scanNode(node, s, s.size)
+			s.Close()
+			if err != nil {
+				return
+			}
+		} else {
+			fmt.Println("lost erasure coded node:", node)
+			delete(t.shards, node)
+		}
+	}
+	return
+}
+
+// findShard adds the shard file name in dir to the shards of its node by its footer
+func (t *rebuilder) findShard(dir, name string) {
+	node, index, ok := strings.Cut(name, ".")
+	if !ok {
+		return
+	}
+	id, ok := strToInt(node)
+	i, err := strconv.Atoi(index)
+	if !ok || err != nil || !util.CheckNodeId(int64(id)) {
+		return
+	}
+	index32, data, parity, size, ok := readShardFooter(dir + "/" + name)
+	if !ok || int(index32) != i {
+		fmt.Println("invalid shard file:", dir+"/"+name)
+		return
+	}
+	s := t.shards[node]
+	if s == nil {
+		if s, err = newShardSet(node, data, size, make([]string, data+parity)); err != nil {
+			return
+		}
+		t.shards[node] = s
+	}
+	if s.data != data || int32(len(s.dirs)) != data+parity || s.size != size || s.dirs[i] != "" {
+		fmt.Println("shard file does not agree with the other shards:", dir+"/"+name)
+		return
+	}
+	s.dirs[i] = dir
+}
+
+func (t *rebuilder) scanNode(node string, f io.ReaderAt, length int64) (err error) {
+	if key, e := os.ReadFile(keyFile(node)); e == nil {
+		t.keys[node] = key
+		if _, e = useNodeKey(node, key); e != nil {
+			fmt.Println("data key cannot be unwrapped:", node, e)
+		}
+	}
+	hash := probeHash(node, f, length)
+	t.hashes[node] = hash
+	step := int64(hashLen(hash))
+	hd, zero := make([]byte, step+4), make([]byte, step)
+	var end int64
+	for end+step+4 <= length {
+		if _, err = f.ReadAt(hd, end); err != nil {
+			return
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size == 0 && bytes.Equal(hd[:step], zero) {
+			break
+		}
+		if size <= 0 || end+step+4+size > length {
+			fmt.Println("truncated block:", node, end)
+			break
+		}
+		bs := make([]byte, size)
+		if _, err = f.ReadAt(bs, end+step+4); err != nil {
+			return
+		}
+		bidBs := bytes.Clone(hd[:step])
+		if compressType, chain, dict, data := detectBlock(openBlock(node, bidBs, bs), bidBs); data == nil {
+			t.corrupt++
+			t.rmsize[node] += step + 4 + size
+		} else if _, ok := t.beans[string(bidBs)]; ok {
+			t.rmsize[node] += step + 4 + size
+		} else {
+			offset, datasize := end, int64(len(data))
+			wfb := &stub.WfsFileBean{Storenode: &node, Offset: &offset, Size: &size, Datasize: &datasize, CompressType: &compressType}
+			if sys.DedupVerify {
+				wfb.Digest = digest(data)
+			}
+			if chain > 0 {
+				wfb.Chain = &chain
+			}
+			if dict != 0 {
+				wfb.Dict = &dict
+			}
+			t.beans[string(bidBs)] = wfb
+		}
+		end += step + 4 + size
+	}
+	if end > 0 {
+		t.puts[string(append(ENDOFFSET_, nodeBytes(node)...))] = goutil.Int64ToBytes(end)
+		t.rmsize[node] += 0
+	}
+	return nil
+}
+
+// probeHash finds the hash algorithm of the block headers of a node file by the first block
+// whose data gives back its fingerprint, a node without such block is taken as written by the current one
+func probeHash(node string, f io.ReaderAt, size int64) int32 {
+	for _, hash := range []int32{currentHash(), 0, 1, 2, 3} {
+		step := int64(hashLen(hash))
+		hd := make([]byte, step+4)
+		if _, err := f.ReadAt(hd, 0); err != nil {
+			continue
+		}
+		if n := int64(goutil.BytesToInt32(hd[step:])); n > 0 && step+4+n <= size {
+			bs := make([]byte, n)
+			if _, err := f.ReadAt(bs, step+4); err == nil {
+				if _, _, _, data := detectBlock(openBlock(node, hd[:step], bs), hd[:step]); data != nil {
+					return hash
+				}
+			}
+		}
+	}
+	return currentHash()
+}
+
+// detectBlock finds the compress type, or the dictionary, that decompresses bs to the data of bidBs,
+// and the chain of the fingerprint of the data that gives bidBs
+func detectBlock(bs, bidBs []byte) (compressType, chain, dict int32, data []byte) {
+	for _, compressType = range []int32{0, 1, 2, 3, COMPRESS_LZ4, COMPRESS_BROTLI} {
+		if data = praseUncompress(bs, compressType); data != nil {
+			if chain = blockChain(data, bidBs); chain >= 0 {
+				return
+			}
+		}
+	}
+	if dict = frameDict(bs); dict != 0 {
+		if data = dictUncompress(bs, dict); data != nil {
+			if chain = blockChain(data, bidBs); chain >= 0 {
+				return 2, chain, dict, data
+			}
+		}
+	}
+	return 0, 0, 0, nil
+}
+
+// blockChain is the chain of the fingerprint of data that gives bidBs, -1 if there is none
+func blockChain(data, bidBs []byte) int32 {
+	fp := fingerprintBy(keyHash(bidBs), data)
+	for chain := int32(0); chain <= maxChain; chain++ {
+		if bytes.Equal(chainKey(fp, chain), bidBs) {
+			return chain
+		}
+	}
+	return -1
+}
+
+// replay applies the journal to the paths in order, the metadata of a path is the one of its first binding
+func (t *rebuilder) replay() error {
+	names, err := journalNames()
+	if err != nil {
+		return err
+	}
+	jbs, err := journalPaths(names)
+	if err != nil {
+		return err
+	}
+	for _, jb := range jbs {
+		t.restore(jb)
+	}
+	return nil
+}
+
+func (t *rebuilder) restore(jb *stub.WfsJournalBean) {
+	path, bidBs := jb.GetPath(), jb.Fingerprint
+	if len(jb.Parts) > 0 {
+		if _, ok := t.beans[string(bidBs)]; !ok {
+			var size int64
+			for _, p := range jb.Parts {
+				if wfb, ok := t.beans[string(p.Fingerprint)]; !ok || wfb.Storenode == nil {
+					t.lost++
+					fmt.Println("lost path:", path)
+					return
+				}
+				size += p.GetSize()
+			}
+			compressType := jb.GetCompressType()
+			t.beans[string(bidBs)] = &stub.WfsFileBean{Size: &size, Datasize: &size, CompressType: &compressType, Parts: jb.Parts}
+			for _, p := range jb.Parts {
+				t.refers[string(p.Fingerprint)]++
+			}
+			t.manifests++
+		}
+	} else if _, ok := t.beans[string(bidBs)]; !ok {
+		t.lost++
+		fmt.Println("lost path:", path)
+		return
+	}
+	t.refers[string(bidBs)]++
+	t.puts[string(fingerprint([]byte(path)))] = bidBs
+	t.count++
+	if sys.Mode == 1 {
+		t.seq++
+		wpb := &stub.WfsPathBean{Path: &path, Timestramp: jb.Timestramp, ContentType: jb.ContentType, Meta: jb.Meta}
+		t.puts[string(append(PATH_PRE, []byte(path)...))] = goutil.Int64ToBytes(t.seq)
+		t.puts[string(append(PATH_SEQ, goutil.Int64ToBytes(t.seq)...))] = wfsPathBeanToBytes(wpb)
+	}
+	if e := jb.GetExpire(); e > 0 {
+		t.puts[string(ttlKey(path))] = goutil.Int64ToBytes(e)
+		t.puts[string(expireKey(e, path))] = []byte{0}
+	}
+}
+
+// finish writes the beans with their reference counts, the blocks without reference are deleted data
+// and are counted as removed size of their node
+func (t *rebuilder) finish() {
+	for k, wfb := range t.beans {
+		refer := t.refers[k]
+		if refer == 0 {
+			t.unreferenced++
+			node := wfb.GetStorenode()
+			t.rmsize[node] += int64(hashLen(t.hashes[node])) + 4 + wfb.GetSize()
+			continue
+		}
+		wfb.Refercount = &refer
+		t.puts[k] = wfsFileBeanToBytes(wfb)
+	}
+	for node, rmsize := range t.rmsize {
+		hash := t.hashes[node]
+		t.puts[string(nodeBytes(node))] = wfsNodeBeanToBytes(t.shards[node].keep(&stub.WfsNodeBean{Rmsize: &rmsize, Hash: &hash, Key: t.keys[node], Dir: beanDir(node)}))
+	}
+	t.puts[string(HASH)] = hashBytes([]int32{currentHash()})
+	t.puts[string(COUNT)] = goutil.Int64ToBytes(t.count)
+	t.puts[string(SEQ)] = goutil.Int64ToBytes(t.seq)
+	t.puts[string(VERSION_)] = []byte(sys.VERSION)
+}
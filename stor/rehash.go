@@ -0,0 +1,396 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// hashes are the hash algorithms of the keys of the stored paths, the current one first.
+// The paths stored by a previous one are found by it until the re-hash moves them.
+var hashes atomic.Value
+
+// nodeHashes caches the hash algorithm of the block headers of every node
+var nodeHashes = &sync.Map{}
+
+var rehashing int32
+
+func hashBytes(hs []int32) (bs []byte) {
+	for _, h := range hs {
+		bs = append(bs, byte(h))
+	}
+	return
+}
+
+func prevHashes() []int32 {
+	if hs, ok := hashes.Load().([]int32); ok && len(hs) > 1 {
+		return hs[1:]
+	}
+	return nil
+}
+
+// loadHashes reads the hash algorithms in use
+func loadHashes() {
+	v, _ := wfsdb.Get(HASH)
+	useHashes(v)
+}
+
+// useHashes sets the hash algorithms in use to the current one followed by the ones of v
+func useHashes(v []byte) (hs []int32) {
+	hs = []int32{currentHash()}
+	for _, h := range v {
+		if !bytes.Contains(hashBytes(hs), []byte{h}) {
+			hs = append(hs, int32(h))
+		}
+	}
+	hashes.Store(hs)
+	return
+}
+
+// mergeHashes adds the hash algorithms of imported data to the ones in use
+func mergeHashes(v []byte) error {
+	hs, _ := hashes.Load().([]int32)
+	return wfsdb.Put(HASH, hashBytes(useHashes(append(hashBytes(hs), v...))))
+}
+
+// initHash records the hash algorithm of sys.FileHash beside the ones of the stored paths
+func initHash() {
+	v, err := wfsdb.Get(HASH)
+	if err != nil || v == nil {
+		v = recordNodeHashes()
+	}
+	wfsdb.Put(HASH, hashBytes(useHashes(v)))
+}
+
+// recordNodeHashes keeps the hash algorithm in the beans of the nodes stored before it was recorded,
+// a node gets the one of its first block. The removed size of such node counted the data of the deleted blocks
+// only, it is counted again with their headers. It returns the algorithms of the nodes.
+func recordNodeHashes() (used []byte) {
+	start := ENDOFFSET_
+	for {
+		keys, err := wfsdb.GetKeysPrefixLimit(ENDOFFSET_, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			start = append(bytes.Clone(k), 0)
+			if len(k) != len(ENDOFFSET_)+8 {
+				continue
+			}
+			nidbs := k[len(ENDOFFSET_):]
+			name := nodeName(nidbs)
+			if name == "" {
+				continue
+			}
+			wnb := &stub.WfsNodeBean{}
+			if v, err := wfsdb.Get(nidbs); err == nil && v != nil {
+				if wnb = bytesToWfsNodeBean(v); wnb == nil {
+					wnb = &stub.WfsNodeBean{}
+				}
+			}
+			if wnb.Hash == nil {
+				hash := detectHash(name)
+				var end int64
+				if endBs, err := wfsdb.Get(k); err == nil && len(endBs) == 8 {
+					end = goutil.BytesToInt64(endBs)
+				}
+				rmsize := removedSize(name, hash, end)
+				wnb.Hash, wnb.Rmsize = &hash, &rmsize
+				wfsdb.Put(nidbs, wfsNodeBeanToBytes(wnb))
+			}
+			if !bytes.Contains(used, []byte{byte(wnb.GetHash())}) {
+				used = append(used, byte(wnb.GetHash()))
+			}
+		}
+		if len(keys) < maxListLimit {
+			return
+		}
+	}
+}
+
+// nodeHash returns the hash algorithm of the block headers of node
+func nodeHash(node string) (hash int32) {
+	if v, ok := nodeHashes.Load(node); ok {
+		return v.(int32)
+	}
+	nid, _ := strToInt(node)
+	if v, err := wfsdb.Get(goutil.Int64ToBytes(int64(nid))); err == nil && v != nil {
+		if wnb := bytesToWfsNodeBean(v); wnb != nil && wnb.Hash != nil {
+			hash = wnb.GetHash()
+			nodeHashes.Store(node, hash)
+			return
+		}
+	}
+	return detectHash(node)
+}
+
+// nodeOffset is the length of the block headers of node
+func nodeOffset(node string) int64 {
+	return int64(hashLen(nodeHash(node)) + 4)
+}
+
+// newNodeBean returns the bean of node with its removed size, the hash algorithm, the data key, the directory and the shards of node are kept in it
+func newNodeBean(node string, rmsize int64) *stub.WfsNodeBean {
+	hash := nodeHash(node)
+	return nodeShards(node).keep(&stub.WfsNodeBean{Rmsize: &rmsize, Hash: &hash, Key: nodeSecret(node).wrapped, Dir: beanDir(node)})
+}
+
+// removedSize walks the block headers of hash in the file of node up to end, and returns the size of the blocks
+// without bean with their headers
+func removedSize(node string, hash int32, end int64) (rmsize int64) {
+	f, err := os.Open(getpathBynode(node))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	step := int64(hashLen(hash))
+	hd := make([]byte, step+4)
+	for offset := int64(0); offset+step+4 <= end; {
+		if _, err := f.ReadAt(hd, offset); err != nil {
+			return
+		}
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size <= 0 {
+			return
+		}
+		if liveBean(bytes.Clone(hd[:step]), node, offset) == nil {
+			rmsize += step + 4 + size
+		}
+		offset += step + 4 + size
+	}
+	return
+}
+
+// detectHash finds the hash algorithm whose header length gives the bean of the first block of node,
+// a node without such block is taken as written by the current one
+func detectHash(node string) int32 {
+	if f, err := os.Open(getpathBynode(node)); err == nil {
+		defer f.Close()
+		hd := make([]byte, hashLen(3)+4)
+		n, _ := f.ReadAt(hd, 0)
+		for _, hash := range []int32{currentHash(), 0, 1, 2, 3} {
+			if step := hashLen(hash); n >= step+4 {
+				if wfb := liveBean(bytes.Clone(hd[:step]), node, 0); wfb != nil && wfb.GetSize() == int64(goutil.BytesToInt32(hd[step:step+4])) {
+					return hash
+				}
+			}
+		}
+	}
+	return currentHash()
+}
+
+// pathKey returns the key of path, it is the fingerprint of a previous hash algorithm
+// while the path is not moved to the current one
+func pathKey(path string) (fid []byte) {
+	fid = fingerprint([]byte(path))
+	prev := prevHashes()
+	if len(prev) == 0 {
+		return
+	}
+	if v, err := cacheGet(fid); err == nil && v != nil {
+		return
+	}
+	for _, h := range prev {
+		if k := fingerprintBy(h, []byte(path)); exist(k) {
+			return k
+		}
+	}
+	return
+}
+
+func initRehash() {
+	if len(prevHashes()) > 0 {
+		goTask(func() {
+			if err := rehash(); err != nil {
+				logger.Error("rehash error:", err.Error(), ", the paths stored with a previous filehash are kept on it")
+			}
+		})
+	}
+}
+
+// rehash walks the path index and moves every path to the key of the current hash algorithm. A node header
+// carries the fingerprint of its algorithm and has its length, so the blocks are not rewritten in place: they
+// are stored again in the current node under keys of the current algorithm, and the data takes up to twice its
+// size until defragmentation reclaims the nodes of the previous ones. The previous algorithms are dropped once
+// no path is left on them. Mode 0 keeps no path index, its paths stay on the previous algorithms.
+func rehash() (_r sys.ERROR) {
+	defer util.Recover()
+	if sys.Mode != 1 {
+		return sys.ERR_REHASH_MODE
+	}
+	if !atomic.CompareAndSwapInt32(&rehashing, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&rehashing, 0)
+	var moved, left int64
+	start := PATH_PRE
+	for !stopstat {
+		keys, err := wfsdb.GetKeysPrefixLimit(PATH_PRE, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			if stopstat {
+				return
+			}
+			tasklimit()
+			if ok, m := rehashPath(string(k[len(PATH_PRE):])); !ok {
+				left++
+			} else if m {
+				moved++
+			}
+			start = append(bytes.Clone(k), 0)
+		}
+		if len(keys) < maxListLimit {
+			break
+		}
+	}
+	if stopstat {
+		return
+	}
+	logger.Warn("rehash moved paths:", moved, ", paths left:", left)
+	if left == 0 {
+		hs := []int32{currentHash()}
+		if wfsdb.Put(HASH, hashBytes(hs)) == nil {
+			hashes.Store(hs)
+		}
+	}
+	return
+}
+
+// rehashPath moves path to the current hash algorithm, it reports whether path is on it
+// and whether it was moved
+func rehashPath(path string) (ok, moved bool) {
+	lockid := goutil.Hash64(append(APPENDLOCK_, []byte(path)...))
+	lockLevel1.Lock(int64(lockid))
+	defer lockLevel1.Unlock(int64(lockid))
+	fidBs := pathKey(path)
+	bidBs, err := wfsdb.Get(fidBs)
+	if err != nil || bidBs == nil {
+		return true, false
+	}
+	newFidBs := fingerprint([]byte(path))
+	newBidBs, wfb := rehashBean(bidBs)
+	if newBidBs == nil {
+		logger.Warn("rehash failed:", path)
+		return
+	}
+	if bytes.Equal(fidBs, newFidBs) && bytes.Equal(bidBs, newBidBs) {
+		return true, false
+	}
+	bat := newBatch()
+	if !bytes.Equal(bidBs, newBidBs) {
+		bat.unrefer(bidBs)
+	}
+	if !bytes.Equal(fidBs, newFidBs) {
+		bat.del(fidBs)
+	}
+	bat.put(newFidBs, newBidBs)
+	if bat.commit() != nil {
+		return
+	}
+	if !bytes.Equal(bidBs, newBidBs) {
+		journalBind(path, newBidBs, wfb.Parts, wfb.GetCompressType(), nil)
+	}
+	return true, true
+}
+
+// rehashBean stores the block of bidBs, or the parts of its manifest, again under keys of the current hash
+// algorithm. The caller owns one reference of the returned key if it is not bidBs. A block that cannot be
+// read back with its fingerprint is not moved.
+func rehashBean(bidBs []byte) (_r []byte, wfb *stub.WfsFileBean) {
+	if wfb = fileBean(bidBs); wfb == nil || quarantined(bidBs) {
+		return nil, nil
+	}
+	if len(wfb.Parts) == 0 {
+		if keyHash(bidBs) == currentHash() {
+			return bidBs, wfb
+		}
+		data := readFileBean(wfb)
+		if data == nil || !bytes.Equal(chainKey(fingerprintBy(keyHash(bidBs), data), wfb.GetChain()), bidBs) {
+			return nil, nil
+		}
+		if _r, _ = fe.appendBlock("", data, wfb.GetCompressType()); _r != nil {
+			return _r, fileBean(_r)
+		}
+		return nil, nil
+	}
+	moved := keyHash(bidBs) != currentHash()
+	for _, p := range wfb.Parts {
+		moved = moved || keyHash(p.Fingerprint) != currentHash()
+	}
+	if !moved {
+		return bidBs, wfb
+	}
+	bat := newBatch()
+	parts, owned := make([]*stub.WfsPartBean, 0, len(wfb.Parts)), make([]bool, 0, len(wfb.Parts))
+	release := func() {
+		for i, p := range parts {
+			if owned[i] {
+				bat.unrefer(p.Fingerprint)
+			}
+		}
+		bat.commit()
+	}
+	for _, p := range wfb.Parts {
+		pid, _ := rehashBean(p.Fingerprint)
+		if pid == nil {
+			break
+		}
+		parts = append(parts, &stub.WfsPartBean{Number: p.Number, Fingerprint: pid, Size: p.Size})
+		owned = append(owned, !bytes.Equal(pid, p.Fingerprint))
+	}
+	if len(parts) < len(wfb.Parts) {
+		release()
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	buf.Write(MANIFEST_)
+	for _, p := range parts {
+		buf.Write(p.Fingerprint)
+	}
+	midBs := fingerprint(buf.Bytes())
+	mlockid := goutil.Hash64(append(APPENDLOCK_, midBs...))
+	lockLevel2.Lock(int64(mlockid))
+	defer lockLevel2.Unlock(int64(mlockid))
+	var err sys.ERROR
+	if midBs, _, err = dedupKey(midBs, sameManifest(parts)); err != nil {
+		release()
+		return nil, nil
+	}
+	if v := bat.get(midBs); v != nil {
+		for i, p := range parts {
+			if owned[i] {
+				bat.unrefer(p.Fingerprint)
+			}
+		}
+		bat.refer(midBs, v)
+	} else {
+		for i, p := range parts {
+			if !owned[i] {
+				bat.refer(p.Fingerprint, bat.get(p.Fingerprint))
+			}
+		}
+		refer := int32(1)
+		nwfb := &stub.WfsFileBean{Size: wfb.Size, Datasize: wfb.Datasize, CompressType: wfb.CompressType, Refercount: &refer, Parts: parts}
+		bat.put(midBs, wfsFileBeanToBytes(nwfb))
+	}
+	if bat.commit() != nil {
+		return nil, nil
+	}
+	return midBs, fileBean(midBs)
+}
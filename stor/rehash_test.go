@@ -0,0 +1,116 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// rehashStore stores paths with crc64 fingerprints and restarts dir with sha256 ones
+func rehashStore(t *testing.T, dir string) map[string][]byte {
+	fileHash, maxsize := sys.FileHash, sys.DataMaxsize
+	t.Cleanup(func() { sys.FileHash, sys.DataMaxsize = fileHash, maxsize })
+	sys.FileHash = 0
+	startStore(dir)
+	data := map[string][]byte{"h/a": []byte("the data of h/a"), "h/b": []byte("the data of h/a"), "h/c": []byte("the data of h/c")}
+	for path, bs := range data {
+		fe.append(path, bs, 0, nil)
+	}
+	sys.DataMaxsize = 16
+	data["h/parts"] = bytes.Repeat([]byte("the parts of h/parts "), 8)
+	if _, err := fe.appendParts("h/parts", data["h/parts"], 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	sys.DataMaxsize = maxsize
+	sys.FileHash = 3
+	restart(dir)
+	return data
+}
+
+func TestRehash(t *testing.T) {
+	dir := t.TempDir()
+	data := rehashStore(t, dir)
+	defer CloseAll()
+	for deadline := time.Now().Add(10 * time.Second); len(prevHashes()) > 0 || atomic.LoadInt32(&rehashing) == 1; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the rehash does not end")
+		}
+	}
+	for path, bs := range data {
+		if got := fe.getData(path); !bytes.Equal(got, bs) {
+			t.Fatalf("data of %s after the rehash: %q", path, got)
+		}
+		if sb := fe.stat(path); sb == nil || keyHash(pathKey(path)) != 3 || keyHash(sb.Fingerprint) != 3 {
+			t.Fatalf("keys of %s after the rehash: %v", path, sb)
+		}
+	}
+	consistent(t)
+}
+
+func TestRehashMode(t *testing.T) {
+	mode := sys.Mode
+	defer func() { sys.Mode = mode }()
+	sys.Mode = 0
+	dir := t.TempDir()
+	data := rehashStore(t, dir)
+	defer CloseAll()
+	if err := rehash(); err == nil || !err.Equal(sys.ERR_REHASH_MODE) {
+		t.Fatal("the rehash of mode 0:", err)
+	}
+	for path, bs := range data {
+		if got := fe.getData(path); !bytes.Equal(got, bs) || keyHash(pathKey(path)) != 0 {
+			t.Fatalf("data of %s stored with the previous filehash: %q", path, got)
+		}
+	}
+	if len(prevHashes()) == 0 {
+		t.Fatal("the previous filehash is dropped")
+	}
+}
+
+func TestLegacyRmsize(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	dir := t.TempDir()
+	startStore(dir)
+	defer CloseAll()
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	node := storedNodes(true)[0]
+	// a node of a version without the hash algorithm counts the data of the deleted blocks only
+	var legacy int64
+	for i := 0; i < len(paths); i += 3 {
+		if sb := fe.stat(paths[i]); sb != nil && sb.Node == node {
+			legacy += sb.Size
+		}
+		fe.delData(paths[i])
+	}
+	nidbs := nodeBytes(node)
+	v, _ := wfsdb.Get(nidbs)
+	rmsize := bytesToWfsNodeBean(v).GetRmsize()
+	wfsdb.Put(nidbs, wfsNodeBeanToBytes(&stub.WfsNodeBean{Rmsize: &legacy}))
+	wfsdb.Del(HASH)
+	nodeHashes.Delete(node)
+	restart(dir)
+	v, _ = wfsdb.Get(nidbs)
+	if wnb := bytesToWfsNodeBean(v); wnb.GetRmsize() != rmsize || wnb.Hash == nil || legacy >= rmsize {
+		t.Fatalf("removed size of the node after the start: %d of %d, counted %d before", wnb.GetRmsize(), rmsize, legacy)
+	}
+	consistent(t)
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/stub"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// quarantineOn is set once a block is quarantined, reads skip the lookup before that
+var quarantineOn int32
+
+// scrubRun is 1 while the scrubber runs and 2 once it is asked to stop
+var scrubRun int32
+var scrubMux = &sync.Mutex{}
+var scrubStat = &sys.ScrubBean{}
+
+func init() {
+	sys.ScrubStart = scrubStart
+	sys.ScrubStop = scrubStop
+	sys.ScrubStatus = scrubStatus
+	sys.Corrupt = fe.corrupt
+}
+
+func initScrub() {
+	if keys, err := wfsdb.GetKeysPrefixLimit(QUARANTINE_, QUARANTINE_, 1); err == nil && len(keys) > 0 {
+		atomic.StoreInt32(&quarantineOn, 1)
+	}
+	if sys.ScrubInterval > 0 {
+		goTask(scrubTk)
+	}
+}
+
+func quarantineKey(bidBs []byte) []byte {
+	return append(QUARANTINE_, bidBs...)
+}
+
+func quarantined(bidBs []byte) (b bool) {
+	if atomic.LoadInt32(&quarantineOn) == 1 {
+		if v, err := wfsdb.Get(quarantineKey(bidBs)); err == nil && v != nil {
+			b = true
+		}
+	}
+	return
+}
+
+func quarantine(bidBs []byte) bool {
+	atomic.StoreInt32(&quarantineOn, 1)
+	return wfsdb.Put(quarantineKey(bidBs), []byte{0}) == nil
+}
+
+// corrupt reports whether the block of path, or one of its parts, is quarantined
+func (t *fileEg) corrupt(path string) (b bool) {
+	if atomic.LoadInt32(&quarantineOn) == 0 {
+		return
+	}
+	defer util.Recover()
+	if bidBs, wfb := t.getFileBean(path); wfb != nil {
+		if b = quarantined(bidBs); !b {
+			for _, p := range wfb.Parts {
+				if b = quarantined(p.Fingerprint); b {
+					break
+				}
+			}
+		}
+	}
+	return
+}
+
+func scrubStart() sys.ERROR {
+	if stopstat {
+		return sys.ERR_STOPSERVICE
+	}
+	if !atomic.CompareAndSwapInt32(&scrubRun, 0, 1) {
+		return sys.ERR_SCRUB_UNDERWAY
+	}
+	scrubMux.Lock()
+	scrubStat = &sys.ScrubBean{Running: true, StartTime: time.Now().UnixNano()}
+	scrubMux.Unlock()
+	goTask((&scrubber{start: time.Now()}).run)
+	return nil
+}
+
+func scrubStop() {
+	atomic.CompareAndSwapInt32(&scrubRun, 1, 2)
+}
+
+// scrubStatus returns a copy of the progress of the running or the last scrub
+func scrubStatus() *sys.ScrubBean {
+	scrubMux.Lock()
+	defer scrubMux.Unlock()
+	sb := *scrubStat
+	return &sb
+}
+
+func scrubTk() {
+	ticker := time.NewTicker(time.Duration(sys.ScrubInterval) * time.Hour)
+	defer ticker.Stop()
+	for !stopstat {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			scrubStart()
+		}
+	}
+}
+
+func setScrubStat(f func(sb *sys.ScrubBean)) {
+	scrubMux.Lock()
+	defer scrubMux.Unlock()
+	f(scrubStat)
+}
+
+// scrubber walks the node files block by block and verifies the fingerprint of every live block,
+// then checks that the metadata of every path points to a stored block. It reads at most
+// sys.ScrubRate MB per second so that it can run beside the service.
+type scrubber struct {
+	start time.Time
+	read  int64
+}
+
+func (t *scrubber) run() {
+	defer util.Recover()
+	defer func() {
+		setScrubStat(func(sb *sys.ScrubBean) { sb.Running, sb.Node, sb.EndTime = false, "", time.Now().UnixNano() })
+		atomic.StoreInt32(&scrubRun, 0)
+	}()
+	nodes := scrubNodes()
+	setScrubStat(func(sb *sys.ScrubBean) { sb.NodeTotal = len(nodes) })
+	for _, node := range nodes {
+		if !t.running() {
+			return
+		}
+		setScrubStat(func(sb *sys.ScrubBean) { sb.Node = node })
+		t.node(node)
+		setScrubStat(func(sb *sys.ScrubBean) { sb.Nodes++ })
+	}
+	setScrubStat(func(sb *sys.ScrubBean) { sb.Node = "" })
+	if sys.Mode == 1 {
+		t.paths()
+	}
+}
+
+func (t *scrubber) running() bool {
+	return atomic.LoadInt32(&scrubRun) == 1 && !stopstat
+}
+
+// throttle sleeps while more than sys.ScrubRate MB per second have been read
+func (t *scrubber) throttle(n int64) {
+	t.read += n
+	if d := time.Duration(float64(t.read)/float64(sys.ScrubRate*sys.MB)*float64(time.Second)) - time.Since(t.start); d > 0 {
+		<-time.After(d)
+	}
+}
+
+func scrubNodes() (_r []string) {
+	for _, dir := range sys.FileDirs() {
+		if entries, err := os.ReadDir(dir); err == nil {
+			for _, e := range entries {
+				if e.IsDir() || strings.Contains(e.Name(), "_") || slices.Contains(_r, e.Name()) {
+					continue
+				}
+				if id, ok := strToInt(e.Name()); ok && util.CheckNodeId(int64(id)) {
+					_r = append(_r, e.Name())
+				}
+			}
+		}
+	}
+	for _, node := range storedNodes(true) {
+		if nodeShards(node) != nil && !slices.Contains(_r, node) {
+			_r = append(_r, node)
+		}
+	}
+	return
+}
+
+// node verifies the blocks of node up to its end offset. Blocks whose fingerprint is not bound
+// to this node and offset are deleted or moved data and are skipped.
+func (t *scrubber) node(node string) {
+	nid, _ := strToInt(node)
+	var end int64
+	if v, err := wfsdb.Get(append(ENDOFFSET_, goutil.Int64ToBytes(int64(nid))...)); err == nil && v != nil {
+		end = goutil.BytesToInt64(v)
+	}
+	var current bool
+	if v, err := wfsdb.Get(CURRENT); err == nil && string(v) == node {
+		current = true
+	}
+	step := int64(hashLen(nodeHash(node)))
+	for offset := int64(0); offset < end && t.running(); {
+		if _, ok := defragmap.Load(node); ok {
+			return
+		}
+		if _, ok := relocating.Load(node); ok {
+			return
+		}
+		hd, ok := dataEg.readData(node, offset, step+4)
+		if !ok {
+			if !t.gone(node, offset, nil) {
+				t.find("truncated", node, offset, "", nil, false)
+			}
+			return
+		}
+		bidBs := bytes.Clone(hd[:step])
+		size := int64(goutil.BytesToInt32(hd[step:]))
+		if size == 0 && bytes.Equal(bidBs, make([]byte, step)) {
+			// blocks of the current node may be reserved but not written yet
+			if !current {
+				t.find("truncated", node, offset, "", nil, false)
+			}
+			return
+		}
+		wfb := liveBean(bidBs, node, offset)
+		if size <= 0 || offset+step+4+size > end {
+			t.find("truncated", node, offset, "", bidBs, wfb != nil)
+			return
+		}
+		if wfb != nil {
+			if bs, ok := dataEg.readData(node, offset+step+4, size); !ok {
+				if !t.gone(node, offset, bidBs) {
+					t.find("truncated", node, offset, "", bidBs, true)
+				}
+				return
+			} else if !verify(openBlock(node, bidBs, bs), bidBs, wfb) && !t.gone(node, offset, bidBs) {
+				t.find("mismatch", node, offset, "", bidBs, true)
+			}
+			setScrubStat(func(sb *sys.ScrubBean) { sb.Blocks++; sb.Bytes += size })
+		}
+		offset += step + 4 + size
+		t.throttle(step + 4 + size)
+	}
+}
+
+// gone tells whether the block bidBs at offset of node, or the node if bidBs is nil, was moved or removed while it
+// was read, as compaction and relocation do. A failed read or verify of such block is no finding.
+func (t *scrubber) gone(node string, offset int64, bidBs []byte) bool {
+	if _, ok := defragmap.Load(node); ok {
+		return true
+	}
+	if _, ok := relocating.Load(node); ok {
+		return true
+	}
+	if !exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+		return true
+	}
+	return bidBs != nil && liveBean(bidBs, node, offset) == nil
+}
+
+func liveBean(bidBs []byte, node string, offset int64) (wfb *stub.WfsFileBean) {
+	if v, err := wfsdb.Get(bidBs); err == nil && v != nil {
+		if wfb = bytesToWfsFileBean(v); wfb != nil && (wfb.GetStorenode() != node || wfb.GetOffset() != offset) {
+			wfb = nil
+		}
+	}
+	return
+}
+
+// verify decompresses the decrypted stored bytes and compares their fingerprint with the one in the block header
+func verify(bs, bidBs []byte, wfb *stub.WfsFileBean) bool {
+	data := uncompressBean(bs, wfb)
+	if data == nil || (wfb.Datasize != nil && int64(len(data)) != wfb.GetDatasize()) {
+		return false
+	}
+	return bytes.Equal(chainKey(fingerprintBy(keyHash(bidBs), data), wfb.GetChain()), bidBs)
+}
+
+// paths walks the path index and reports the paths whose data or parts are not stored where their metadata points
+func (t *scrubber) paths() {
+	start := PATH_PRE
+	for t.running() {
+		keys, err := wfsdb.GetKeysPrefixLimit(PATH_PRE, start, maxListLimit)
+		if err != nil {
+			return
+		}
+		for _, k := range keys {
+			if !t.running() {
+				return
+			}
+			t.path(string(k[len(PATH_PRE):]))
+			start = append(bytes.Clone(k), 0)
+		}
+		if len(keys) < maxListLimit {
+			return
+		}
+	}
+}
+
+func (t *scrubber) path(path string) {
+	if expired(path) {
+		return
+	}
+	bidBs, err := wfsdb.Get(pathKey(path))
+	if err != nil || bidBs == nil {
+		t.orphan(path, nil, nil)
+		return
+	}
+	wfb := fileBean(bidBs)
+	if wfb == nil {
+		t.orphan(path, bidBs, nil)
+		return
+	}
+	if len(wfb.Parts) > 0 {
+		for _, p := range wfb.Parts {
+			if pwfb := fileBean(p.Fingerprint); !t.located(p.Fingerprint, pwfb) {
+				t.orphan(path, p.Fingerprint, pwfb)
+				return
+			}
+		}
+	} else if !t.located(bidBs, wfb) {
+		t.orphan(path, bidBs, wfb)
+		return
+	}
+	setScrubStat(func(sb *sys.ScrubBean) { sb.Paths++ })
+}
+
+func fileBean(bidBs []byte) (wfb *stub.WfsFileBean) {
+	if v, err := wfsdb.Get(bidBs); err == nil && v != nil {
+		wfb = bytesToWfsFileBean(v)
+	}
+	return
+}
+
+// located reports whether the block header at the node and offset of wfb carries bidBs
+func (t *scrubber) located(bidBs []byte, wfb *stub.WfsFileBean) bool {
+	if wfb == nil || wfb.Storenode == nil {
+		return false
+	}
+	step := int64(hashLen(nodeHash(wfb.GetStorenode())))
+	t.throttle(step + 4)
+	if hd, ok := dataEg.readData(wfb.GetStorenode(), wfb.GetOffset(), step+4); ok {
+		return bytes.Equal(hd[:step], bidBs) && int64(goutil.BytesToInt32(hd[step:])) == wfb.GetSize()
+	}
+	return false
+}
+
+func (t *scrubber) orphan(path string, bidBs []byte, wfb *stub.WfsFileBean) {
+	// the path may have been deleted while it was checked
+	if v, err := wfsdb.Get(append(PATH_PRE, []byte(path)...)); err != nil || v == nil {
+		return
+	}
+	var node string
+	var offset int64
+	if wfb != nil {
+		node, offset = wfb.GetStorenode(), wfb.GetOffset()
+	}
+	t.find("orphan", node, offset, path, bidBs, wfb != nil)
+}
+
+// find records a finding, the block of a live bean is quarantined if sys.Quarantine is set
+func (t *scrubber) find(kind, node string, offset int64, path string, bidBs []byte, live bool) {
+	f := &sys.ScrubFinding{Kind: kind, Node: node, Offset: offset, Path: path, Fingerprint: bidBs}
+	if live && sys.Quarantine {
+		f.Quarantined = quarantine(bidBs)
+	}
+	logger.Warn("scrub found ", kind, " block:", node, ",", offset, " ", path)
+	setScrubStat(func(sb *sys.ScrubBean) {
+		if len(sb.Findings) < maxFindings {
+			sb.Findings = append(sb.Findings, f)
+		}
+	})
+}
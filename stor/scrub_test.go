@@ -0,0 +1,105 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/wfs/sys"
+)
+
+// corruptBlock flips a byte of the stored data of path in its node file
+func corruptBlock(t *testing.T, path string) []byte {
+	sb := fe.stat(path)
+	if sb == nil {
+		t.Fatal("stat of", path)
+	}
+	f, err := os.OpenFile(getpathBynode(sb.Node), os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	at := sb.Offset + int64(hashLen(nodeHash(sb.Node))) + 4
+	b := make([]byte, 1)
+	f.ReadAt(b, at)
+	b[0] ^= 0xff
+	f.WriteAt(b, at)
+	return sb.Fingerprint
+}
+
+func scrubAll(t *testing.T) *sys.ScrubBean {
+	if err := scrubStart(); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(10 * time.Second); scrubStatus().Running; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the scrub does not end")
+		}
+	}
+	return scrubStatus()
+}
+
+func TestScrubQuarantine(t *testing.T) {
+	quarantine := sys.Quarantine
+	defer func() { sys.Quarantine, quarantineOn = quarantine, 0 }()
+	sys.Quarantine = true
+	startStore(t.TempDir())
+	defer CloseAll()
+	for i := 0; i < 8; i++ {
+		path := fmt.Sprint("q/", i)
+		fe.append(path, []byte("the data of "+path), 0, nil)
+	}
+	bidBs := corruptBlock(t, "q/3")
+	sb := scrubAll(t)
+	if len(sb.Findings) != 1 || sb.Findings[0].Kind != "mismatch" || !bytes.Equal(sb.Findings[0].Fingerprint, bidBs) || !sb.Findings[0].Quarantined {
+		t.Fatal("findings of the scrub:", sb.Findings)
+	}
+	if !quarantined(bidBs) || !fe.corrupt("q/3") || fe.getData("q/3") != nil {
+		t.Fatal("the corrupt block is read")
+	}
+	if len(fe.findLike("q/")) != 7 || len(fe.findLimit(seq, 10)) != 7 {
+		t.Fatal("the paths found with the quarantined block")
+	}
+	if fe.stat("q/3") == nil || fe.corrupt("q/2") || string(fe.getData("q/2")) != "the data of q/2" {
+		t.Fatal("the paths after find and list")
+	}
+	if sb = scrubAll(t); len(sb.Findings) != 1 {
+		t.Fatal("findings of the second scrub:", sb.Findings)
+	}
+}
+
+func TestScrubMoved(t *testing.T) {
+	fileSize := sys.FileSize
+	defer func() { sys.FileSize = fileSize }()
+	sys.FileSize = 1 << 16
+	startStore(t.TempDir())
+	defer CloseAll()
+	var paths []string
+	for len(storedNodes(true)) == 0 {
+		path := fmt.Sprint("f", len(paths))
+		fe.append(path, []byte("the data of "+path), 0, nil)
+		paths = append(paths, path)
+	}
+	fe.delData("f0")
+	sb := fe.stat("f1")
+	if (&scrubber{}).gone(sb.Node, sb.Offset, sb.Fingerprint) {
+		t.Fatal("the live block is taken as moved")
+	}
+	// a read of the block that failed as the node is compacted is no finding
+	fe.defragNode(sb.Node, nil)
+	if !(&scrubber{}).gone(sb.Node, sb.Offset, sb.Fingerprint) || !(&scrubber{}).gone(sb.Node, sb.Offset, nil) {
+		t.Fatal("the moved block is taken as live")
+	}
+	if sb := scrubAll(t); len(sb.Findings) != 0 {
+		t.Fatal("findings of the scrub:", sb.Findings)
+	}
+}
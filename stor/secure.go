@@ -9,6 +9,7 @@ package stor
 
 import (
 	"runtime"
+	"sync/atomic"
 	"time"
 
 	"github.com/donnie4w/wfs/sys"
@@ -22,7 +23,11 @@ type Ram struct {
 	UsedPercent float64
 }
 
-var ram = newRam()
+var ram atomic.Pointer[Ram]
+
+func init() {
+	ram.Store(newRam())
+}
 
 func newRam() (r *Ram) {
 	r = &Ram{}
@@ -38,7 +43,7 @@ func newRam() (r *Ram) {
 }
 
 func restrict() bool {
-	return int(ram.UsedPercent) > sys.Restrict
+	return int(ram.Load().UsedPercent) > sys.Restrict
 }
 
 func tasklimit() {
@@ -49,10 +54,14 @@ func tasklimit() {
 
 func storTk() {
 	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
 	for {
 		select {
+		case <-stopc:
+			return
 		case <-ticker.C:
-			ram = newRam()
+			ram.Store(newRam())
+			checkDirs()
 		}
 	}
 }
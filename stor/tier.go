@@ -0,0 +1,210 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of t source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/donnie4w/go-logger/logger"
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// The nodes are kept in two tiers by the recency of their reads. New nodes are placed in sys.TierHot, a sealed
+// node not read for sys.TierAge seconds is moved to sys.TierCold, its blocks recompressed by sys.TierCompress if
+// it is set, and a cold node read at least sys.TierPromote times a minute for tierPromoteRounds minutes in a row
+// is moved back. The last access time of a node is kept under ATIME_.
+const (
+	tierInterval      = time.Minute
+	tierPromoteRounds = 3
+)
+
+type nodeAccess struct {
+	last   int64
+	reads  int64
+	saved  int64
+	streak int
+}
+
+// accesses holds the last access time and the reads of the nodes read since the start
+var accesses = &sync.Map{}
+
+// tierStart is taken as the last access time of the nodes without one
+var tierStart = time.Now().Unix()
+
+// coldfn is the node in the cold directory that the blocks of the demoted nodes are recompressed to
+var coldfn *fileHandler
+
+func tierOn() bool {
+	return sys.TierHot != "" && sys.TierCold != ""
+}
+
+func initTier() {
+	if tierOn() {
+		tierStart = time.Now().Unix()
+		goTask(tierTk)
+	}
+}
+
+func tierTk() {
+	ticker := time.NewTicker(tierInterval)
+	defer ticker.Stop()
+	for !stopstat {
+		select {
+		case <-stopc:
+			return
+		case <-ticker.C:
+			tierNodes()
+		}
+	}
+}
+
+func atimeKey(node string) []byte {
+	return append(ATIME_, nodeBytes(node)...)
+}
+
+// touchNode records a read of node
+func touchNode(node string) {
+	v, ok := accesses.Load(node)
+	if !ok {
+		v, _ = accesses.LoadOrStore(node, &nodeAccess{})
+	}
+	a := v.(*nodeAccess)
+	atomic.StoreInt64(&a.last, time.Now().Unix())
+	atomic.AddInt64(&a.reads, 1)
+}
+
+// lastAccess returns the last access time of node, the recorded one if node is not read since the start
+func lastAccess(node string) int64 {
+	if v, ok := accesses.Load(node); ok {
+		return atomic.LoadInt64(&v.(*nodeAccess).last)
+	}
+	if v, err := wfsdb.Get(atimeKey(node)); err == nil && len(v) == 8 {
+		return goutil.BytesToInt64(v)
+	}
+	return tierStart
+}
+
+// saveAccesses records the last access times that changed since they were last recorded
+func saveAccesses() {
+	accesses.Range(func(k, v any) bool {
+		node, a := k.(string), v.(*nodeAccess)
+		if last := atomic.LoadInt64(&a.last); last != a.saved && exist(append(ENDOFFSET_, nodeBytes(node)...)) {
+			if wfsdb.Put(atimeKey(node), goutil.Int64ToBytes(last)) == nil {
+				a.saved = last
+			}
+		}
+		return true
+	})
+}
+
+// tierNodes records the last access times and moves the sealed nodes between the tiers
+func tierNodes() {
+	defer util.Recover()
+	saveAccesses()
+	var demoted, promoted int
+	now := time.Now().Unix()
+	for _, node := range storedNodes(true) {
+		if stopstat {
+			return
+		}
+		if nodeShards(node) != nil {
+			continue
+		}
+		var reads int64
+		var a *nodeAccess
+		if v, ok := accesses.Load(node); ok {
+			a = v.(*nodeAccess)
+			reads = atomic.SwapInt64(&a.reads, 0)
+		}
+		if nodeDir(node) == sys.TierCold {
+			if a == nil {
+				continue
+			}
+			if reads >= int64(sys.TierPromote) {
+				a.streak++
+			} else {
+				a.streak = 0
+			}
+			if a.streak >= tierPromoteRounds {
+				a.streak = 0
+				if _, ok := checkDir(sys.TierHot); !ok {
+					continue
+				}
+				if err := moveNode(node, sys.TierHot); err != nil {
+					logger.Error("promote node ", node, " failed:", err)
+				} else {
+					promoted++
+				}
+			}
+		} else if now-lastAccess(node) >= sys.TierAge {
+			if err := demoteNode(node); err != nil {
+				logger.Error("demote node ", node, " failed:", err)
+			} else {
+				demoted++
+			}
+		}
+	}
+	if demoted+promoted > 0 {
+		logger.Warn("tier demoted nodes:", demoted, ", promoted nodes:", promoted)
+	}
+}
+
+// demoteNode moves node to the cold directory, or recompresses its live blocks to the cold node by
+// sys.TierCompress and removes it
+func demoteNode(node string) (err error) {
+	if _, ok := checkDir(sys.TierCold); !ok {
+		return errors.New("the cold directory is full")
+	}
+	if sys.TierCompress == nil {
+		return moveNode(node, sys.TierCold)
+	}
+	relocating.Store(node, byte(0))
+	defer relocating.Delete(node)
+	if _, ok := defragmap.Load(node); ok || defragging() {
+		return errors.New("the node is being defragmented")
+	}
+	if _, ok := moveBlocks(node, func(bidBs []byte, offset int64) sys.ERROR {
+		return coldRelocate(bidBs, node, offset)
+	}); !ok {
+		return errors.New("some blocks are not moved")
+	}
+	removeNode(node)
+	return
+}
+
+// coldRelocate stores the block of bidBs at offset of node again in the cold node by sys.TierCompress, a full
+// cold node is sealed and a new one is created
+func coldRelocate(bidBs []byte, node string, offset int64) (_r sys.ERROR) {
+	for i := 0; i < 2; i++ {
+		if coldfn == nil {
+			fh, err := newNodeIn(sys.TierCold)
+			if err == nil {
+				err = registerNode(fh.Node, false)
+			}
+			if err != nil {
+				logger.Error(err)
+				return sys.ERR_UNDEFINED
+			}
+			// the cold node is written by the mover only, it is not defragmented meanwhile
+			relocating.Store(fh.Node, byte(0))
+			coldfn = fh
+		}
+		if _r = coldfn.relocateAs(bidBs, node, offset, sys.TierCompress); _r == nil || !_r.Equal(sys.ERR_FILEAPPEND) {
+			return
+		}
+		relocating.Delete(coldfn.Node)
+		dataEg.sealNode(coldfn.Node)
+		coldfn = nil
+	}
+	return
+}
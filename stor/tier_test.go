@@ -0,0 +1,110 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package stor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// useTiers keeps the nodes in the hot and the cold directory of dir
+func useTiers(t *testing.T, dir string, compressType *int32) (hot, cold string) {
+	h, c, age, ct, promote, fileSize := sys.TierHot, sys.TierCold, sys.TierAge, sys.TierCompress, sys.TierPromote, sys.FileSize
+	t.Cleanup(func() {
+		sys.TierHot, sys.TierCold, sys.TierAge, sys.TierCompress, sys.TierPromote, sys.FileSize = h, c, age, ct, promote, fileSize
+		nodeDirs, readonlyDirs, accesses, coldfn = &sync.Map{}, &sync.Map{}, &sync.Map{}, nil
+	})
+	hot, cold = dir+"/hot", dir+"/cold"
+	os.MkdirAll(hot, 0777)
+	os.MkdirAll(cold, 0777)
+	sys.TierHot, sys.TierCold, sys.TierAge, sys.TierCompress, sys.TierPromote, sys.FileSize = hot, cold, 3600, compressType, 2, 1<<16
+	accesses, coldfn = &sync.Map{}, nil
+	return
+}
+
+// tierData stores the files of a node that is sealed and not read for longer than sys.TierAge
+func tierData(t *testing.T, n int) (node string) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		fe.append(fmt.Sprint("t/", i), bytes.Repeat([]byte(fmt.Sprint("the data of t/", i, " ")), 64), 0, nil)
+	}
+	node = fe.handler.Node
+	fe.next(node)
+	accesses.Store(node, &nodeAccess{last: time.Now().Unix() - sys.TierAge - 1})
+	return
+}
+
+func readTier(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if !bytes.Equal(fe.getData(fmt.Sprint("t/", i)), bytes.Repeat([]byte(fmt.Sprint("the data of t/", i, " ")), 64)) {
+			t.Fatal("data of t/", i)
+		}
+	}
+}
+
+func TestTier(t *testing.T) {
+	dir := t.TempDir()
+	hot, cold := useTiers(t, dir, nil)
+	startStore(dir)
+	defer CloseAll()
+	node := tierData(t, 3)
+	if nodeDir(node) != hot || nodeDir(fe.handler.Node) != hot {
+		t.Fatal("the new nodes are not placed in the hot directory")
+	}
+	// the node not read for sys.TierAge is demoted, the current one stays
+	tierNodes()
+	if nodeDir(node) != cold || nodeDir(fe.handler.Node) != hot || goutil.IsFileExist(hot+"/"+node) {
+		t.Fatal("node", node, "in", nodeDir(node))
+	}
+	if v, _ := wfsdb.Get(atimeKey(node)); goutil.BytesToInt64(v) != lastAccess(node) {
+		t.Fatal("the last access time is not recorded")
+	}
+	readTier(t, 3)
+	// a cold node read sys.TierPromote times a minute for tierPromoteRounds minutes in a row is promoted
+	for i := 0; i < tierPromoteRounds; i++ {
+		if nodeDir(node) != cold {
+			t.Fatal("node is promoted after", i, "rounds")
+		}
+		readTier(t, sys.TierPromote)
+		tierNodes()
+	}
+	if nodeDir(node) != hot {
+		t.Fatal("node is not promoted")
+	}
+	readTier(t, 3)
+	consistent(t)
+}
+
+func TestTierCompress(t *testing.T) {
+	dir := t.TempDir()
+	ct := int32(COMPRESS_ZSTD_BEST)
+	_, cold := useTiers(t, dir, &ct)
+	startStore(dir)
+	defer CloseAll()
+	node := tierData(t, 3)
+	// the blocks of a demoted node are recompressed to the cold node and the node is removed
+	tierNodes()
+	if coldfn == nil || nodeDir(coldfn.Node) != cold || exist(nodeBytes(node)) || goutil.IsFileExist(getpathBynode(node)) {
+		t.Fatal("node is not demoted:", node)
+	}
+	for i := 0; i < 3; i++ {
+		if sb := fe.stat(fmt.Sprint("t/", i)); sb.CompressType != ct || sb.Node != coldfn.Node {
+			t.Fatalf("%+v", sb)
+		}
+	}
+	readTier(t, 3)
+	consistent(t)
+}
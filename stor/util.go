@@ -20,6 +20,7 @@ import (
 	"github.com/donnie4w/wfs/sys"
 	"github.com/donnie4w/wfs/util"
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/klauspost/compress/zstd"
 )
 
 func strToInt(s string) (u uint64, b bool) {
@@ -77,7 +78,13 @@ func praseCompress(bs []byte, compressType int32) (_r []byte) {
 	case 2:
 		_r, _ = compress.Zstd(bs)
 	case 3, 4, 5, 6, 7, 8, 9, 10, 11:
-		_r, _ = compress.ZlibLevel(bs, int(compressType)-2)
+		_r = zlibCompress(bs, int(compressType)-2)
+	case COMPRESS_LZ4:
+		_r = lz4Compress(bs)
+	case 13, 14, 15, 16:
+		_r, _ = compress.ZstdLevel(bs, zstd.EncoderLevel(compressType-12))
+	case 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28:
+		_r = brotliCompress(bs, int(compressType-COMPRESS_BROTLI))
 	default:
 		_r = bs
 	}
@@ -91,7 +98,13 @@ func praseUncompress(bs []byte, compressType int32) (_r []byte) {
 	case 2:
 		_r, _ = compress.UnZstd(bs)
 	case 3, 4, 5, 6, 7, 8, 9, 10, 11:
-		_r, _ = compress.UnZlib(bs)
+		_r = zlibUncompress(bs)
+	case COMPRESS_LZ4:
+		_r = lz4Uncompress(bs)
+	case 13, 14, 15, 16:
+		_r, _ = compress.UnZstd(bs)
+	case 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28:
+		_r = brotliUncompress(bs)
 	default:
 		_r = bs
 	}
@@ -99,9 +112,20 @@ func praseUncompress(bs []byte, compressType int32) (_r []byte) {
 }
 
 func fingerprint(bs []byte) []byte {
-	switch sys.FileHash {
-	case 0:
-		return goutil.Int64ToBytes(int64(goutil.CRC64(bs)))
+	return fingerprintBy(currentHash(), bs)
+}
+
+// currentHash returns the hash algorithm of sys.FileHash, an unknown value is crc64 as 0
+func currentHash() int32 {
+	if sys.FileHash > 0 && sys.FileHash <= 3 {
+		return int32(sys.FileHash)
+	}
+	return 0
+}
+
+// fingerprintBy returns the fingerprint of bs by the hash algorithm hash, the values of sys.FileHash
+func fingerprintBy(hash int32, bs []byte) []byte {
+	switch hash {
 	case 1:
 		hash := md5.Sum(bs)
 		return hash[:]
@@ -117,20 +141,15 @@ func fingerprint(bs []byte) []byte {
 }
 
 func fileoffset() int {
-	switch sys.FileHash {
-	case 1:
-		return 20
-	case 2:
-		return 24
-	case 3:
-		return 36
-	default:
-		return 12
-	}
+	return fingerprintLen() + 4
 }
 
 func fingerprintLen() int {
-	switch sys.FileHash {
+	return hashLen(currentHash())
+}
+
+func hashLen(hash int32) int {
+	switch hash {
 	case 1:
 		return 16
 	case 2:
@@ -142,6 +161,25 @@ func fingerprintLen() int {
 	}
 }
 
+// keyHash returns the hash algorithm of a fingerprint, every algorithm gives fingerprints of a different length
+func keyHash(bidBs []byte) int32 {
+	switch len(bidBs) {
+	case 16:
+		return 1
+	case 20:
+		return 2
+	case 32:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// isFingerprint reports whether a key has the length of the fingerprints of one of the hash algorithms
+func isFingerprint(k []byte) bool {
+	return len(k) == hashLen(keyHash(k))
+}
+
 var cache *lru.Cache[string, []byte]
 
 func initcache() (err error) {
@@ -183,3 +221,26 @@ func cacheDel(key []byte) bool {
 	}
 	return cache.Remove(string(key))
 }
+
+// dataSize is the original size of the data of wfb, the beans stored before datasize was recorded fall back to reading it
+func dataSize(wfb *WfsFileBean) int64 {
+	if wfb.Datasize != nil {
+		return wfb.GetDatasize()
+	}
+	if wfb.GetCompressType() == 0 {
+		return wfb.GetSize()
+	}
+	return int64(len(readFileBean(wfb)))
+}
+
+// prefixEnd returns the smallest key greater than every key with prefix bs
+func prefixEnd(bs []byte) []byte {
+	_r := append([]byte{}, bs...)
+	for i := len(_r) - 1; i >= 0; i-- {
+		if _r[i] < 0xff {
+			_r[i]++
+			return _r[:i+1]
+		}
+	}
+	return nil
+}
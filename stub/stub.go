@@ -750,10 +750,16 @@ func (p *WfsData) Validate() error {
 //  - Data
 //  - Name
 //  - Compress
+//  - ContentType
+//  - Meta
+//  - Ttl
 type WfsFile struct {
   Data []byte `thrift:"data,1,required" db:"data" json:"data"`
   Name string `thrift:"name,2,required" db:"name" json:"name"`
   Compress *int8 `thrift:"compress,3" db:"compress" json:"compress,omitempty"`
+  ContentType *string `thrift:"contentType,4" db:"contentType" json:"contentType,omitempty"`
+  Meta map[string]string `thrift:"meta,5" db:"meta" json:"meta,omitempty"`
+  Ttl *int64 `thrift:"ttl,6" db:"ttl" json:"ttl,omitempty"`
 }
 
 func NewWfsFile() *WfsFile {
@@ -775,10 +781,40 @@ func (p *WfsFile) GetCompress() int8 {
   }
 return *p.Compress
 }
+var WfsFile_ContentType_DEFAULT string
+func (p *WfsFile) GetContentType() string {
+  if !p.IsSetContentType() {
+    return WfsFile_ContentType_DEFAULT
+  }
+return *p.ContentType
+}
+
+func (p *WfsFile) GetMeta() map[string]string {
+  return p.Meta
+}
+var WfsFile_Ttl_DEFAULT int64
+func (p *WfsFile) GetTtl() int64 {
+  if !p.IsSetTtl() {
+    return WfsFile_Ttl_DEFAULT
+  }
+return *p.Ttl
+}
 func (p *WfsFile) IsSetCompress() bool {
   return p.Compress != nil
 }
 
+func (p *WfsFile) IsSetContentType() bool {
+  return p.ContentType != nil
+}
+
+func (p *WfsFile) IsSetMeta() bool {
+  return p.Meta != nil
+}
+
+func (p *WfsFile) IsSetTtl() bool {
+  return p.Ttl != nil
+}
+
 func (p *WfsFile) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
@@ -826,6 +862,36 @@ func (p *WfsFile) Read(ctx context.Context, iprot thrift.TProtocol) error {
           return err
         }
       }
+    case 4:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField4(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 5:
+      if fieldTypeId == thrift.MAP {
+        if err := p.ReadField5(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 6:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField6(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
     default:
       if err := iprot.Skip(ctx, fieldTypeId); err != nil {
         return err
@@ -875,6 +941,52 @@ func (p *WfsFile)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error
   return nil
 }
 
+func (p *WfsFile)  ReadField4(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 4: ", err)
+} else {
+  p.ContentType = &v
+}
+  return nil
+}
+
+func (p *WfsFile)  ReadField5(ctx context.Context, iprot thrift.TProtocol) error {
+  _, _, size, err := iprot.ReadMapBegin(ctx)
+  if err != nil {
+    return thrift.PrependError("error reading map begin: ", err)
+  }
+  tMap := make(map[string]string, size)
+  p.Meta =  tMap
+  for i := 0; i < size; i ++ {
+var _key71 string
+    if v, err := iprot.ReadString(ctx); err != nil {
+    return thrift.PrependError("error reading field 0: ", err)
+} else {
+    _key71 = v
+}
+var _val72 string
+    if v, err := iprot.ReadString(ctx); err != nil {
+    return thrift.PrependError("error reading field 0: ", err)
+} else {
+    _val72 = v
+}
+    p.Meta[_key71] = _val72
+  }
+  if err := iprot.ReadMapEnd(ctx); err != nil {
+    return thrift.PrependError("error reading map end: ", err)
+  }
+  return nil
+}
+
+func (p *WfsFile)  ReadField6(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 6: ", err)
+} else {
+  p.Ttl = &v
+}
+  return nil
+}
+
 func (p *WfsFile) Write(ctx context.Context, oprot thrift.TProtocol) error {
   if err := oprot.WriteStructBegin(ctx, "WfsFile"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
@@ -882,6 +994,9 @@ func (p *WfsFile) Write(ctx context.Context, oprot thrift.TProtocol) error {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
     if err := p.writeField2(ctx, oprot); err != nil { return err }
     if err := p.writeField3(ctx, oprot); err != nil { return err }
+    if err := p.writeField4(ctx, oprot); err != nil { return err }
+    if err := p.writeField5(ctx, oprot); err != nil { return err }
+    if err := p.writeField6(ctx, oprot); err != nil { return err }
   }
   if err := oprot.WriteFieldStop(ctx); err != nil {
     return thrift.PrependError("write field stop error: ", err) }
@@ -922,6 +1037,52 @@ func (p *WfsFile) writeField3(ctx context.Context, oprot thrift.TProtocol) (err
   return err
 }
 
+func (p *WfsFile) writeField4(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetContentType() {
+    if err := oprot.WriteFieldBegin(ctx, "contentType", thrift.STRING, 4); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:contentType: ", p), err) }
+    if err := oprot.WriteString(ctx, string(*p.ContentType)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.contentType (4) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 4:contentType: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsFile) writeField5(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetMeta() {
+    if err := oprot.WriteFieldBegin(ctx, "meta", thrift.MAP, 5); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:meta: ", p), err) }
+    if err := oprot.WriteMapBegin(ctx, thrift.STRING, thrift.STRING, len(p.Meta)); err != nil {
+      return thrift.PrependError("error writing map begin: ", err)
+    }
+    for k, v := range p.Meta {
+      if err := oprot.WriteString(ctx, string(k)); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err) }
+      if err := oprot.WriteString(ctx, string(v)); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err) }
+    }
+    if err := oprot.WriteMapEnd(ctx); err != nil {
+      return thrift.PrependError("error writing map end: ", err)
+    }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 5:meta: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsFile) writeField6(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetTtl() {
+    if err := oprot.WriteFieldBegin(ctx, "ttl", thrift.I64, 6); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:ttl: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.Ttl)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.ttl (6) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 6:ttl: ", p), err) }
+  }
+  return err
+}
+
 func (p *WfsFile) Equals(other *WfsFile) bool {
   if p == other {
     return true
@@ -936,6 +1097,23 @@ func (p *WfsFile) Equals(other *WfsFile) bool {
     }
     if (*p.Compress) != (*other.Compress) { return false }
   }
+  if p.ContentType != other.ContentType {
+    if p.ContentType == nil || other.ContentType == nil {
+      return false
+    }
+    if (*p.ContentType) != (*other.ContentType) { return false }
+  }
+  if len(p.Meta) != len(other.Meta) { return false }
+  for k, _tgt := range p.Meta {
+    _src73 := other.Meta[k]
+    if _tgt != _src73 { return false }
+  }
+  if p.Ttl != other.Ttl {
+    if p.Ttl == nil || other.Ttl == nil {
+      return false
+    }
+    if (*p.Ttl) != (*other.Ttl) { return false }
+  }
   return true
 }
 
@@ -949,807 +1127,2968 @@ func (p *WfsFile) String() string {
 func (p *WfsFile) Validate() error {
   return nil
 }
-type WfsIface interface {
-  // Parameters:
-  //  - File
-  Append(ctx context.Context, file *WfsFile) (_r *WfsAck, _err error)
-  // Parameters:
-  //  - Path
-  Delete(ctx context.Context, path string) (_r *WfsAck, _err error)
-  // Parameters:
-  //  - Path
-  //  - Newpath_
-  Rename(ctx context.Context, path string, newpath string) (_r *WfsAck, _err error)
-  // Parameters:
-  //  - Wa
-  Auth(ctx context.Context, wa *WfsAuth) (_r *WfsAck, _err error)
-  // Parameters:
-  //  - Path
-  Get(ctx context.Context, path string) (_r *WfsData, _err error)
-  Ping(ctx context.Context) (_r int8, _err error)
+// Attributes:
+//  - Path
+//  - Size
+//  - Timestramp
+//  - Compress
+type WfsEntry struct {
+  Path string `thrift:"path,1,required" db:"path" json:"path"`
+  Size int64 `thrift:"size,2,required" db:"size" json:"size"`
+  Timestramp int64 `thrift:"timestramp,3,required" db:"timestramp" json:"timestramp"`
+  Compress int8 `thrift:"compress,4,required" db:"compress" json:"compress"`
 }
 
-type WfsIfaceClient struct {
-  c thrift.TClient
-  meta thrift.ResponseMeta
+func NewWfsEntry() *WfsEntry {
+  return &WfsEntry{}
 }
 
-func NewWfsIfaceClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *WfsIfaceClient {
-  return &WfsIfaceClient{
-    c: thrift.NewTStandardClient(f.GetProtocol(t), f.GetProtocol(t)),
-  }
-}
 
-func NewWfsIfaceClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *WfsIfaceClient {
-  return &WfsIfaceClient{
-    c: thrift.NewTStandardClient(iprot, oprot),
-  }
+func (p *WfsEntry) GetPath() string {
+  return p.Path
 }
 
-func NewWfsIfaceClient(c thrift.TClient) *WfsIfaceClient {
-  return &WfsIfaceClient{
-    c: c,
-  }
+func (p *WfsEntry) GetSize() int64 {
+  return p.Size
 }
 
-func (p *WfsIfaceClient) Client_() thrift.TClient {
-  return p.c
+func (p *WfsEntry) GetTimestramp() int64 {
+  return p.Timestramp
 }
 
-func (p *WfsIfaceClient) LastResponseMeta_() thrift.ResponseMeta {
-  return p.meta
+func (p *WfsEntry) GetCompress() int8 {
+  return p.Compress
 }
+func (p *WfsEntry) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
 
-func (p *WfsIfaceClient) SetLastResponseMeta_(meta thrift.ResponseMeta) {
-  p.meta = meta
-}
+  var issetPath bool = false;
+  var issetSize bool = false;
+  var issetTimestramp bool = false;
+  var issetCompress bool = false;
 
-// Parameters:
-//  - File
-func (p *WfsIfaceClient) Append(ctx context.Context, file *WfsFile) (_r *WfsAck, _err error) {
-  var _args0 WfsIfaceAppendArgs
-  _args0.File = file
-  var _result2 WfsIfaceAppendResult
-  var _meta1 thrift.ResponseMeta
-  _meta1, _err = p.Client_().Call(ctx, "Append", &_args0, &_result2)
-  p.SetLastResponseMeta_(_meta1)
-  if _err != nil {
-    return
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+    }
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+        issetPath = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 2:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField2(ctx, iprot); err != nil {
+          return err
+        }
+        issetSize = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 3:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField3(ctx, iprot); err != nil {
+          return err
+        }
+        issetTimestramp = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 4:
+      if fieldTypeId == thrift.BYTE {
+        if err := p.ReadField4(ctx, iprot); err != nil {
+          return err
+        }
+        issetCompress = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
+    }
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
+    }
   }
-  if _ret3 := _result2.GetSuccess(); _ret3 != nil {
-    return _ret3, nil
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Append failed: unknown result")
-}
-
-// Parameters:
-//  - Path
-func (p *WfsIfaceClient) Delete(ctx context.Context, path string) (_r *WfsAck, _err error) {
-  var _args4 WfsIfaceDeleteArgs
-  _args4.Path = path
-  var _result6 WfsIfaceDeleteResult
-  var _meta5 thrift.ResponseMeta
-  _meta5, _err = p.Client_().Call(ctx, "Delete", &_args4, &_result6)
-  p.SetLastResponseMeta_(_meta5)
-  if _err != nil {
-    return
+  if !issetPath{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Path is not set"));
   }
-  if _ret7 := _result6.GetSuccess(); _ret7 != nil {
-    return _ret7, nil
+  if !issetSize{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Size is not set"));
   }
-  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Delete failed: unknown result")
-}
-
-// Parameters:
-//  - Path
-//  - Newpath_
-func (p *WfsIfaceClient) Rename(ctx context.Context, path string, newpath string) (_r *WfsAck, _err error) {
-  var _args8 WfsIfaceRenameArgs
-  _args8.Path = path
-  _args8.Newpath_ = newpath
-  var _result10 WfsIfaceRenameResult
-  var _meta9 thrift.ResponseMeta
-  _meta9, _err = p.Client_().Call(ctx, "Rename", &_args8, &_result10)
-  p.SetLastResponseMeta_(_meta9)
-  if _err != nil {
-    return
+  if !issetTimestramp{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Timestramp is not set"));
   }
-  if _ret11 := _result10.GetSuccess(); _ret11 != nil {
-    return _ret11, nil
+  if !issetCompress{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Compress is not set"));
   }
-  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Rename failed: unknown result")
+  return nil
 }
 
-// Parameters:
-//  - Wa
-func (p *WfsIfaceClient) Auth(ctx context.Context, wa *WfsAuth) (_r *WfsAck, _err error) {
-  var _args12 WfsIfaceAuthArgs
-  _args12.Wa = wa
-  var _result14 WfsIfaceAuthResult
-  var _meta13 thrift.ResponseMeta
-  _meta13, _err = p.Client_().Call(ctx, "Auth", &_args12, &_result14)
-  p.SetLastResponseMeta_(_meta13)
-  if _err != nil {
-    return
-  }
-  if _ret15 := _result14.GetSuccess(); _ret15 != nil {
-    return _ret15, nil
-  }
-  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Auth failed: unknown result")
+func (p *WfsEntry)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 1: ", err)
+} else {
+  p.Path = v
+}
+  return nil
 }
 
-// Parameters:
-//  - Path
-func (p *WfsIfaceClient) Get(ctx context.Context, path string) (_r *WfsData, _err error) {
-  var _args16 WfsIfaceGetArgs
-  _args16.Path = path
-  var _result18 WfsIfaceGetResult
-  var _meta17 thrift.ResponseMeta
-  _meta17, _err = p.Client_().Call(ctx, "Get", &_args16, &_result18)
-  p.SetLastResponseMeta_(_meta17)
-  if _err != nil {
-    return
-  }
-  if _ret19 := _result18.GetSuccess(); _ret19 != nil {
-    return _ret19, nil
-  }
-  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Get failed: unknown result")
+func (p *WfsEntry)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 2: ", err)
+} else {
+  p.Size = v
+}
+  return nil
 }
 
-func (p *WfsIfaceClient) Ping(ctx context.Context) (_r int8, _err error) {
-  var _args20 WfsIfacePingArgs
-  var _result22 WfsIfacePingResult
-  var _meta21 thrift.ResponseMeta
-  _meta21, _err = p.Client_().Call(ctx, "Ping", &_args20, &_result22)
-  p.SetLastResponseMeta_(_meta21)
-  if _err != nil {
-    return
-  }
-  return _result22.GetSuccess(), nil
+func (p *WfsEntry)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 3: ", err)
+} else {
+  p.Timestramp = v
+}
+  return nil
 }
 
-type WfsIfaceProcessor struct {
-  processorMap map[string]thrift.TProcessorFunction
-  handler WfsIface
+func (p *WfsEntry)  ReadField4(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadByte(ctx); err != nil {
+  return thrift.PrependError("error reading field 4: ", err)
+} else {
+  temp := int8(v)
+  p.Compress = temp
+}
+  return nil
 }
 
-func (p *WfsIfaceProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
-  p.processorMap[key] = processor
+func (p *WfsEntry) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "WfsEntry"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
+    if err := p.writeField2(ctx, oprot); err != nil { return err }
+    if err := p.writeField3(ctx, oprot); err != nil { return err }
+    if err := p.writeField4(ctx, oprot); err != nil { return err }
+  }
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
 }
 
-func (p *WfsIfaceProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
-  processor, ok = p.processorMap[key]
-  return processor, ok
+func (p *WfsEntry) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.path (1) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:path: ", p), err) }
+  return err
 }
 
-func (p *WfsIfaceProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
-  return p.processorMap
+func (p *WfsEntry) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "size", thrift.I64, 2); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:size: ", p), err) }
+  if err := oprot.WriteI64(ctx, int64(p.Size)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.size (2) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:size: ", p), err) }
+  return err
 }
 
-func NewWfsIfaceProcessor(handler WfsIface) *WfsIfaceProcessor {
+func (p *WfsEntry) writeField3(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "timestramp", thrift.I64, 3); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:timestramp: ", p), err) }
+  if err := oprot.WriteI64(ctx, int64(p.Timestramp)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.timestramp (3) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 3:timestramp: ", p), err) }
+  return err
+}
 
-  self23 := &WfsIfaceProcessor{handler:handler, processorMap:make(map[string]thrift.TProcessorFunction)}
-  self23.processorMap["Append"] = &wfsIfaceProcessorAppend{handler:handler}
-  self23.processorMap["Delete"] = &wfsIfaceProcessorDelete{handler:handler}
-  self23.processorMap["Rename"] = &wfsIfaceProcessorRename{handler:handler}
-  self23.processorMap["Auth"] = &wfsIfaceProcessorAuth{handler:handler}
-  self23.processorMap["Get"] = &wfsIfaceProcessorGet{handler:handler}
-  self23.processorMap["Ping"] = &wfsIfaceProcessorPing{handler:handler}
-return self23
+func (p *WfsEntry) writeField4(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "compress", thrift.BYTE, 4); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:compress: ", p), err) }
+  if err := oprot.WriteByte(ctx, int8(p.Compress)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.compress (4) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 4:compress: ", p), err) }
+  return err
 }
 
-func (p *WfsIfaceProcessor) Process(ctx context.Context, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  name, _, seqId, err2 := iprot.ReadMessageBegin(ctx)
-  if err2 != nil { return false, thrift.WrapTException(err2) }
-  if processor, ok := p.GetProcessorFunction(name); ok {
-    return processor.Process(ctx, seqId, iprot, oprot)
+func (p *WfsEntry) Equals(other *WfsEntry) bool {
+  if p == other {
+    return true
+  } else if p == nil || other == nil {
+    return false
   }
-  iprot.Skip(ctx, thrift.STRUCT)
-  iprot.ReadMessageEnd(ctx)
-  x24 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function " + name)
-  oprot.WriteMessageBegin(ctx, name, thrift.EXCEPTION, seqId)
-  x24.Write(ctx, oprot)
-  oprot.WriteMessageEnd(ctx)
-  oprot.Flush(ctx)
-  return false, x24
+  if p.Path != other.Path { return false }
+  if p.Size != other.Size { return false }
+  if p.Timestramp != other.Timestramp { return false }
+  if p.Compress != other.Compress { return false }
+  return true
+}
 
+func (p *WfsEntry) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsEntry(%+v)", *p)
 }
 
-type wfsIfaceProcessorAppend struct {
-  handler WfsIface
+func (p *WfsEntry) Validate() error {
+  return nil
 }
 
-func (p *wfsIfaceProcessorAppend) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err25 error
-  args := WfsIfaceAppendArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Append", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+// Attributes:
+//  - Entries
+//  - Prefixes
+//  - Next
+type WfsList struct {
+  Entries []*WfsEntry `thrift:"entries,1,required" db:"entries" json:"entries"`
+  Prefixes []string `thrift:"prefixes,2,required" db:"prefixes" json:"prefixes"`
+  Next *string `thrift:"next,3" db:"next" json:"next,omitempty"`
+}
+
+func NewWfsList() *WfsList {
+  return &WfsList{}
+}
+
+
+func (p *WfsList) GetEntries() []*WfsEntry {
+  return p.Entries
+}
+
+func (p *WfsList) GetPrefixes() []string {
+  return p.Prefixes
+}
+var WfsList_Next_DEFAULT string
+func (p *WfsList) GetNext() string {
+  if !p.IsSetNext() {
+    return WfsList_Next_DEFAULT
   }
-  iprot.ReadMessageEnd(ctx)
+return *p.Next
+}
+func (p *WfsList) IsSetNext() bool {
+  return p.Next != nil
+}
 
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+func (p *WfsList) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
 
-  result := WfsIfaceAppendResult{}
-  if retval, err2 := p.handler.Append(ctx, args.File); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
+  var issetEntries bool = false;
+  var issetPrefixes bool = false;
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
     }
-    if errors.Is(err2, context.Canceled) {
-      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
-        return false, thrift.WrapTException(err)
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.LIST {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+        issetEntries = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 2:
+      if fieldTypeId == thrift.LIST {
+        if err := p.ReadField2(ctx, iprot); err != nil {
+          return err
+        }
+        issetPrefixes = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 3:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField3(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
       }
     }
-    _exc26 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Append: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Append", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err25 = thrift.WrapTException(err2)
-    }
-    if err2 := _exc26.Write(ctx, oprot); _write_err25 == nil && err2 != nil {
-      _write_err25 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err25 == nil && err2 != nil {
-      _write_err25 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.Flush(ctx); _write_err25 == nil && err2 != nil {
-      _write_err25 = thrift.WrapTException(err2)
-    }
-    if _write_err25 != nil {
-      return false, thrift.WrapTException(_write_err25)
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
     }
-    return true, err
-  } else {
-    result.Success = retval
   }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Append", thrift.REPLY, seqId); err2 != nil {
-    _write_err25 = thrift.WrapTException(err2)
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  if err2 := result.Write(ctx, oprot); _write_err25 == nil && err2 != nil {
-    _write_err25 = thrift.WrapTException(err2)
+  if !issetEntries{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Entries is not set"));
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err25 == nil && err2 != nil {
-    _write_err25 = thrift.WrapTException(err2)
+  if !issetPrefixes{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Prefixes is not set"));
   }
-  if err2 := oprot.Flush(ctx); _write_err25 == nil && err2 != nil {
-    _write_err25 = thrift.WrapTException(err2)
+  return nil
+}
+
+func (p *WfsList)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  _, size, err := iprot.ReadListBegin(ctx)
+  if err != nil {
+    return thrift.PrependError("error reading list begin: ", err)
   }
-  if _write_err25 != nil {
-    return false, thrift.WrapTException(_write_err25)
+  tSlice := make([]*WfsEntry, 0, size)
+  p.Entries =  tSlice
+  for i := 0; i < size; i ++ {
+    _elem39 := &WfsEntry{}
+    if err := _elem39.Read(ctx, iprot); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", _elem39), err)
+    }
+    p.Entries = append(p.Entries, _elem39)
   }
-  return true, err
+  if err := iprot.ReadListEnd(ctx); err != nil {
+    return thrift.PrependError("error reading list end: ", err)
+  }
+  return nil
 }
 
-type wfsIfaceProcessorDelete struct {
-  handler WfsIface
+func (p *WfsList)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+  _, size, err := iprot.ReadListBegin(ctx)
+  if err != nil {
+    return thrift.PrependError("error reading list begin: ", err)
+  }
+  tSlice := make([]string, 0, size)
+  p.Prefixes =  tSlice
+  for i := 0; i < size; i ++ {
+var _elem40 string
+    if v, err := iprot.ReadString(ctx); err != nil {
+    return thrift.PrependError("error reading field 0: ", err)
+} else {
+    _elem40 = v
 }
-
-func (p *wfsIfaceProcessorDelete) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err27 error
-  args := WfsIfaceDeleteArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Delete", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+    p.Prefixes = append(p.Prefixes, _elem40)
   }
-  iprot.ReadMessageEnd(ctx)
+  if err := iprot.ReadListEnd(ctx); err != nil {
+    return thrift.PrependError("error reading list end: ", err)
+  }
+  return nil
+}
 
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+func (p *WfsList)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 3: ", err)
+} else {
+  p.Next = &v
+}
+  return nil
+}
+
+func (p *WfsList) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "WfsList"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
+    if err := p.writeField2(ctx, oprot); err != nil { return err }
+    if err := p.writeField3(ctx, oprot); err != nil { return err }
   }
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
 
-  result := WfsIfaceDeleteResult{}
-  if retval, err2 := p.handler.Delete(ctx, args.Path); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
-    }
-    if errors.Is(err2, context.Canceled) {
-      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
-        return false, thrift.WrapTException(err)
-      }
-    }
-    _exc28 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Delete: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Delete", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err27 = thrift.WrapTException(err2)
-    }
-    if err2 := _exc28.Write(ctx, oprot); _write_err27 == nil && err2 != nil {
-      _write_err27 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err27 == nil && err2 != nil {
-      _write_err27 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.Flush(ctx); _write_err27 == nil && err2 != nil {
-      _write_err27 = thrift.WrapTException(err2)
-    }
-    if _write_err27 != nil {
-      return false, thrift.WrapTException(_write_err27)
-    }
-    return true, err
-  } else {
-    result.Success = retval
+func (p *WfsList) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "entries", thrift.LIST, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:entries: ", p), err) }
+  if err := oprot.WriteListBegin(ctx, thrift.STRUCT, len(p.Entries)); err != nil {
+    return thrift.PrependError("error writing list begin: ", err)
   }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Delete", thrift.REPLY, seqId); err2 != nil {
-    _write_err27 = thrift.WrapTException(err2)
+  for _, v := range p.Entries {
+    if err := v.Write(ctx, oprot); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", v), err)
+    }
   }
-  if err2 := result.Write(ctx, oprot); _write_err27 == nil && err2 != nil {
-    _write_err27 = thrift.WrapTException(err2)
+  if err := oprot.WriteListEnd(ctx); err != nil {
+    return thrift.PrependError("error writing list end: ", err)
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err27 == nil && err2 != nil {
-    _write_err27 = thrift.WrapTException(err2)
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:entries: ", p), err) }
+  return err
+}
+
+func (p *WfsList) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "prefixes", thrift.LIST, 2); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:prefixes: ", p), err) }
+  if err := oprot.WriteListBegin(ctx, thrift.STRING, len(p.Prefixes)); err != nil {
+    return thrift.PrependError("error writing list begin: ", err)
   }
-  if err2 := oprot.Flush(ctx); _write_err27 == nil && err2 != nil {
-    _write_err27 = thrift.WrapTException(err2)
+  for _, v := range p.Prefixes {
+    if err := oprot.WriteString(ctx, string(v)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err) }
   }
-  if _write_err27 != nil {
-    return false, thrift.WrapTException(_write_err27)
+  if err := oprot.WriteListEnd(ctx); err != nil {
+    return thrift.PrependError("error writing list end: ", err)
   }
-  return true, err
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:prefixes: ", p), err) }
+  return err
 }
 
-type wfsIfaceProcessorRename struct {
-  handler WfsIface
+func (p *WfsList) writeField3(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetNext() {
+    if err := oprot.WriteFieldBegin(ctx, "next", thrift.STRING, 3); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:next: ", p), err) }
+    if err := oprot.WriteString(ctx, string(*p.Next)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.next (3) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 3:next: ", p), err) }
+  }
+  return err
 }
 
-func (p *wfsIfaceProcessorRename) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err29 error
-  args := WfsIfaceRenameArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Rename", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+func (p *WfsList) Equals(other *WfsList) bool {
+  if p == other {
+    return true
+  } else if p == nil || other == nil {
+    return false
   }
-  iprot.ReadMessageEnd(ctx)
-
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+  if len(p.Entries) != len(other.Entries) { return false }
+  for i, _tgt := range p.Entries {
+    _src41 := other.Entries[i]
+    if !_tgt.Equals(_src41) { return false }
   }
-
-  result := WfsIfaceRenameResult{}
-  if retval, err2 := p.handler.Rename(ctx, args.Path, args.Newpath_); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
-    }
-    if errors.Is(err2, context.Canceled) {
-      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
-        return false, thrift.WrapTException(err)
-      }
-    }
-    _exc30 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Rename: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Rename", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err29 = thrift.WrapTException(err2)
-    }
-    if err2 := _exc30.Write(ctx, oprot); _write_err29 == nil && err2 != nil {
-      _write_err29 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err29 == nil && err2 != nil {
-      _write_err29 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.Flush(ctx); _write_err29 == nil && err2 != nil {
-      _write_err29 = thrift.WrapTException(err2)
-    }
-    if _write_err29 != nil {
-      return false, thrift.WrapTException(_write_err29)
+  if len(p.Prefixes) != len(other.Prefixes) { return false }
+  for i, _tgt := range p.Prefixes {
+    _src42 := other.Prefixes[i]
+    if _tgt != _src42 { return false }
+  }
+  if p.Next != other.Next {
+    if p.Next == nil || other.Next == nil {
+      return false
     }
-    return true, err
-  } else {
-    result.Success = retval
+    if (*p.Next) != (*other.Next) { return false }
   }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Rename", thrift.REPLY, seqId); err2 != nil {
-    _write_err29 = thrift.WrapTException(err2)
+  return true
+}
+
+func (p *WfsList) String() string {
+  if p == nil {
+    return "<nil>"
   }
-  if err2 := result.Write(ctx, oprot); _write_err29 == nil && err2 != nil {
-    _write_err29 = thrift.WrapTException(err2)
+  return fmt.Sprintf("WfsList(%+v)", *p)
+}
+
+func (p *WfsList) Validate() error {
+  return nil
+}
+
+// Attributes:
+//  - Exist
+//  - Size
+//  - StoredSize
+//  - Compress
+//  - Node
+//  - Offset
+//  - Refercount
+//  - Fingerprint
+//  - Timestramp
+//  - ContentType
+//  - Meta
+//  - Expire
+type WfsStat struct {
+  Exist bool `thrift:"exist,1,required" db:"exist" json:"exist"`
+  Size *int64 `thrift:"size,2" db:"size" json:"size,omitempty"`
+  StoredSize *int64 `thrift:"storedSize,3" db:"storedSize" json:"storedSize,omitempty"`
+  Compress *int8 `thrift:"compress,4" db:"compress" json:"compress,omitempty"`
+  Node *string `thrift:"node,5" db:"node" json:"node,omitempty"`
+  Offset *int64 `thrift:"offset,6" db:"offset" json:"offset,omitempty"`
+  Refercount *int32 `thrift:"refercount,7" db:"refercount" json:"refercount,omitempty"`
+  Fingerprint []byte `thrift:"fingerprint,8" db:"fingerprint" json:"fingerprint,omitempty"`
+  Timestramp *int64 `thrift:"timestramp,9" db:"timestramp" json:"timestramp,omitempty"`
+  ContentType *string `thrift:"contentType,10" db:"contentType" json:"contentType,omitempty"`
+  Meta map[string]string `thrift:"meta,11" db:"meta" json:"meta,omitempty"`
+  Expire *int64 `thrift:"expire,12" db:"expire" json:"expire,omitempty"`
+}
+
+func NewWfsStat() *WfsStat {
+  return &WfsStat{}
+}
+
+
+func (p *WfsStat) GetExist() bool {
+  return p.Exist
+}
+var WfsStat_Size_DEFAULT int64
+func (p *WfsStat) GetSize() int64 {
+  if !p.IsSetSize() {
+    return WfsStat_Size_DEFAULT
+  }
+return *p.Size
+}
+var WfsStat_StoredSize_DEFAULT int64
+func (p *WfsStat) GetStoredSize() int64 {
+  if !p.IsSetStoredSize() {
+    return WfsStat_StoredSize_DEFAULT
+  }
+return *p.StoredSize
+}
+var WfsStat_Compress_DEFAULT int8
+func (p *WfsStat) GetCompress() int8 {
+  if !p.IsSetCompress() {
+    return WfsStat_Compress_DEFAULT
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err29 == nil && err2 != nil {
-    _write_err29 = thrift.WrapTException(err2)
+return *p.Compress
+}
+var WfsStat_Node_DEFAULT string
+func (p *WfsStat) GetNode() string {
+  if !p.IsSetNode() {
+    return WfsStat_Node_DEFAULT
   }
-  if err2 := oprot.Flush(ctx); _write_err29 == nil && err2 != nil {
-    _write_err29 = thrift.WrapTException(err2)
+return *p.Node
+}
+var WfsStat_Offset_DEFAULT int64
+func (p *WfsStat) GetOffset() int64 {
+  if !p.IsSetOffset() {
+    return WfsStat_Offset_DEFAULT
   }
-  if _write_err29 != nil {
-    return false, thrift.WrapTException(_write_err29)
+return *p.Offset
+}
+var WfsStat_Refercount_DEFAULT int32
+func (p *WfsStat) GetRefercount() int32 {
+  if !p.IsSetRefercount() {
+    return WfsStat_Refercount_DEFAULT
   }
-  return true, err
+return *p.Refercount
 }
+var WfsStat_Fingerprint_DEFAULT []byte
 
-type wfsIfaceProcessorAuth struct {
-  handler WfsIface
+func (p *WfsStat) GetFingerprint() []byte {
+  return p.Fingerprint
 }
-
-func (p *wfsIfaceProcessorAuth) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err31 error
-  args := WfsIfaceAuthArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Auth", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+var WfsStat_Timestramp_DEFAULT int64
+func (p *WfsStat) GetTimestramp() int64 {
+  if !p.IsSetTimestramp() {
+    return WfsStat_Timestramp_DEFAULT
   }
-  iprot.ReadMessageEnd(ctx)
+return *p.Timestramp
+}
+var WfsStat_ContentType_DEFAULT string
+func (p *WfsStat) GetContentType() string {
+  if !p.IsSetContentType() {
+    return WfsStat_ContentType_DEFAULT
+  }
+return *p.ContentType
+}
 
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+func (p *WfsStat) GetMeta() map[string]string {
+  return p.Meta
+}
+var WfsStat_Expire_DEFAULT int64
+func (p *WfsStat) GetExpire() int64 {
+  if !p.IsSetExpire() {
+    return WfsStat_Expire_DEFAULT
   }
+return *p.Expire
+}
+func (p *WfsStat) IsSetSize() bool {
+  return p.Size != nil
+}
 
-  result := WfsIfaceAuthResult{}
-  if retval, err2 := p.handler.Auth(ctx, args.Wa); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
-    }
+func (p *WfsStat) IsSetStoredSize() bool {
+  return p.StoredSize != nil
+}
+
+func (p *WfsStat) IsSetCompress() bool {
+  return p.Compress != nil
+}
+
+func (p *WfsStat) IsSetNode() bool {
+  return p.Node != nil
+}
+
+func (p *WfsStat) IsSetOffset() bool {
+  return p.Offset != nil
+}
+
+func (p *WfsStat) IsSetRefercount() bool {
+  return p.Refercount != nil
+}
+
+func (p *WfsStat) IsSetFingerprint() bool {
+  return p.Fingerprint != nil
+}
+
+func (p *WfsStat) IsSetTimestramp() bool {
+  return p.Timestramp != nil
+}
+
+func (p *WfsStat) IsSetContentType() bool {
+  return p.ContentType != nil
+}
+
+func (p *WfsStat) IsSetMeta() bool {
+  return p.Meta != nil
+}
+
+func (p *WfsStat) IsSetExpire() bool {
+  return p.Expire != nil
+}
+
+func (p *WfsStat) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
+
+  var issetExist bool = false;
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+    }
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.BOOL {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+        issetExist = true
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 2:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField2(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 3:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField3(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 4:
+      if fieldTypeId == thrift.BYTE {
+        if err := p.ReadField4(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 5:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField5(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 6:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField6(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 7:
+      if fieldTypeId == thrift.I32 {
+        if err := p.ReadField7(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 8:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField8(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 9:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField9(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 10:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField10(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 11:
+      if fieldTypeId == thrift.MAP {
+        if err := p.ReadField11(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 12:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField12(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
+    }
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
+    }
+  }
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+  }
+  if !issetExist{
+    return thrift.NewTProtocolExceptionWithType(thrift.INVALID_DATA, fmt.Errorf("Required field Exist is not set"));
+  }
+  return nil
+}
+
+func (p *WfsStat)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadBool(ctx); err != nil {
+  return thrift.PrependError("error reading field 1: ", err)
+} else {
+  p.Exist = v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 2: ", err)
+} else {
+  p.Size = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 3: ", err)
+} else {
+  p.StoredSize = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField4(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadByte(ctx); err != nil {
+  return thrift.PrependError("error reading field 4: ", err)
+} else {
+  temp := int8(v)
+  p.Compress = &temp
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField5(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 5: ", err)
+} else {
+  p.Node = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField6(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 6: ", err)
+} else {
+  p.Offset = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField7(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI32(ctx); err != nil {
+  return thrift.PrependError("error reading field 7: ", err)
+} else {
+  p.Refercount = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField8(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadBinary(ctx); err != nil {
+  return thrift.PrependError("error reading field 8: ", err)
+} else {
+  p.Fingerprint = v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField9(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 9: ", err)
+} else {
+  p.Timestramp = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField10(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 10: ", err)
+} else {
+  p.ContentType = &v
+}
+  return nil
+}
+
+func (p *WfsStat)  ReadField11(ctx context.Context, iprot thrift.TProtocol) error {
+  _, _, size, err := iprot.ReadMapBegin(ctx)
+  if err != nil {
+    return thrift.PrependError("error reading map begin: ", err)
+  }
+  tMap := make(map[string]string, size)
+  p.Meta =  tMap
+  for i := 0; i < size; i ++ {
+var _key81 string
+    if v, err := iprot.ReadString(ctx); err != nil {
+    return thrift.PrependError("error reading field 0: ", err)
+} else {
+    _key81 = v
+}
+var _val82 string
+    if v, err := iprot.ReadString(ctx); err != nil {
+    return thrift.PrependError("error reading field 0: ", err)
+} else {
+    _val82 = v
+}
+    p.Meta[_key81] = _val82
+  }
+  if err := iprot.ReadMapEnd(ctx); err != nil {
+    return thrift.PrependError("error reading map end: ", err)
+  }
+  return nil
+}
+
+func (p *WfsStat)  ReadField12(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 12: ", err)
+} else {
+  p.Expire = &v
+}
+  return nil
+}
+
+func (p *WfsStat) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "WfsStat"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
+    if err := p.writeField2(ctx, oprot); err != nil { return err }
+    if err := p.writeField3(ctx, oprot); err != nil { return err }
+    if err := p.writeField4(ctx, oprot); err != nil { return err }
+    if err := p.writeField5(ctx, oprot); err != nil { return err }
+    if err := p.writeField6(ctx, oprot); err != nil { return err }
+    if err := p.writeField7(ctx, oprot); err != nil { return err }
+    if err := p.writeField8(ctx, oprot); err != nil { return err }
+    if err := p.writeField9(ctx, oprot); err != nil { return err }
+    if err := p.writeField10(ctx, oprot); err != nil { return err }
+    if err := p.writeField11(ctx, oprot); err != nil { return err }
+    if err := p.writeField12(ctx, oprot); err != nil { return err }
+  }
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
+
+func (p *WfsStat) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "exist", thrift.BOOL, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:exist: ", p), err) }
+  if err := oprot.WriteBool(ctx, bool(p.Exist)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.exist (1) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:exist: ", p), err) }
+  return err
+}
+
+func (p *WfsStat) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetSize() {
+    if err := oprot.WriteFieldBegin(ctx, "size", thrift.I64, 2); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:size: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.Size)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.size (2) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 2:size: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField3(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetStoredSize() {
+    if err := oprot.WriteFieldBegin(ctx, "storedSize", thrift.I64, 3); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:storedSize: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.StoredSize)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.storedSize (3) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 3:storedSize: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField4(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetCompress() {
+    if err := oprot.WriteFieldBegin(ctx, "compress", thrift.BYTE, 4); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:compress: ", p), err) }
+    if err := oprot.WriteByte(ctx, int8(*p.Compress)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.compress (4) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 4:compress: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField5(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetNode() {
+    if err := oprot.WriteFieldBegin(ctx, "node", thrift.STRING, 5); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:node: ", p), err) }
+    if err := oprot.WriteString(ctx, string(*p.Node)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.node (5) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 5:node: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField6(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetOffset() {
+    if err := oprot.WriteFieldBegin(ctx, "offset", thrift.I64, 6); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:offset: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.Offset)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.offset (6) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 6:offset: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField7(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetRefercount() {
+    if err := oprot.WriteFieldBegin(ctx, "refercount", thrift.I32, 7); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:refercount: ", p), err) }
+    if err := oprot.WriteI32(ctx, int32(*p.Refercount)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.refercount (7) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 7:refercount: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField8(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetFingerprint() {
+    if err := oprot.WriteFieldBegin(ctx, "fingerprint", thrift.STRING, 8); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:fingerprint: ", p), err) }
+    if err := oprot.WriteBinary(ctx, p.Fingerprint); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.fingerprint (8) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 8:fingerprint: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField9(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetTimestramp() {
+    if err := oprot.WriteFieldBegin(ctx, "timestramp", thrift.I64, 9); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:timestramp: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.Timestramp)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.timestramp (9) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 9:timestramp: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField10(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetContentType() {
+    if err := oprot.WriteFieldBegin(ctx, "contentType", thrift.STRING, 10); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 10:contentType: ", p), err) }
+    if err := oprot.WriteString(ctx, string(*p.ContentType)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.contentType (10) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 10:contentType: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField11(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetMeta() {
+    if err := oprot.WriteFieldBegin(ctx, "meta", thrift.MAP, 11); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 11:meta: ", p), err) }
+    if err := oprot.WriteMapBegin(ctx, thrift.STRING, thrift.STRING, len(p.Meta)); err != nil {
+      return thrift.PrependError("error writing map begin: ", err)
+    }
+    for k, v := range p.Meta {
+      if err := oprot.WriteString(ctx, string(k)); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err) }
+      if err := oprot.WriteString(ctx, string(v)); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T. (0) field write error: ", p), err) }
+    }
+    if err := oprot.WriteMapEnd(ctx); err != nil {
+      return thrift.PrependError("error writing map end: ", err)
+    }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 11:meta: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) writeField12(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetExpire() {
+    if err := oprot.WriteFieldBegin(ctx, "expire", thrift.I64, 12); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 12:expire: ", p), err) }
+    if err := oprot.WriteI64(ctx, int64(*p.Expire)); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T.expire (12) field write error: ", p), err) }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 12:expire: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsStat) Equals(other *WfsStat) bool {
+  if p == other {
+    return true
+  } else if p == nil || other == nil {
+    return false
+  }
+  if p.Exist != other.Exist { return false }
+  if p.Size != other.Size {
+    if p.Size == nil || other.Size == nil {
+      return false
+    }
+    if (*p.Size) != (*other.Size) { return false }
+  }
+  if p.StoredSize != other.StoredSize {
+    if p.StoredSize == nil || other.StoredSize == nil {
+      return false
+    }
+    if (*p.StoredSize) != (*other.StoredSize) { return false }
+  }
+  if p.Compress != other.Compress {
+    if p.Compress == nil || other.Compress == nil {
+      return false
+    }
+    if (*p.Compress) != (*other.Compress) { return false }
+  }
+  if p.Node != other.Node {
+    if p.Node == nil || other.Node == nil {
+      return false
+    }
+    if (*p.Node) != (*other.Node) { return false }
+  }
+  if p.Offset != other.Offset {
+    if p.Offset == nil || other.Offset == nil {
+      return false
+    }
+    if (*p.Offset) != (*other.Offset) { return false }
+  }
+  if p.Refercount != other.Refercount {
+    if p.Refercount == nil || other.Refercount == nil {
+      return false
+    }
+    if (*p.Refercount) != (*other.Refercount) { return false }
+  }
+  if bytes.Compare(p.Fingerprint, other.Fingerprint) != 0 { return false }
+  if p.Timestramp != other.Timestramp {
+    if p.Timestramp == nil || other.Timestramp == nil {
+      return false
+    }
+    if (*p.Timestramp) != (*other.Timestramp) { return false }
+  }
+  if p.ContentType != other.ContentType {
+    if p.ContentType == nil || other.ContentType == nil {
+      return false
+    }
+    if (*p.ContentType) != (*other.ContentType) { return false }
+  }
+  if len(p.Meta) != len(other.Meta) { return false }
+  for k, _tgt := range p.Meta {
+    _src83 := other.Meta[k]
+    if _tgt != _src83 { return false }
+  }
+  if p.Expire != other.Expire {
+    if p.Expire == nil || other.Expire == nil {
+      return false
+    }
+    if (*p.Expire) != (*other.Expire) { return false }
+  }
+  return true
+}
+
+func (p *WfsStat) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsStat(%+v)", *p)
+}
+
+func (p *WfsStat) Validate() error {
+  return nil
+}
+
+type WfsIface interface {
+  // Parameters:
+  //  - File
+  Append(ctx context.Context, file *WfsFile) (_r *WfsAck, _err error)
+  // Parameters:
+  //  - Path
+  Delete(ctx context.Context, path string) (_r *WfsAck, _err error)
+  // Parameters:
+  //  - Path
+  //  - Newpath_
+  Rename(ctx context.Context, path string, newpath string) (_r *WfsAck, _err error)
+  // Parameters:
+  //  - Wa
+  Auth(ctx context.Context, wa *WfsAuth) (_r *WfsAck, _err error)
+  // Parameters:
+  //  - Path
+  Get(ctx context.Context, path string) (_r *WfsData, _err error)
+  // Parameters:
+  //  - Prefix
+  //  - StartAfter
+  //  - Delimiter
+  //  - Limit
+  List(ctx context.Context, prefix string, startAfter string, delimiter string, limit int32) (_r *WfsList, _err error)
+  // Parameters:
+  //  - Path
+  Stat(ctx context.Context, path string) (_r *WfsStat, _err error)
+  // Parameters:
+  //  - Path
+  //  - Offset
+  //  - Size
+  GetChunk(ctx context.Context, path string, offset int64, size int32) (_r *WfsData, _err error)
+  Ping(ctx context.Context) (_r int8, _err error)
+}
+
+type WfsIfaceClient struct {
+  c thrift.TClient
+  meta thrift.ResponseMeta
+}
+
+func NewWfsIfaceClientFactory(t thrift.TTransport, f thrift.TProtocolFactory) *WfsIfaceClient {
+  return &WfsIfaceClient{
+    c: thrift.NewTStandardClient(f.GetProtocol(t), f.GetProtocol(t)),
+  }
+}
+
+func NewWfsIfaceClientProtocol(t thrift.TTransport, iprot thrift.TProtocol, oprot thrift.TProtocol) *WfsIfaceClient {
+  return &WfsIfaceClient{
+    c: thrift.NewTStandardClient(iprot, oprot),
+  }
+}
+
+func NewWfsIfaceClient(c thrift.TClient) *WfsIfaceClient {
+  return &WfsIfaceClient{
+    c: c,
+  }
+}
+
+func (p *WfsIfaceClient) Client_() thrift.TClient {
+  return p.c
+}
+
+func (p *WfsIfaceClient) LastResponseMeta_() thrift.ResponseMeta {
+  return p.meta
+}
+
+func (p *WfsIfaceClient) SetLastResponseMeta_(meta thrift.ResponseMeta) {
+  p.meta = meta
+}
+
+// Parameters:
+//  - File
+func (p *WfsIfaceClient) Append(ctx context.Context, file *WfsFile) (_r *WfsAck, _err error) {
+  var _args0 WfsIfaceAppendArgs
+  _args0.File = file
+  var _result2 WfsIfaceAppendResult
+  var _meta1 thrift.ResponseMeta
+  _meta1, _err = p.Client_().Call(ctx, "Append", &_args0, &_result2)
+  p.SetLastResponseMeta_(_meta1)
+  if _err != nil {
+    return
+  }
+  if _ret3 := _result2.GetSuccess(); _ret3 != nil {
+    return _ret3, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Append failed: unknown result")
+}
+
+// Parameters:
+//  - Path
+func (p *WfsIfaceClient) Delete(ctx context.Context, path string) (_r *WfsAck, _err error) {
+  var _args4 WfsIfaceDeleteArgs
+  _args4.Path = path
+  var _result6 WfsIfaceDeleteResult
+  var _meta5 thrift.ResponseMeta
+  _meta5, _err = p.Client_().Call(ctx, "Delete", &_args4, &_result6)
+  p.SetLastResponseMeta_(_meta5)
+  if _err != nil {
+    return
+  }
+  if _ret7 := _result6.GetSuccess(); _ret7 != nil {
+    return _ret7, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Delete failed: unknown result")
+}
+
+// Parameters:
+//  - Path
+//  - Newpath_
+func (p *WfsIfaceClient) Rename(ctx context.Context, path string, newpath string) (_r *WfsAck, _err error) {
+  var _args8 WfsIfaceRenameArgs
+  _args8.Path = path
+  _args8.Newpath_ = newpath
+  var _result10 WfsIfaceRenameResult
+  var _meta9 thrift.ResponseMeta
+  _meta9, _err = p.Client_().Call(ctx, "Rename", &_args8, &_result10)
+  p.SetLastResponseMeta_(_meta9)
+  if _err != nil {
+    return
+  }
+  if _ret11 := _result10.GetSuccess(); _ret11 != nil {
+    return _ret11, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Rename failed: unknown result")
+}
+
+// Parameters:
+//  - Wa
+func (p *WfsIfaceClient) Auth(ctx context.Context, wa *WfsAuth) (_r *WfsAck, _err error) {
+  var _args12 WfsIfaceAuthArgs
+  _args12.Wa = wa
+  var _result14 WfsIfaceAuthResult
+  var _meta13 thrift.ResponseMeta
+  _meta13, _err = p.Client_().Call(ctx, "Auth", &_args12, &_result14)
+  p.SetLastResponseMeta_(_meta13)
+  if _err != nil {
+    return
+  }
+  if _ret15 := _result14.GetSuccess(); _ret15 != nil {
+    return _ret15, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Auth failed: unknown result")
+}
+
+// Parameters:
+//  - Path
+func (p *WfsIfaceClient) Get(ctx context.Context, path string) (_r *WfsData, _err error) {
+  var _args16 WfsIfaceGetArgs
+  _args16.Path = path
+  var _result18 WfsIfaceGetResult
+  var _meta17 thrift.ResponseMeta
+  _meta17, _err = p.Client_().Call(ctx, "Get", &_args16, &_result18)
+  p.SetLastResponseMeta_(_meta17)
+  if _err != nil {
+    return
+  }
+  if _ret19 := _result18.GetSuccess(); _ret19 != nil {
+    return _ret19, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Get failed: unknown result")
+}
+
+// Parameters:
+//  - Prefix
+//  - StartAfter
+//  - Delimiter
+//  - Limit
+func (p *WfsIfaceClient) List(ctx context.Context, prefix string, startAfter string, delimiter string, limit int32) (_r *WfsList, _err error) {
+  var _args46 WfsIfaceListArgs
+  _args46.Prefix = prefix
+  _args46.StartAfter = startAfter
+  _args46.Delimiter = delimiter
+  _args46.Limit = limit
+  var _result48 WfsIfaceListResult
+  var _meta47 thrift.ResponseMeta
+  _meta47, _err = p.Client_().Call(ctx, "List", &_args46, &_result48)
+  p.SetLastResponseMeta_(_meta47)
+  if _err != nil {
+    return
+  }
+  if _ret49 := _result48.GetSuccess(); _ret49 != nil {
+    return _ret49, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "List failed: unknown result")
+}
+
+// Parameters:
+//  - Path
+func (p *WfsIfaceClient) Stat(ctx context.Context, path string) (_r *WfsStat, _err error) {
+  var _args63 WfsIfaceStatArgs
+  _args63.Path = path
+  var _result65 WfsIfaceStatResult
+  var _meta64 thrift.ResponseMeta
+  _meta64, _err = p.Client_().Call(ctx, "Stat", &_args63, &_result65)
+  p.SetLastResponseMeta_(_meta64)
+  if _err != nil {
+    return
+  }
+  if _ret66 := _result65.GetSuccess(); _ret66 != nil {
+    return _ret66, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "Stat failed: unknown result")
+}
+
+// Parameters:
+//  - Path
+//  - Offset
+//  - Size
+func (p *WfsIfaceClient) GetChunk(ctx context.Context, path string, offset int64, size int32) (_r *WfsData, _err error) {
+  var _args69 WfsIfaceGetChunkArgs
+  _args69.Path = path
+  _args69.Offset = offset
+  _args69.Size = size
+  var _result71 WfsIfaceGetChunkResult
+  var _meta70 thrift.ResponseMeta
+  _meta70, _err = p.Client_().Call(ctx, "GetChunk", &_args69, &_result71)
+  p.SetLastResponseMeta_(_meta70)
+  if _err != nil {
+    return
+  }
+  if _ret72 := _result71.GetSuccess(); _ret72 != nil {
+    return _ret72, nil
+  }
+  return nil, thrift.NewTApplicationException(thrift.MISSING_RESULT, "GetChunk failed: unknown result")
+}
+
+func (p *WfsIfaceClient) Ping(ctx context.Context) (_r int8, _err error) {
+  var _args20 WfsIfacePingArgs
+  var _result22 WfsIfacePingResult
+  var _meta21 thrift.ResponseMeta
+  _meta21, _err = p.Client_().Call(ctx, "Ping", &_args20, &_result22)
+  p.SetLastResponseMeta_(_meta21)
+  if _err != nil {
+    return
+  }
+  return _result22.GetSuccess(), nil
+}
+
+type WfsIfaceProcessor struct {
+  processorMap map[string]thrift.TProcessorFunction
+  handler WfsIface
+}
+
+func (p *WfsIfaceProcessor) AddToProcessorMap(key string, processor thrift.TProcessorFunction) {
+  p.processorMap[key] = processor
+}
+
+func (p *WfsIfaceProcessor) GetProcessorFunction(key string) (processor thrift.TProcessorFunction, ok bool) {
+  processor, ok = p.processorMap[key]
+  return processor, ok
+}
+
+func (p *WfsIfaceProcessor) ProcessorMap() map[string]thrift.TProcessorFunction {
+  return p.processorMap
+}
+
+func NewWfsIfaceProcessor(handler WfsIface) *WfsIfaceProcessor {
+
+  self23 := &WfsIfaceProcessor{handler:handler, processorMap:make(map[string]thrift.TProcessorFunction)}
+  self23.processorMap["Append"] = &wfsIfaceProcessorAppend{handler:handler}
+  self23.processorMap["Delete"] = &wfsIfaceProcessorDelete{handler:handler}
+  self23.processorMap["Rename"] = &wfsIfaceProcessorRename{handler:handler}
+  self23.processorMap["Auth"] = &wfsIfaceProcessorAuth{handler:handler}
+  self23.processorMap["Get"] = &wfsIfaceProcessorGet{handler:handler}
+  self23.processorMap["List"] = &wfsIfaceProcessorList{handler:handler}
+  self23.processorMap["Stat"] = &wfsIfaceProcessorStat{handler:handler}
+  self23.processorMap["GetChunk"] = &wfsIfaceProcessorGetChunk{handler:handler}
+  self23.processorMap["Ping"] = &wfsIfaceProcessorPing{handler:handler}
+return self23
+}
+
+func (p *WfsIfaceProcessor) Process(ctx context.Context, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  name, _, seqId, err2 := iprot.ReadMessageBegin(ctx)
+  if err2 != nil { return false, thrift.WrapTException(err2) }
+  if processor, ok := p.GetProcessorFunction(name); ok {
+    return processor.Process(ctx, seqId, iprot, oprot)
+  }
+  iprot.Skip(ctx, thrift.STRUCT)
+  iprot.ReadMessageEnd(ctx)
+  x24 := thrift.NewTApplicationException(thrift.UNKNOWN_METHOD, "Unknown function " + name)
+  oprot.WriteMessageBegin(ctx, name, thrift.EXCEPTION, seqId)
+  x24.Write(ctx, oprot)
+  oprot.WriteMessageEnd(ctx)
+  oprot.Flush(ctx)
+  return false, x24
+
+}
+
+type wfsIfaceProcessorAppend struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorAppend) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err25 error
+  args := WfsIfaceAppendArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Append", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceAppendResult{}
+  if retval, err2 := p.handler.Append(ctx, args.File); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc26 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Append: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Append", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err25 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc26.Write(ctx, oprot); _write_err25 == nil && err2 != nil {
+      _write_err25 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err25 == nil && err2 != nil {
+      _write_err25 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err25 == nil && err2 != nil {
+      _write_err25 = thrift.WrapTException(err2)
+    }
+    if _write_err25 != nil {
+      return false, thrift.WrapTException(_write_err25)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Append", thrift.REPLY, seqId); err2 != nil {
+    _write_err25 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err25 == nil && err2 != nil {
+    _write_err25 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err25 == nil && err2 != nil {
+    _write_err25 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err25 == nil && err2 != nil {
+    _write_err25 = thrift.WrapTException(err2)
+  }
+  if _write_err25 != nil {
+    return false, thrift.WrapTException(_write_err25)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorDelete struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorDelete) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err27 error
+  args := WfsIfaceDeleteArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Delete", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceDeleteResult{}
+  if retval, err2 := p.handler.Delete(ctx, args.Path); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc28 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Delete: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Delete", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err27 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc28.Write(ctx, oprot); _write_err27 == nil && err2 != nil {
+      _write_err27 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err27 == nil && err2 != nil {
+      _write_err27 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err27 == nil && err2 != nil {
+      _write_err27 = thrift.WrapTException(err2)
+    }
+    if _write_err27 != nil {
+      return false, thrift.WrapTException(_write_err27)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Delete", thrift.REPLY, seqId); err2 != nil {
+    _write_err27 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err27 == nil && err2 != nil {
+    _write_err27 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err27 == nil && err2 != nil {
+    _write_err27 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err27 == nil && err2 != nil {
+    _write_err27 = thrift.WrapTException(err2)
+  }
+  if _write_err27 != nil {
+    return false, thrift.WrapTException(_write_err27)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorRename struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorRename) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err29 error
+  args := WfsIfaceRenameArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Rename", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceRenameResult{}
+  if retval, err2 := p.handler.Rename(ctx, args.Path, args.Newpath_); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc30 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Rename: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Rename", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err29 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc30.Write(ctx, oprot); _write_err29 == nil && err2 != nil {
+      _write_err29 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err29 == nil && err2 != nil {
+      _write_err29 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err29 == nil && err2 != nil {
+      _write_err29 = thrift.WrapTException(err2)
+    }
+    if _write_err29 != nil {
+      return false, thrift.WrapTException(_write_err29)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Rename", thrift.REPLY, seqId); err2 != nil {
+    _write_err29 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err29 == nil && err2 != nil {
+    _write_err29 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err29 == nil && err2 != nil {
+    _write_err29 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err29 == nil && err2 != nil {
+    _write_err29 = thrift.WrapTException(err2)
+  }
+  if _write_err29 != nil {
+    return false, thrift.WrapTException(_write_err29)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorAuth struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorAuth) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err31 error
+  args := WfsIfaceAuthArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Auth", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceAuthResult{}
+  if retval, err2 := p.handler.Auth(ctx, args.Wa); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc32 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Auth: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Auth", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err31 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc32.Write(ctx, oprot); _write_err31 == nil && err2 != nil {
+      _write_err31 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err31 == nil && err2 != nil {
+      _write_err31 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err31 == nil && err2 != nil {
+      _write_err31 = thrift.WrapTException(err2)
+    }
+    if _write_err31 != nil {
+      return false, thrift.WrapTException(_write_err31)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Auth", thrift.REPLY, seqId); err2 != nil {
+    _write_err31 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err31 == nil && err2 != nil {
+    _write_err31 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err31 == nil && err2 != nil {
+    _write_err31 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err31 == nil && err2 != nil {
+    _write_err31 = thrift.WrapTException(err2)
+  }
+  if _write_err31 != nil {
+    return false, thrift.WrapTException(_write_err31)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorGet struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorGet) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err33 error
+  args := WfsIfaceGetArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Get", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceGetResult{}
+  if retval, err2 := p.handler.Get(ctx, args.Path); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc34 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Get: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Get", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err33 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc34.Write(ctx, oprot); _write_err33 == nil && err2 != nil {
+      _write_err33 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err33 == nil && err2 != nil {
+      _write_err33 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err33 == nil && err2 != nil {
+      _write_err33 = thrift.WrapTException(err2)
+    }
+    if _write_err33 != nil {
+      return false, thrift.WrapTException(_write_err33)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Get", thrift.REPLY, seqId); err2 != nil {
+    _write_err33 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err33 == nil && err2 != nil {
+    _write_err33 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err33 == nil && err2 != nil {
+    _write_err33 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err33 == nil && err2 != nil {
+    _write_err33 = thrift.WrapTException(err2)
+  }
+  if _write_err33 != nil {
+    return false, thrift.WrapTException(_write_err33)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorList struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorList) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err50 error
+  args := WfsIfaceListArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "List", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceListResult{}
+  if retval, err2 := p.handler.List(ctx, args.Prefix, args.StartAfter, args.Delimiter, args.Limit); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc51 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing List: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "List", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err50 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc51.Write(ctx, oprot); _write_err50 == nil && err2 != nil {
+      _write_err50 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err50 == nil && err2 != nil {
+      _write_err50 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err50 == nil && err2 != nil {
+      _write_err50 = thrift.WrapTException(err2)
+    }
+    if _write_err50 != nil {
+      return false, thrift.WrapTException(_write_err50)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "List", thrift.REPLY, seqId); err2 != nil {
+    _write_err50 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err50 == nil && err2 != nil {
+    _write_err50 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err50 == nil && err2 != nil {
+    _write_err50 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err50 == nil && err2 != nil {
+    _write_err50 = thrift.WrapTException(err2)
+  }
+  if _write_err50 != nil {
+    return false, thrift.WrapTException(_write_err50)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorStat struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorStat) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err67 error
+  args := WfsIfaceStatArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Stat", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceStatResult{}
+  if retval, err2 := p.handler.Stat(ctx, args.Path); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc68 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Stat: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Stat", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err67 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc68.Write(ctx, oprot); _write_err67 == nil && err2 != nil {
+      _write_err67 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err67 == nil && err2 != nil {
+      _write_err67 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err67 == nil && err2 != nil {
+      _write_err67 = thrift.WrapTException(err2)
+    }
+    if _write_err67 != nil {
+      return false, thrift.WrapTException(_write_err67)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Stat", thrift.REPLY, seqId); err2 != nil {
+    _write_err67 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err67 == nil && err2 != nil {
+    _write_err67 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err67 == nil && err2 != nil {
+    _write_err67 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err67 == nil && err2 != nil {
+    _write_err67 = thrift.WrapTException(err2)
+  }
+  if _write_err67 != nil {
+    return false, thrift.WrapTException(_write_err67)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorGetChunk struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorGetChunk) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err73 error
+  args := WfsIfaceGetChunkArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "GetChunk", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfaceGetChunkResult{}
+  if retval, err2 := p.handler.GetChunk(ctx, args.Path, args.Offset, args.Size); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
+    if errors.Is(err2, context.Canceled) {
+      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
+        return false, thrift.WrapTException(err)
+      }
+    }
+    _exc74 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing GetChunk: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "GetChunk", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err73 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc74.Write(ctx, oprot); _write_err73 == nil && err2 != nil {
+      _write_err73 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err73 == nil && err2 != nil {
+      _write_err73 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err73 == nil && err2 != nil {
+      _write_err73 = thrift.WrapTException(err2)
+    }
+    if _write_err73 != nil {
+      return false, thrift.WrapTException(_write_err73)
+    }
+    return true, err
+  } else {
+    result.Success = retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "GetChunk", thrift.REPLY, seqId); err2 != nil {
+    _write_err73 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err73 == nil && err2 != nil {
+    _write_err73 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err73 == nil && err2 != nil {
+    _write_err73 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err73 == nil && err2 != nil {
+    _write_err73 = thrift.WrapTException(err2)
+  }
+  if _write_err73 != nil {
+    return false, thrift.WrapTException(_write_err73)
+  }
+  return true, err
+}
+
+type wfsIfaceProcessorPing struct {
+  handler WfsIface
+}
+
+func (p *wfsIfaceProcessorPing) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
+  var _write_err35 error
+  args := WfsIfacePingArgs{}
+  if err2 := args.Read(ctx, iprot); err2 != nil {
+    iprot.ReadMessageEnd(ctx)
+    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
+    oprot.WriteMessageBegin(ctx, "Ping", thrift.EXCEPTION, seqId)
+    x.Write(ctx, oprot)
+    oprot.WriteMessageEnd(ctx)
+    oprot.Flush(ctx)
+    return false, thrift.WrapTException(err2)
+  }
+  iprot.ReadMessageEnd(ctx)
+
+  tickerCancel := func() {}
+  // Start a goroutine to do server side connectivity check.
+  if thrift.ServerConnectivityCheckInterval > 0 {
+    var cancel context.CancelCauseFunc
+    ctx, cancel = context.WithCancelCause(ctx)
+    defer cancel(nil)
+    var tickerCtx context.Context
+    tickerCtx, tickerCancel = context.WithCancel(context.Background())
+    defer tickerCancel()
+    go func(ctx context.Context, cancel context.CancelCauseFunc) {
+      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
+      defer ticker.Stop()
+      for {
+        select {
+        case <-ctx.Done():
+          return
+        case <-ticker.C:
+          if !iprot.Transport().IsOpen() {
+            cancel(thrift.ErrAbandonRequest)
+            return
+          }
+        }
+      }
+    }(tickerCtx, cancel)
+  }
+
+  result := WfsIfacePingResult{}
+  if retval, err2 := p.handler.Ping(ctx); err2 != nil {
+    tickerCancel()
+    err = thrift.WrapTException(err2)
+    if errors.Is(err2, thrift.ErrAbandonRequest) {
+      return false, thrift.WrapTException(err2)
+    }
     if errors.Is(err2, context.Canceled) {
       if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
         return false, thrift.WrapTException(err)
       }
     }
-    _exc32 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Auth: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Auth", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err31 = thrift.WrapTException(err2)
+    _exc36 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Ping: " + err2.Error())
+    if err2 := oprot.WriteMessageBegin(ctx, "Ping", thrift.EXCEPTION, seqId); err2 != nil {
+      _write_err35 = thrift.WrapTException(err2)
+    }
+    if err2 := _exc36.Write(ctx, oprot); _write_err35 == nil && err2 != nil {
+      _write_err35 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.WriteMessageEnd(ctx); _write_err35 == nil && err2 != nil {
+      _write_err35 = thrift.WrapTException(err2)
+    }
+    if err2 := oprot.Flush(ctx); _write_err35 == nil && err2 != nil {
+      _write_err35 = thrift.WrapTException(err2)
+    }
+    if _write_err35 != nil {
+      return false, thrift.WrapTException(_write_err35)
+    }
+    return true, err
+  } else {
+    result.Success = &retval
+  }
+  tickerCancel()
+  if err2 := oprot.WriteMessageBegin(ctx, "Ping", thrift.REPLY, seqId); err2 != nil {
+    _write_err35 = thrift.WrapTException(err2)
+  }
+  if err2 := result.Write(ctx, oprot); _write_err35 == nil && err2 != nil {
+    _write_err35 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.WriteMessageEnd(ctx); _write_err35 == nil && err2 != nil {
+    _write_err35 = thrift.WrapTException(err2)
+  }
+  if err2 := oprot.Flush(ctx); _write_err35 == nil && err2 != nil {
+    _write_err35 = thrift.WrapTException(err2)
+  }
+  if _write_err35 != nil {
+    return false, thrift.WrapTException(_write_err35)
+  }
+  return true, err
+}
+
+
+// HELPER FUNCTIONS AND STRUCTURES
+
+// Attributes:
+//  - File
+type WfsIfaceAppendArgs struct {
+  File *WfsFile `thrift:"file,1" db:"file" json:"file"`
+}
+
+func NewWfsIfaceAppendArgs() *WfsIfaceAppendArgs {
+  return &WfsIfaceAppendArgs{}
+}
+
+var WfsIfaceAppendArgs_File_DEFAULT *WfsFile
+func (p *WfsIfaceAppendArgs) GetFile() *WfsFile {
+  if !p.IsSetFile() {
+    return WfsIfaceAppendArgs_File_DEFAULT
+  }
+return p.File
+}
+func (p *WfsIfaceAppendArgs) IsSetFile() bool {
+  return p.File != nil
+}
+
+func (p *WfsIfaceAppendArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
+
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+    }
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.STRUCT {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
+    }
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
+    }
+  }
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+  }
+  return nil
+}
+
+func (p *WfsIfaceAppendArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  p.File = &WfsFile{}
+  if err := p.File.Read(ctx, iprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.File), err)
+  }
+  return nil
+}
+
+func (p *WfsIfaceAppendArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Append_args"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
+  }
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
+
+func (p *WfsIfaceAppendArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "file", thrift.STRUCT, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:file: ", p), err) }
+  if err := p.File.Write(ctx, oprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.File), err)
+  }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:file: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceAppendArgs) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsIfaceAppendArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+type WfsIfaceAppendResult struct {
+  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
+}
+
+func NewWfsIfaceAppendResult() *WfsIfaceAppendResult {
+  return &WfsIfaceAppendResult{}
+}
+
+var WfsIfaceAppendResult_Success_DEFAULT *WfsAck
+func (p *WfsIfaceAppendResult) GetSuccess() *WfsAck {
+  if !p.IsSetSuccess() {
+    return WfsIfaceAppendResult_Success_DEFAULT
+  }
+return p.Success
+}
+func (p *WfsIfaceAppendResult) IsSetSuccess() bool {
+  return p.Success != nil
+}
+
+func (p *WfsIfaceAppendResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
+
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+    }
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 0:
+      if fieldTypeId == thrift.STRUCT {
+        if err := p.ReadField0(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
+    }
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
     }
-    if err2 := _exc32.Write(ctx, oprot); _write_err31 == nil && err2 != nil {
-      _write_err31 = thrift.WrapTException(err2)
+  }
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+  }
+  return nil
+}
+
+func (p *WfsIfaceAppendResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsAck{}
+  if err := p.Success.Read(ctx, iprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
+  }
+  return nil
+}
+
+func (p *WfsIfaceAppendResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Append_result"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField0(ctx, oprot); err != nil { return err }
+  }
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
+
+func (p *WfsIfaceAppendResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetSuccess() {
+    if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
+    if err := p.Success.Write(ctx, oprot); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
     }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err31 == nil && err2 != nil {
-      _write_err31 = thrift.WrapTException(err2)
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err) }
+  }
+  return err
+}
+
+func (p *WfsIfaceAppendResult) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsIfaceAppendResult(%+v)", *p)
+}
+
+// Attributes:
+//  - Path
+type WfsIfaceDeleteArgs struct {
+  Path string `thrift:"path,1" db:"path" json:"path"`
+}
+
+func NewWfsIfaceDeleteArgs() *WfsIfaceDeleteArgs {
+  return &WfsIfaceDeleteArgs{}
+}
+
+
+func (p *WfsIfaceDeleteArgs) GetPath() string {
+  return p.Path
+}
+func (p *WfsIfaceDeleteArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
+
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
     }
-    if err2 := oprot.Flush(ctx); _write_err31 == nil && err2 != nil {
-      _write_err31 = thrift.WrapTException(err2)
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
     }
-    if _write_err31 != nil {
-      return false, thrift.WrapTException(_write_err31)
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
     }
-    return true, err
-  } else {
-    result.Success = retval
   }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Auth", thrift.REPLY, seqId); err2 != nil {
-    _write_err31 = thrift.WrapTException(err2)
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  if err2 := result.Write(ctx, oprot); _write_err31 == nil && err2 != nil {
-    _write_err31 = thrift.WrapTException(err2)
+  return nil
+}
+
+func (p *WfsIfaceDeleteArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 1: ", err)
+} else {
+  p.Path = v
+}
+  return nil
+}
+
+func (p *WfsIfaceDeleteArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Delete_args"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err31 == nil && err2 != nil {
-    _write_err31 = thrift.WrapTException(err2)
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
+
+func (p *WfsIfaceDeleteArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.path (1) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:path: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceDeleteArgs) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsIfaceDeleteArgs(%+v)", *p)
+}
+
+// Attributes:
+//  - Success
+type WfsIfaceDeleteResult struct {
+  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
+}
+
+func NewWfsIfaceDeleteResult() *WfsIfaceDeleteResult {
+  return &WfsIfaceDeleteResult{}
+}
+
+var WfsIfaceDeleteResult_Success_DEFAULT *WfsAck
+func (p *WfsIfaceDeleteResult) GetSuccess() *WfsAck {
+  if !p.IsSetSuccess() {
+    return WfsIfaceDeleteResult_Success_DEFAULT
+  }
+return p.Success
+}
+func (p *WfsIfaceDeleteResult) IsSetSuccess() bool {
+  return p.Success != nil
+}
+
+func (p *WfsIfaceDeleteResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+  }
+
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+    }
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 0:
+      if fieldTypeId == thrift.STRUCT {
+        if err := p.ReadField0(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
+      }
+    }
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
+    }
+  }
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  if err2 := oprot.Flush(ctx); _write_err31 == nil && err2 != nil {
-    _write_err31 = thrift.WrapTException(err2)
+  return nil
+}
+
+func (p *WfsIfaceDeleteResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsAck{}
+  if err := p.Success.Read(ctx, iprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
   }
-  if _write_err31 != nil {
-    return false, thrift.WrapTException(_write_err31)
+  return nil
+}
+
+func (p *WfsIfaceDeleteResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Delete_result"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField0(ctx, oprot); err != nil { return err }
   }
-  return true, err
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
 }
 
-type wfsIfaceProcessorGet struct {
-  handler WfsIface
+func (p *WfsIfaceDeleteResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetSuccess() {
+    if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
+    if err := p.Success.Write(ctx, oprot); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+    }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err) }
+  }
+  return err
 }
 
-func (p *wfsIfaceProcessorGet) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err33 error
-  args := WfsIfaceGetArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Get", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+func (p *WfsIfaceDeleteResult) String() string {
+  if p == nil {
+    return "<nil>"
   }
-  iprot.ReadMessageEnd(ctx)
+  return fmt.Sprintf("WfsIfaceDeleteResult(%+v)", *p)
+}
 
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+// Attributes:
+//  - Path
+//  - Newpath_
+type WfsIfaceRenameArgs struct {
+  Path string `thrift:"path,1" db:"path" json:"path"`
+  Newpath_ string `thrift:"newpath,2" db:"newpath" json:"newpath"`
+}
+
+func NewWfsIfaceRenameArgs() *WfsIfaceRenameArgs {
+  return &WfsIfaceRenameArgs{}
+}
+
+
+func (p *WfsIfaceRenameArgs) GetPath() string {
+  return p.Path
+}
+
+func (p *WfsIfaceRenameArgs) GetNewpath_() string {
+  return p.Newpath_
+}
+func (p *WfsIfaceRenameArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
 
-  result := WfsIfaceGetResult{}
-  if retval, err2 := p.handler.Get(ctx, args.Path); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
     }
-    if errors.Is(err2, context.Canceled) {
-      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
-        return false, thrift.WrapTException(err)
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 1:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField1(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 2:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField2(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
       }
     }
-    _exc34 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Get: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Get", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err33 = thrift.WrapTException(err2)
-    }
-    if err2 := _exc34.Write(ctx, oprot); _write_err33 == nil && err2 != nil {
-      _write_err33 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err33 == nil && err2 != nil {
-      _write_err33 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.Flush(ctx); _write_err33 == nil && err2 != nil {
-      _write_err33 = thrift.WrapTException(err2)
-    }
-    if _write_err33 != nil {
-      return false, thrift.WrapTException(_write_err33)
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
     }
-    return true, err
-  } else {
-    result.Success = retval
-  }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Get", thrift.REPLY, seqId); err2 != nil {
-    _write_err33 = thrift.WrapTException(err2)
-  }
-  if err2 := result.Write(ctx, oprot); _write_err33 == nil && err2 != nil {
-    _write_err33 = thrift.WrapTException(err2)
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err33 == nil && err2 != nil {
-    _write_err33 = thrift.WrapTException(err2)
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  if err2 := oprot.Flush(ctx); _write_err33 == nil && err2 != nil {
-    _write_err33 = thrift.WrapTException(err2)
+  return nil
+}
+
+func (p *WfsIfaceRenameArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 1: ", err)
+} else {
+  p.Path = v
+}
+  return nil
+}
+
+func (p *WfsIfaceRenameArgs)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 2: ", err)
+} else {
+  p.Newpath_ = v
+}
+  return nil
+}
+
+func (p *WfsIfaceRenameArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Rename_args"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField1(ctx, oprot); err != nil { return err }
+    if err := p.writeField2(ctx, oprot); err != nil { return err }
   }
-  if _write_err33 != nil {
-    return false, thrift.WrapTException(_write_err33)
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
+}
+
+func (p *WfsIfaceRenameArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.path (1) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:path: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceRenameArgs) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "newpath", thrift.STRING, 2); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:newpath: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Newpath_)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.newpath (2) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:newpath: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceRenameArgs) String() string {
+  if p == nil {
+    return "<nil>"
   }
-  return true, err
+  return fmt.Sprintf("WfsIfaceRenameArgs(%+v)", *p)
 }
 
-type wfsIfaceProcessorPing struct {
-  handler WfsIface
+// Attributes:
+//  - Success
+type WfsIfaceRenameResult struct {
+  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func (p *wfsIfaceProcessorPing) Process(ctx context.Context, seqId int32, iprot, oprot thrift.TProtocol) (success bool, err thrift.TException) {
-  var _write_err35 error
-  args := WfsIfacePingArgs{}
-  if err2 := args.Read(ctx, iprot); err2 != nil {
-    iprot.ReadMessageEnd(ctx)
-    x := thrift.NewTApplicationException(thrift.PROTOCOL_ERROR, err2.Error())
-    oprot.WriteMessageBegin(ctx, "Ping", thrift.EXCEPTION, seqId)
-    x.Write(ctx, oprot)
-    oprot.WriteMessageEnd(ctx)
-    oprot.Flush(ctx)
-    return false, thrift.WrapTException(err2)
+func NewWfsIfaceRenameResult() *WfsIfaceRenameResult {
+  return &WfsIfaceRenameResult{}
+}
+
+var WfsIfaceRenameResult_Success_DEFAULT *WfsAck
+func (p *WfsIfaceRenameResult) GetSuccess() *WfsAck {
+  if !p.IsSetSuccess() {
+    return WfsIfaceRenameResult_Success_DEFAULT
   }
-  iprot.ReadMessageEnd(ctx)
+return p.Success
+}
+func (p *WfsIfaceRenameResult) IsSetSuccess() bool {
+  return p.Success != nil
+}
 
-  tickerCancel := func() {}
-  // Start a goroutine to do server side connectivity check.
-  if thrift.ServerConnectivityCheckInterval > 0 {
-    var cancel context.CancelCauseFunc
-    ctx, cancel = context.WithCancelCause(ctx)
-    defer cancel(nil)
-    var tickerCtx context.Context
-    tickerCtx, tickerCancel = context.WithCancel(context.Background())
-    defer tickerCancel()
-    go func(ctx context.Context, cancel context.CancelCauseFunc) {
-      ticker := time.NewTicker(thrift.ServerConnectivityCheckInterval)
-      defer ticker.Stop()
-      for {
-        select {
-        case <-ctx.Done():
-          return
-        case <-ticker.C:
-          if !iprot.Transport().IsOpen() {
-            cancel(thrift.ErrAbandonRequest)
-            return
-          }
-        }
-      }
-    }(tickerCtx, cancel)
+func (p *WfsIfaceRenameResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+  if _, err := iprot.ReadStructBegin(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
 
-  result := WfsIfacePingResult{}
-  if retval, err2 := p.handler.Ping(ctx); err2 != nil {
-    tickerCancel()
-    err = thrift.WrapTException(err2)
-    if errors.Is(err2, thrift.ErrAbandonRequest) {
-      return false, thrift.WrapTException(err2)
+
+  for {
+    _, fieldTypeId, fieldId, err := iprot.ReadFieldBegin(ctx)
+    if err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
     }
-    if errors.Is(err2, context.Canceled) {
-      if err := context.Cause(ctx); errors.Is(err, thrift.ErrAbandonRequest) {
-        return false, thrift.WrapTException(err)
+    if fieldTypeId == thrift.STOP { break; }
+    switch fieldId {
+    case 0:
+      if fieldTypeId == thrift.STRUCT {
+        if err := p.ReadField0(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    default:
+      if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+        return err
       }
     }
-    _exc36 := thrift.NewTApplicationException(thrift.INTERNAL_ERROR, "Internal error processing Ping: " + err2.Error())
-    if err2 := oprot.WriteMessageBegin(ctx, "Ping", thrift.EXCEPTION, seqId); err2 != nil {
-      _write_err35 = thrift.WrapTException(err2)
-    }
-    if err2 := _exc36.Write(ctx, oprot); _write_err35 == nil && err2 != nil {
-      _write_err35 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.WriteMessageEnd(ctx); _write_err35 == nil && err2 != nil {
-      _write_err35 = thrift.WrapTException(err2)
-    }
-    if err2 := oprot.Flush(ctx); _write_err35 == nil && err2 != nil {
-      _write_err35 = thrift.WrapTException(err2)
-    }
-    if _write_err35 != nil {
-      return false, thrift.WrapTException(_write_err35)
+    if err := iprot.ReadFieldEnd(ctx); err != nil {
+      return err
     }
-    return true, err
-  } else {
-    result.Success = &retval
-  }
-  tickerCancel()
-  if err2 := oprot.WriteMessageBegin(ctx, "Ping", thrift.REPLY, seqId); err2 != nil {
-    _write_err35 = thrift.WrapTException(err2)
-  }
-  if err2 := result.Write(ctx, oprot); _write_err35 == nil && err2 != nil {
-    _write_err35 = thrift.WrapTException(err2)
   }
-  if err2 := oprot.WriteMessageEnd(ctx); _write_err35 == nil && err2 != nil {
-    _write_err35 = thrift.WrapTException(err2)
+  if err := iprot.ReadStructEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
   }
-  if err2 := oprot.Flush(ctx); _write_err35 == nil && err2 != nil {
-    _write_err35 = thrift.WrapTException(err2)
+  return nil
+}
+
+func (p *WfsIfaceRenameResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsAck{}
+  if err := p.Success.Read(ctx, iprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
   }
-  if _write_err35 != nil {
-    return false, thrift.WrapTException(_write_err35)
+  return nil
+}
+
+func (p *WfsIfaceRenameResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Rename_result"); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
+  if p != nil {
+    if err := p.writeField0(ctx, oprot); err != nil { return err }
   }
-  return true, err
+  if err := oprot.WriteFieldStop(ctx); err != nil {
+    return thrift.PrependError("write field stop error: ", err) }
+  if err := oprot.WriteStructEnd(ctx); err != nil {
+    return thrift.PrependError("write struct stop error: ", err) }
+  return nil
 }
 
+func (p *WfsIfaceRenameResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if p.IsSetSuccess() {
+    if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
+    if err := p.Success.Write(ctx, oprot); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Success), err)
+    }
+    if err := oprot.WriteFieldEnd(ctx); err != nil {
+      return thrift.PrependError(fmt.Sprintf("%T write field end error 0:success: ", p), err) }
+  }
+  return err
+}
 
-// HELPER FUNCTIONS AND STRUCTURES
+func (p *WfsIfaceRenameResult) String() string {
+  if p == nil {
+    return "<nil>"
+  }
+  return fmt.Sprintf("WfsIfaceRenameResult(%+v)", *p)
+}
 
 // Attributes:
-//  - File
-type WfsIfaceAppendArgs struct {
-  File *WfsFile `thrift:"file,1" db:"file" json:"file"`
+//  - Wa
+type WfsIfaceAuthArgs struct {
+  Wa *WfsAuth `thrift:"wa,1" db:"wa" json:"wa"`
 }
 
-func NewWfsIfaceAppendArgs() *WfsIfaceAppendArgs {
-  return &WfsIfaceAppendArgs{}
+func NewWfsIfaceAuthArgs() *WfsIfaceAuthArgs {
+  return &WfsIfaceAuthArgs{}
 }
 
-var WfsIfaceAppendArgs_File_DEFAULT *WfsFile
-func (p *WfsIfaceAppendArgs) GetFile() *WfsFile {
-  if !p.IsSetFile() {
-    return WfsIfaceAppendArgs_File_DEFAULT
+var WfsIfaceAuthArgs_Wa_DEFAULT *WfsAuth
+func (p *WfsIfaceAuthArgs) GetWa() *WfsAuth {
+  if !p.IsSetWa() {
+    return WfsIfaceAuthArgs_Wa_DEFAULT
   }
-return p.File
+return p.Wa
 }
-func (p *WfsIfaceAppendArgs) IsSetFile() bool {
-  return p.File != nil
+func (p *WfsIfaceAuthArgs) IsSetWa() bool {
+  return p.Wa != nil
 }
 
-func (p *WfsIfaceAppendArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceAuthArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -1787,16 +4126,16 @@ func (p *WfsIfaceAppendArgs) Read(ctx context.Context, iprot thrift.TProtocol) e
   return nil
 }
 
-func (p *WfsIfaceAppendArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
-  p.File = &WfsFile{}
-  if err := p.File.Read(ctx, iprot); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.File), err)
+func (p *WfsIfaceAuthArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Wa = &WfsAuth{}
+  if err := p.Wa.Read(ctx, iprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Wa), err)
   }
   return nil
 }
 
-func (p *WfsIfaceAppendArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Append_args"); err != nil {
+func (p *WfsIfaceAuthArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Auth_args"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
@@ -1808,46 +4147,46 @@ func (p *WfsIfaceAppendArgs) Write(ctx context.Context, oprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceAppendArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
-  if err := oprot.WriteFieldBegin(ctx, "file", thrift.STRUCT, 1); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:file: ", p), err) }
-  if err := p.File.Write(ctx, oprot); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.File), err)
+func (p *WfsIfaceAuthArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "wa", thrift.STRUCT, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:wa: ", p), err) }
+  if err := p.Wa.Write(ctx, oprot); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Wa), err)
   }
   if err := oprot.WriteFieldEnd(ctx); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:file: ", p), err) }
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:wa: ", p), err) }
   return err
 }
 
-func (p *WfsIfaceAppendArgs) String() string {
+func (p *WfsIfaceAuthArgs) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceAppendArgs(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceAuthArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
-type WfsIfaceAppendResult struct {
+type WfsIfaceAuthResult struct {
   Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func NewWfsIfaceAppendResult() *WfsIfaceAppendResult {
-  return &WfsIfaceAppendResult{}
+func NewWfsIfaceAuthResult() *WfsIfaceAuthResult {
+  return &WfsIfaceAuthResult{}
 }
 
-var WfsIfaceAppendResult_Success_DEFAULT *WfsAck
-func (p *WfsIfaceAppendResult) GetSuccess() *WfsAck {
+var WfsIfaceAuthResult_Success_DEFAULT *WfsAck
+func (p *WfsIfaceAuthResult) GetSuccess() *WfsAck {
   if !p.IsSetSuccess() {
-    return WfsIfaceAppendResult_Success_DEFAULT
+    return WfsIfaceAuthResult_Success_DEFAULT
   }
 return p.Success
 }
-func (p *WfsIfaceAppendResult) IsSetSuccess() bool {
+func (p *WfsIfaceAuthResult) IsSetSuccess() bool {
   return p.Success != nil
 }
 
-func (p *WfsIfaceAppendResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceAuthResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -1885,7 +4224,7 @@ func (p *WfsIfaceAppendResult) Read(ctx context.Context, iprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceAppendResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceAuthResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
   p.Success = &WfsAck{}
   if err := p.Success.Read(ctx, iprot); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
@@ -1893,8 +4232,8 @@ func (p *WfsIfaceAppendResult)  ReadField0(ctx context.Context, iprot thrift.TPr
   return nil
 }
 
-func (p *WfsIfaceAppendResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Append_result"); err != nil {
+func (p *WfsIfaceAuthResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Auth_result"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField0(ctx, oprot); err != nil { return err }
@@ -1906,7 +4245,7 @@ func (p *WfsIfaceAppendResult) Write(ctx context.Context, oprot thrift.TProtocol
   return nil
 }
 
-func (p *WfsIfaceAppendResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceAuthResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if p.IsSetSuccess() {
     if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
       return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
@@ -1919,28 +4258,28 @@ func (p *WfsIfaceAppendResult) writeField0(ctx context.Context, oprot thrift.TPr
   return err
 }
 
-func (p *WfsIfaceAppendResult) String() string {
+func (p *WfsIfaceAuthResult) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceAppendResult(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceAuthResult(%+v)", *p)
 }
 
 // Attributes:
 //  - Path
-type WfsIfaceDeleteArgs struct {
+type WfsIfaceGetArgs struct {
   Path string `thrift:"path,1" db:"path" json:"path"`
 }
 
-func NewWfsIfaceDeleteArgs() *WfsIfaceDeleteArgs {
-  return &WfsIfaceDeleteArgs{}
+func NewWfsIfaceGetArgs() *WfsIfaceGetArgs {
+  return &WfsIfaceGetArgs{}
 }
 
 
-func (p *WfsIfaceDeleteArgs) GetPath() string {
+func (p *WfsIfaceGetArgs) GetPath() string {
   return p.Path
 }
-func (p *WfsIfaceDeleteArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -1978,7 +4317,7 @@ func (p *WfsIfaceDeleteArgs) Read(ctx context.Context, iprot thrift.TProtocol) e
   return nil
 }
 
-func (p *WfsIfaceDeleteArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
   if v, err := iprot.ReadString(ctx); err != nil {
   return thrift.PrependError("error reading field 1: ", err)
 } else {
@@ -1987,8 +4326,8 @@ func (p *WfsIfaceDeleteArgs)  ReadField1(ctx context.Context, iprot thrift.TProt
   return nil
 }
 
-func (p *WfsIfaceDeleteArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Delete_args"); err != nil {
+func (p *WfsIfaceGetArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Get_args"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
@@ -2000,7 +4339,7 @@ func (p *WfsIfaceDeleteArgs) Write(ctx context.Context, oprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceDeleteArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceGetArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
   if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
@@ -2010,35 +4349,35 @@ func (p *WfsIfaceDeleteArgs) writeField1(ctx context.Context, oprot thrift.TProt
   return err
 }
 
-func (p *WfsIfaceDeleteArgs) String() string {
+func (p *WfsIfaceGetArgs) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceDeleteArgs(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceGetArgs(%+v)", *p)
 }
 
 // Attributes:
 //  - Success
-type WfsIfaceDeleteResult struct {
-  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
+type WfsIfaceGetResult struct {
+  Success *WfsData `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func NewWfsIfaceDeleteResult() *WfsIfaceDeleteResult {
-  return &WfsIfaceDeleteResult{}
+func NewWfsIfaceGetResult() *WfsIfaceGetResult {
+  return &WfsIfaceGetResult{}
 }
-
-var WfsIfaceDeleteResult_Success_DEFAULT *WfsAck
-func (p *WfsIfaceDeleteResult) GetSuccess() *WfsAck {
+
+var WfsIfaceGetResult_Success_DEFAULT *WfsData
+func (p *WfsIfaceGetResult) GetSuccess() *WfsData {
   if !p.IsSetSuccess() {
-    return WfsIfaceDeleteResult_Success_DEFAULT
+    return WfsIfaceGetResult_Success_DEFAULT
   }
 return p.Success
 }
-func (p *WfsIfaceDeleteResult) IsSetSuccess() bool {
+func (p *WfsIfaceGetResult) IsSetSuccess() bool {
   return p.Success != nil
 }
 
-func (p *WfsIfaceDeleteResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2076,16 +4415,16 @@ func (p *WfsIfaceDeleteResult) Read(ctx context.Context, iprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceDeleteResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
-  p.Success = &WfsAck{}
+func (p *WfsIfaceGetResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsData{}
   if err := p.Success.Read(ctx, iprot); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
   }
   return nil
 }
 
-func (p *WfsIfaceDeleteResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Delete_result"); err != nil {
+func (p *WfsIfaceGetResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Get_result"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField0(ctx, oprot); err != nil { return err }
@@ -2097,7 +4436,7 @@ func (p *WfsIfaceDeleteResult) Write(ctx context.Context, oprot thrift.TProtocol
   return nil
 }
 
-func (p *WfsIfaceDeleteResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceGetResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if p.IsSetSuccess() {
     if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
       return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
@@ -2110,34 +4449,46 @@ func (p *WfsIfaceDeleteResult) writeField0(ctx context.Context, oprot thrift.TPr
   return err
 }
 
-func (p *WfsIfaceDeleteResult) String() string {
+func (p *WfsIfaceGetResult) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceDeleteResult(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceGetResult(%+v)", *p)
 }
 
 // Attributes:
-//  - Path
-//  - Newpath_
-type WfsIfaceRenameArgs struct {
-  Path string `thrift:"path,1" db:"path" json:"path"`
-  Newpath_ string `thrift:"newpath,2" db:"newpath" json:"newpath"`
+//  - Prefix
+//  - StartAfter
+//  - Delimiter
+//  - Limit
+type WfsIfaceListArgs struct {
+  Prefix string `thrift:"prefix,1" db:"prefix" json:"prefix"`
+  StartAfter string `thrift:"startAfter,2" db:"startAfter" json:"startAfter"`
+  Delimiter string `thrift:"delimiter,3" db:"delimiter" json:"delimiter"`
+  Limit int32 `thrift:"limit,4" db:"limit" json:"limit"`
 }
 
-func NewWfsIfaceRenameArgs() *WfsIfaceRenameArgs {
-  return &WfsIfaceRenameArgs{}
+func NewWfsIfaceListArgs() *WfsIfaceListArgs {
+  return &WfsIfaceListArgs{}
 }
 
 
-func (p *WfsIfaceRenameArgs) GetPath() string {
-  return p.Path
+func (p *WfsIfaceListArgs) GetPrefix() string {
+  return p.Prefix
 }
 
-func (p *WfsIfaceRenameArgs) GetNewpath_() string {
-  return p.Newpath_
+func (p *WfsIfaceListArgs) GetStartAfter() string {
+  return p.StartAfter
 }
-func (p *WfsIfaceRenameArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+
+func (p *WfsIfaceListArgs) GetDelimiter() string {
+  return p.Delimiter
+}
+
+func (p *WfsIfaceListArgs) GetLimit() int32 {
+  return p.Limit
+}
+func (p *WfsIfaceListArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2170,6 +4521,26 @@ func (p *WfsIfaceRenameArgs) Read(ctx context.Context, iprot thrift.TProtocol) e
           return err
         }
       }
+    case 3:
+      if fieldTypeId == thrift.STRING {
+        if err := p.ReadField3(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 4:
+      if fieldTypeId == thrift.I32 {
+        if err := p.ReadField4(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
     default:
       if err := iprot.Skip(ctx, fieldTypeId); err != nil {
         return err
@@ -2185,30 +4556,50 @@ func (p *WfsIfaceRenameArgs) Read(ctx context.Context, iprot thrift.TProtocol) e
   return nil
 }
 
-func (p *WfsIfaceRenameArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceListArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
   if v, err := iprot.ReadString(ctx); err != nil {
   return thrift.PrependError("error reading field 1: ", err)
 } else {
-  p.Path = v
+  p.Prefix = v
 }
   return nil
 }
 
-func (p *WfsIfaceRenameArgs)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceListArgs)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
   if v, err := iprot.ReadString(ctx); err != nil {
   return thrift.PrependError("error reading field 2: ", err)
 } else {
-  p.Newpath_ = v
+  p.StartAfter = v
 }
   return nil
 }
 
-func (p *WfsIfaceRenameArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Rename_args"); err != nil {
+func (p *WfsIfaceListArgs)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 3: ", err)
+} else {
+  p.Delimiter = v
+}
+  return nil
+}
+
+func (p *WfsIfaceListArgs)  ReadField4(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI32(ctx); err != nil {
+  return thrift.PrependError("error reading field 4: ", err)
+} else {
+  p.Limit = v
+}
+  return nil
+}
+
+func (p *WfsIfaceListArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "List_args"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
     if err := p.writeField2(ctx, oprot); err != nil { return err }
+    if err := p.writeField3(ctx, oprot); err != nil { return err }
+    if err := p.writeField4(ctx, oprot); err != nil { return err }
   }
   if err := oprot.WriteFieldStop(ctx); err != nil {
     return thrift.PrependError("write field stop error: ", err) }
@@ -2217,55 +4608,76 @@ func (p *WfsIfaceRenameArgs) Write(ctx context.Context, oprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceRenameArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
-  if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
-  if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
-  return thrift.PrependError(fmt.Sprintf("%T.path (1) field write error: ", p), err) }
+func (p *WfsIfaceListArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "prefix", thrift.STRING, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:prefix: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Prefix)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.prefix (1) field write error: ", p), err) }
   if err := oprot.WriteFieldEnd(ctx); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:path: ", p), err) }
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:prefix: ", p), err) }
   return err
 }
 
-func (p *WfsIfaceRenameArgs) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
-  if err := oprot.WriteFieldBegin(ctx, "newpath", thrift.STRING, 2); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:newpath: ", p), err) }
-  if err := oprot.WriteString(ctx, string(p.Newpath_)); err != nil {
-  return thrift.PrependError(fmt.Sprintf("%T.newpath (2) field write error: ", p), err) }
+func (p *WfsIfaceListArgs) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "startAfter", thrift.STRING, 2); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:startAfter: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.StartAfter)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.startAfter (2) field write error: ", p), err) }
   if err := oprot.WriteFieldEnd(ctx); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:newpath: ", p), err) }
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:startAfter: ", p), err) }
   return err
 }
 
-func (p *WfsIfaceRenameArgs) String() string {
+func (p *WfsIfaceListArgs) writeField3(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "delimiter", thrift.STRING, 3); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:delimiter: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Delimiter)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.delimiter (3) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 3:delimiter: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceListArgs) writeField4(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "limit", thrift.I32, 4); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:limit: ", p), err) }
+  if err := oprot.WriteI32(ctx, int32(p.Limit)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.limit (4) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 4:limit: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceListArgs) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceRenameArgs(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceListArgs(%+v)", *p)
 }
 
+
 // Attributes:
 //  - Success
-type WfsIfaceRenameResult struct {
-  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
+type WfsIfaceListResult struct {
+  Success *WfsList `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func NewWfsIfaceRenameResult() *WfsIfaceRenameResult {
-  return &WfsIfaceRenameResult{}
+func NewWfsIfaceListResult() *WfsIfaceListResult {
+  return &WfsIfaceListResult{}
 }
 
-var WfsIfaceRenameResult_Success_DEFAULT *WfsAck
-func (p *WfsIfaceRenameResult) GetSuccess() *WfsAck {
+var WfsIfaceListResult_Success_DEFAULT *WfsList
+func (p *WfsIfaceListResult) GetSuccess() *WfsList {
   if !p.IsSetSuccess() {
-    return WfsIfaceRenameResult_Success_DEFAULT
+    return WfsIfaceListResult_Success_DEFAULT
   }
 return p.Success
 }
-func (p *WfsIfaceRenameResult) IsSetSuccess() bool {
+func (p *WfsIfaceListResult) IsSetSuccess() bool {
   return p.Success != nil
 }
 
-func (p *WfsIfaceRenameResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceListResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2303,16 +4715,16 @@ func (p *WfsIfaceRenameResult) Read(ctx context.Context, iprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceRenameResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
-  p.Success = &WfsAck{}
+func (p *WfsIfaceListResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsList{}
   if err := p.Success.Read(ctx, iprot); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
   }
   return nil
 }
 
-func (p *WfsIfaceRenameResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Rename_result"); err != nil {
+func (p *WfsIfaceListResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "List_result"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField0(ctx, oprot); err != nil { return err }
@@ -2324,7 +4736,7 @@ func (p *WfsIfaceRenameResult) Write(ctx context.Context, oprot thrift.TProtocol
   return nil
 }
 
-func (p *WfsIfaceRenameResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceListResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if p.IsSetSuccess() {
     if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
       return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
@@ -2337,35 +4749,29 @@ func (p *WfsIfaceRenameResult) writeField0(ctx context.Context, oprot thrift.TPr
   return err
 }
 
-func (p *WfsIfaceRenameResult) String() string {
+func (p *WfsIfaceListResult) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceRenameResult(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceListResult(%+v)", *p)
 }
 
+
 // Attributes:
-//  - Wa
-type WfsIfaceAuthArgs struct {
-  Wa *WfsAuth `thrift:"wa,1" db:"wa" json:"wa"`
+//  - Path
+type WfsIfaceStatArgs struct {
+  Path string `thrift:"path,1" db:"path" json:"path"`
 }
 
-func NewWfsIfaceAuthArgs() *WfsIfaceAuthArgs {
-  return &WfsIfaceAuthArgs{}
+func NewWfsIfaceStatArgs() *WfsIfaceStatArgs {
+  return &WfsIfaceStatArgs{}
 }
 
-var WfsIfaceAuthArgs_Wa_DEFAULT *WfsAuth
-func (p *WfsIfaceAuthArgs) GetWa() *WfsAuth {
-  if !p.IsSetWa() {
-    return WfsIfaceAuthArgs_Wa_DEFAULT
-  }
-return p.Wa
-}
-func (p *WfsIfaceAuthArgs) IsSetWa() bool {
-  return p.Wa != nil
-}
 
-func (p *WfsIfaceAuthArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceStatArgs) GetPath() string {
+  return p.Path
+}
+func (p *WfsIfaceStatArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2379,7 +4785,7 @@ func (p *WfsIfaceAuthArgs) Read(ctx context.Context, iprot thrift.TProtocol) err
     if fieldTypeId == thrift.STOP { break; }
     switch fieldId {
     case 1:
-      if fieldTypeId == thrift.STRUCT {
+      if fieldTypeId == thrift.STRING {
         if err := p.ReadField1(ctx, iprot); err != nil {
           return err
         }
@@ -2403,16 +4809,17 @@ func (p *WfsIfaceAuthArgs) Read(ctx context.Context, iprot thrift.TProtocol) err
   return nil
 }
 
-func (p *WfsIfaceAuthArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
-  p.Wa = &WfsAuth{}
-  if err := p.Wa.Read(ctx, iprot); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Wa), err)
-  }
+func (p *WfsIfaceStatArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadString(ctx); err != nil {
+  return thrift.PrependError("error reading field 1: ", err)
+} else {
+  p.Path = v
+}
   return nil
 }
 
-func (p *WfsIfaceAuthArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Auth_args"); err != nil {
+func (p *WfsIfaceStatArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Stat_args"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
@@ -2424,46 +4831,46 @@ func (p *WfsIfaceAuthArgs) Write(ctx context.Context, oprot thrift.TProtocol) er
   return nil
 }
 
-func (p *WfsIfaceAuthArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
-  if err := oprot.WriteFieldBegin(ctx, "wa", thrift.STRUCT, 1); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:wa: ", p), err) }
-  if err := p.Wa.Write(ctx, oprot); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T error writing struct: ", p.Wa), err)
-  }
+func (p *WfsIfaceStatArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
+  if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.path (1) field write error: ", p), err) }
   if err := oprot.WriteFieldEnd(ctx); err != nil {
-    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:wa: ", p), err) }
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 1:path: ", p), err) }
   return err
 }
 
-func (p *WfsIfaceAuthArgs) String() string {
+func (p *WfsIfaceStatArgs) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceAuthArgs(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceStatArgs(%+v)", *p)
 }
 
+
 // Attributes:
 //  - Success
-type WfsIfaceAuthResult struct {
-  Success *WfsAck `thrift:"success,0" db:"success" json:"success,omitempty"`
+type WfsIfaceStatResult struct {
+  Success *WfsStat `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func NewWfsIfaceAuthResult() *WfsIfaceAuthResult {
-  return &WfsIfaceAuthResult{}
+func NewWfsIfaceStatResult() *WfsIfaceStatResult {
+  return &WfsIfaceStatResult{}
 }
 
-var WfsIfaceAuthResult_Success_DEFAULT *WfsAck
-func (p *WfsIfaceAuthResult) GetSuccess() *WfsAck {
+var WfsIfaceStatResult_Success_DEFAULT *WfsStat
+func (p *WfsIfaceStatResult) GetSuccess() *WfsStat {
   if !p.IsSetSuccess() {
-    return WfsIfaceAuthResult_Success_DEFAULT
+    return WfsIfaceStatResult_Success_DEFAULT
   }
 return p.Success
 }
-func (p *WfsIfaceAuthResult) IsSetSuccess() bool {
+func (p *WfsIfaceStatResult) IsSetSuccess() bool {
   return p.Success != nil
 }
 
-func (p *WfsIfaceAuthResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceStatResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2501,16 +4908,16 @@ func (p *WfsIfaceAuthResult) Read(ctx context.Context, iprot thrift.TProtocol) e
   return nil
 }
 
-func (p *WfsIfaceAuthResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
-  p.Success = &WfsAck{}
+func (p *WfsIfaceStatResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+  p.Success = &WfsStat{}
   if err := p.Success.Read(ctx, iprot); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
   }
   return nil
 }
 
-func (p *WfsIfaceAuthResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Auth_result"); err != nil {
+func (p *WfsIfaceStatResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "Stat_result"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField0(ctx, oprot); err != nil { return err }
@@ -2522,7 +4929,7 @@ func (p *WfsIfaceAuthResult) Write(ctx context.Context, oprot thrift.TProtocol)
   return nil
 }
 
-func (p *WfsIfaceAuthResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceStatResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if p.IsSetSuccess() {
     if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
       return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
@@ -2535,28 +4942,41 @@ func (p *WfsIfaceAuthResult) writeField0(ctx context.Context, oprot thrift.TProt
   return err
 }
 
-func (p *WfsIfaceAuthResult) String() string {
+func (p *WfsIfaceStatResult) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceAuthResult(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceStatResult(%+v)", *p)
 }
 
+
 // Attributes:
 //  - Path
-type WfsIfaceGetArgs struct {
+//  - Offset
+//  - Size
+type WfsIfaceGetChunkArgs struct {
   Path string `thrift:"path,1" db:"path" json:"path"`
+  Offset int64 `thrift:"offset,2" db:"offset" json:"offset"`
+  Size int32 `thrift:"size,3" db:"size" json:"size"`
 }
 
-func NewWfsIfaceGetArgs() *WfsIfaceGetArgs {
-  return &WfsIfaceGetArgs{}
+func NewWfsIfaceGetChunkArgs() *WfsIfaceGetChunkArgs {
+  return &WfsIfaceGetChunkArgs{}
 }
 
 
-func (p *WfsIfaceGetArgs) GetPath() string {
+func (p *WfsIfaceGetChunkArgs) GetPath() string {
   return p.Path
 }
-func (p *WfsIfaceGetArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
+
+func (p *WfsIfaceGetChunkArgs) GetOffset() int64 {
+  return p.Offset
+}
+
+func (p *WfsIfaceGetChunkArgs) GetSize() int32 {
+  return p.Size
+}
+func (p *WfsIfaceGetChunkArgs) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2579,6 +4999,26 @@ func (p *WfsIfaceGetArgs) Read(ctx context.Context, iprot thrift.TProtocol) erro
           return err
         }
       }
+    case 2:
+      if fieldTypeId == thrift.I64 {
+        if err := p.ReadField2(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
+    case 3:
+      if fieldTypeId == thrift.I32 {
+        if err := p.ReadField3(ctx, iprot); err != nil {
+          return err
+        }
+      } else {
+        if err := iprot.Skip(ctx, fieldTypeId); err != nil {
+          return err
+        }
+      }
     default:
       if err := iprot.Skip(ctx, fieldTypeId); err != nil {
         return err
@@ -2594,7 +5034,7 @@ func (p *WfsIfaceGetArgs) Read(ctx context.Context, iprot thrift.TProtocol) erro
   return nil
 }
 
-func (p *WfsIfaceGetArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetChunkArgs)  ReadField1(ctx context.Context, iprot thrift.TProtocol) error {
   if v, err := iprot.ReadString(ctx); err != nil {
   return thrift.PrependError("error reading field 1: ", err)
 } else {
@@ -2603,11 +5043,31 @@ func (p *WfsIfaceGetArgs)  ReadField1(ctx context.Context, iprot thrift.TProtoco
   return nil
 }
 
-func (p *WfsIfaceGetArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Get_args"); err != nil {
+func (p *WfsIfaceGetChunkArgs)  ReadField2(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI64(ctx); err != nil {
+  return thrift.PrependError("error reading field 2: ", err)
+} else {
+  p.Offset = v
+}
+  return nil
+}
+
+func (p *WfsIfaceGetChunkArgs)  ReadField3(ctx context.Context, iprot thrift.TProtocol) error {
+  if v, err := iprot.ReadI32(ctx); err != nil {
+  return thrift.PrependError("error reading field 3: ", err)
+} else {
+  p.Size = v
+}
+  return nil
+}
+
+func (p *WfsIfaceGetChunkArgs) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "GetChunk_args"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField1(ctx, oprot); err != nil { return err }
+    if err := p.writeField2(ctx, oprot); err != nil { return err }
+    if err := p.writeField3(ctx, oprot); err != nil { return err }
   }
   if err := oprot.WriteFieldStop(ctx); err != nil {
     return thrift.PrependError("write field stop error: ", err) }
@@ -2616,7 +5076,7 @@ func (p *WfsIfaceGetArgs) Write(ctx context.Context, oprot thrift.TProtocol) err
   return nil
 }
 
-func (p *WfsIfaceGetArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceGetChunkArgs) writeField1(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if err := oprot.WriteFieldBegin(ctx, "path", thrift.STRING, 1); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:path: ", p), err) }
   if err := oprot.WriteString(ctx, string(p.Path)); err != nil {
@@ -2626,35 +5086,56 @@ func (p *WfsIfaceGetArgs) writeField1(ctx context.Context, oprot thrift.TProtoco
   return err
 }
 
-func (p *WfsIfaceGetArgs) String() string {
+func (p *WfsIfaceGetChunkArgs) writeField2(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "offset", thrift.I64, 2); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:offset: ", p), err) }
+  if err := oprot.WriteI64(ctx, int64(p.Offset)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.offset (2) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 2:offset: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceGetChunkArgs) writeField3(ctx context.Context, oprot thrift.TProtocol) (err error) {
+  if err := oprot.WriteFieldBegin(ctx, "size", thrift.I32, 3); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:size: ", p), err) }
+  if err := oprot.WriteI32(ctx, int32(p.Size)); err != nil {
+  return thrift.PrependError(fmt.Sprintf("%T.size (3) field write error: ", p), err) }
+  if err := oprot.WriteFieldEnd(ctx); err != nil {
+    return thrift.PrependError(fmt.Sprintf("%T write field end error 3:size: ", p), err) }
+  return err
+}
+
+func (p *WfsIfaceGetChunkArgs) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceGetArgs(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceGetChunkArgs(%+v)", *p)
 }
 
+
 // Attributes:
 //  - Success
-type WfsIfaceGetResult struct {
+type WfsIfaceGetChunkResult struct {
   Success *WfsData `thrift:"success,0" db:"success" json:"success,omitempty"`
 }
 
-func NewWfsIfaceGetResult() *WfsIfaceGetResult {
-  return &WfsIfaceGetResult{}
+func NewWfsIfaceGetChunkResult() *WfsIfaceGetChunkResult {
+  return &WfsIfaceGetChunkResult{}
 }
 
-var WfsIfaceGetResult_Success_DEFAULT *WfsData
-func (p *WfsIfaceGetResult) GetSuccess() *WfsData {
+var WfsIfaceGetChunkResult_Success_DEFAULT *WfsData
+func (p *WfsIfaceGetChunkResult) GetSuccess() *WfsData {
   if !p.IsSetSuccess() {
-    return WfsIfaceGetResult_Success_DEFAULT
+    return WfsIfaceGetChunkResult_Success_DEFAULT
   }
 return p.Success
 }
-func (p *WfsIfaceGetResult) IsSetSuccess() bool {
+func (p *WfsIfaceGetChunkResult) IsSetSuccess() bool {
   return p.Success != nil
 }
 
-func (p *WfsIfaceGetResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetChunkResult) Read(ctx context.Context, iprot thrift.TProtocol) error {
   if _, err := iprot.ReadStructBegin(ctx); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
   }
@@ -2692,7 +5173,7 @@ func (p *WfsIfaceGetResult) Read(ctx context.Context, iprot thrift.TProtocol) er
   return nil
 }
 
-func (p *WfsIfaceGetResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
+func (p *WfsIfaceGetChunkResult)  ReadField0(ctx context.Context, iprot thrift.TProtocol) error {
   p.Success = &WfsData{}
   if err := p.Success.Read(ctx, iprot); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T error reading struct: ", p.Success), err)
@@ -2700,8 +5181,8 @@ func (p *WfsIfaceGetResult)  ReadField0(ctx context.Context, iprot thrift.TProto
   return nil
 }
 
-func (p *WfsIfaceGetResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
-  if err := oprot.WriteStructBegin(ctx, "Get_result"); err != nil {
+func (p *WfsIfaceGetChunkResult) Write(ctx context.Context, oprot thrift.TProtocol) error {
+  if err := oprot.WriteStructBegin(ctx, "GetChunk_result"); err != nil {
     return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err) }
   if p != nil {
     if err := p.writeField0(ctx, oprot); err != nil { return err }
@@ -2713,7 +5194,7 @@ func (p *WfsIfaceGetResult) Write(ctx context.Context, oprot thrift.TProtocol) e
   return nil
 }
 
-func (p *WfsIfaceGetResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
+func (p *WfsIfaceGetChunkResult) writeField0(ctx context.Context, oprot thrift.TProtocol) (err error) {
   if p.IsSetSuccess() {
     if err := oprot.WriteFieldBegin(ctx, "success", thrift.STRUCT, 0); err != nil {
       return thrift.PrependError(fmt.Sprintf("%T write field begin error 0:success: ", p), err) }
@@ -2726,13 +5207,14 @@ func (p *WfsIfaceGetResult) writeField0(ctx context.Context, oprot thrift.TProto
   return err
 }
 
-func (p *WfsIfaceGetResult) String() string {
+func (p *WfsIfaceGetChunkResult) String() string {
   if p == nil {
     return "<nil>"
   }
-  return fmt.Sprintf("WfsIfaceGetResult(%+v)", *p)
+  return fmt.Sprintf("WfsIfaceGetChunkResult(%+v)", *p)
 }
 
+
 type WfsIfacePingArgs struct {
 }
 
@@ -25,7 +25,13 @@ type WfsNodeBean struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Rmsize *int64 `protobuf:"varint,1,opt,name=rmsize" json:"rmsize,omitempty"`
+	Rmsize     *int64   `protobuf:"varint,1,opt,name=rmsize" json:"rmsize,omitempty"`
+	Hash       *int32   `protobuf:"varint,2,opt,name=hash" json:"hash,omitempty"`
+	Key        []byte   `protobuf:"bytes,3,opt,name=key" json:"key,omitempty"`
+	Dir        *string  `protobuf:"bytes,4,opt,name=dir" json:"dir,omitempty"`
+	Datashards *int32   `protobuf:"varint,5,opt,name=datashards" json:"datashards,omitempty"`
+	Codedsize  *int64   `protobuf:"varint,6,opt,name=codedsize" json:"codedsize,omitempty"`
+	Sharddirs  []string `protobuf:"bytes,7,rep,name=sharddirs" json:"sharddirs,omitempty"`
 }
 
 func (x *WfsNodeBean) Reset() {
@@ -67,16 +73,63 @@ func (x *WfsNodeBean) GetRmsize() int64 {
 	return 0
 }
 
+func (x *WfsNodeBean) GetHash() int32 {
+	if x != nil && x.Hash != nil {
+		return *x.Hash
+	}
+	return 0
+}
+
+func (x *WfsNodeBean) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *WfsNodeBean) GetDir() string {
+	if x != nil && x.Dir != nil {
+		return *x.Dir
+	}
+	return ""
+}
+
+func (x *WfsNodeBean) GetDatashards() int32 {
+	if x != nil && x.Datashards != nil {
+		return *x.Datashards
+	}
+	return 0
+}
+
+func (x *WfsNodeBean) GetCodedsize() int64 {
+	if x != nil && x.Codedsize != nil {
+		return *x.Codedsize
+	}
+	return 0
+}
+
+func (x *WfsNodeBean) GetSharddirs() []string {
+	if x != nil {
+		return x.Sharddirs
+	}
+	return nil
+}
+
 type WfsFileBean struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Storenode    *string `protobuf:"bytes,1,opt,name=storenode" json:"storenode,omitempty"`
-	Offset       *int64  `protobuf:"varint,2,opt,name=offset" json:"offset,omitempty"`
-	Size         *int64  `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
-	CompressType *int32  `protobuf:"varint,4,opt,name=compressType" json:"compressType,omitempty"`
-	Refercount   *int32  `protobuf:"varint,5,opt,name=refercount" json:"refercount,omitempty"`
+	Storenode    *string        `protobuf:"bytes,1,opt,name=storenode" json:"storenode,omitempty"`
+	Offset       *int64         `protobuf:"varint,2,opt,name=offset" json:"offset,omitempty"`
+	Size         *int64         `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
+	CompressType *int32         `protobuf:"varint,4,opt,name=compressType" json:"compressType,omitempty"`
+	Refercount   *int32         `protobuf:"varint,5,opt,name=refercount" json:"refercount,omitempty"`
+	Parts        []*WfsPartBean `protobuf:"bytes,6,rep,name=parts" json:"parts,omitempty"`
+	Datasize     *int64         `protobuf:"varint,7,opt,name=datasize" json:"datasize,omitempty"`
+	Digest       []byte         `protobuf:"bytes,8,opt,name=digest" json:"digest,omitempty"`
+	Chain        *int32         `protobuf:"varint,9,opt,name=chain" json:"chain,omitempty"`
+	Dict         *int32         `protobuf:"varint,10,opt,name=dict" json:"dict,omitempty"`
 }
 
 func (x *WfsFileBean) Reset() {
@@ -146,19 +199,159 @@ func (x *WfsFileBean) GetRefercount() int32 {
 	return 0
 }
 
+func (x *WfsFileBean) GetParts() []*WfsPartBean {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *WfsFileBean) GetDatasize() int64 {
+	if x != nil && x.Datasize != nil {
+		return *x.Datasize
+	}
+	return 0
+}
+
+func (x *WfsFileBean) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+func (x *WfsFileBean) GetChain() int32 {
+	if x != nil && x.Chain != nil {
+		return *x.Chain
+	}
+	return 0
+}
+
+func (x *WfsFileBean) GetDict() int32 {
+	if x != nil && x.Dict != nil {
+		return *x.Dict
+	}
+	return 0
+}
+
+type WfsDictBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id         *int32  `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Scope      *string `protobuf:"bytes,2,opt,name=scope" json:"scope,omitempty"`
+	Data       []byte  `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	Samples    *int32  `protobuf:"varint,4,opt,name=samples" json:"samples,omitempty"`
+	Size       *int64  `protobuf:"varint,5,opt,name=size" json:"size,omitempty"`
+	Plain      *int64  `protobuf:"varint,6,opt,name=plain" json:"plain,omitempty"`
+	Dicted     *int64  `protobuf:"varint,7,opt,name=dicted" json:"dicted,omitempty"`
+	Timestramp *int64  `protobuf:"varint,8,opt,name=timestramp" json:"timestramp,omitempty"`
+}
+
+func (x *WfsDictBean) Reset() {
+	*x = WfsDictBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsDictBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsDictBean) ProtoMessage() {}
+
+func (x *WfsDictBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsDictBean.ProtoReflect.Descriptor instead.
+func (*WfsDictBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WfsDictBean) GetId() int32 {
+	if x != nil && x.Id != nil {
+		return *x.Id
+	}
+	return 0
+}
+
+func (x *WfsDictBean) GetScope() string {
+	if x != nil && x.Scope != nil {
+		return *x.Scope
+	}
+	return ""
+}
+
+func (x *WfsDictBean) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *WfsDictBean) GetSamples() int32 {
+	if x != nil && x.Samples != nil {
+		return *x.Samples
+	}
+	return 0
+}
+
+func (x *WfsDictBean) GetSize() int64 {
+	if x != nil && x.Size != nil {
+		return *x.Size
+	}
+	return 0
+}
+
+func (x *WfsDictBean) GetPlain() int64 {
+	if x != nil && x.Plain != nil {
+		return *x.Plain
+	}
+	return 0
+}
+
+func (x *WfsDictBean) GetDicted() int64 {
+	if x != nil && x.Dicted != nil {
+		return *x.Dicted
+	}
+	return 0
+}
+
+func (x *WfsDictBean) GetTimestramp() int64 {
+	if x != nil && x.Timestramp != nil {
+		return *x.Timestramp
+	}
+	return 0
+}
+
 type WfsPathBean struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Path       *string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
-	Timestramp *int64  `protobuf:"varint,2,opt,name=timestramp" json:"timestramp,omitempty"`
+	Path        *string           `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Timestramp  *int64            `protobuf:"varint,2,opt,name=timestramp" json:"timestramp,omitempty"`
+	ContentType *string           `protobuf:"bytes,3,opt,name=contentType" json:"contentType,omitempty"`
+	Meta        map[string]string `protobuf:"bytes,4,rep,name=meta" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (x *WfsPathBean) Reset() {
 	*x = WfsPathBean{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_wfs_proto_msgTypes[2]
+		mi := &file_wfs_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -171,7 +364,7 @@ func (x *WfsPathBean) String() string {
 func (*WfsPathBean) ProtoMessage() {}
 
 func (x *WfsPathBean) ProtoReflect() protoreflect.Message {
-	mi := &file_wfs_proto_msgTypes[2]
+	mi := &file_wfs_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -184,7 +377,7 @@ func (x *WfsPathBean) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WfsPathBean.ProtoReflect.Descriptor instead.
 func (*WfsPathBean) Descriptor() ([]byte, []int) {
-	return file_wfs_proto_rawDescGZIP(), []int{2}
+	return file_wfs_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *WfsPathBean) GetPath() string {
@@ -201,6 +394,20 @@ func (x *WfsPathBean) GetTimestramp() int64 {
 	return 0
 }
 
+func (x *WfsPathBean) GetContentType() string {
+	if x != nil && x.ContentType != nil {
+		return *x.ContentType
+	}
+	return ""
+}
+
+func (x *WfsPathBean) GetMeta() map[string]string {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
 type SnapshotBean struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -213,7 +420,7 @@ type SnapshotBean struct {
 func (x *SnapshotBean) Reset() {
 	*x = SnapshotBean{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_wfs_proto_msgTypes[3]
+		mi := &file_wfs_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -226,7 +433,7 @@ func (x *SnapshotBean) String() string {
 func (*SnapshotBean) ProtoMessage() {}
 
 func (x *SnapshotBean) ProtoReflect() protoreflect.Message {
-	mi := &file_wfs_proto_msgTypes[3]
+	mi := &file_wfs_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +446,7 @@ func (x *SnapshotBean) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotBean.ProtoReflect.Descriptor instead.
 func (*SnapshotBean) Descriptor() ([]byte, []int) {
-	return file_wfs_proto_rawDescGZIP(), []int{3}
+	return file_wfs_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *SnapshotBean) GetKey() []byte {
@@ -268,7 +475,7 @@ type SnapshotBeans struct {
 func (x *SnapshotBeans) Reset() {
 	*x = SnapshotBeans{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_wfs_proto_msgTypes[4]
+		mi := &file_wfs_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -281,7 +488,7 @@ func (x *SnapshotBeans) String() string {
 func (*SnapshotBeans) ProtoMessage() {}
 
 func (x *SnapshotBeans) ProtoReflect() protoreflect.Message {
-	mi := &file_wfs_proto_msgTypes[4]
+	mi := &file_wfs_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -294,7 +501,7 @@ func (x *SnapshotBeans) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotBeans.ProtoReflect.Descriptor instead.
 func (*SnapshotBeans) Descriptor() ([]byte, []int) {
-	return file_wfs_proto_rawDescGZIP(), []int{4}
+	return file_wfs_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SnapshotBeans) GetId() int64 {
@@ -316,16 +523,19 @@ type SnapshotFile struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id           *int64  `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
-	Path         *string `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
-	Data         []byte  `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
-	CompressType *int32  `protobuf:"varint,4,opt,name=compressType" json:"compressType,omitempty"`
+	Id           *int64            `protobuf:"varint,1,opt,name=id" json:"id,omitempty"`
+	Path         *string           `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Data         []byte            `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+	CompressType *int32            `protobuf:"varint,4,opt,name=compressType" json:"compressType,omitempty"`
+	ContentType  *string           `protobuf:"bytes,5,opt,name=contentType" json:"contentType,omitempty"`
+	Meta         map[string]string `protobuf:"bytes,6,rep,name=meta" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Expire       *int64            `protobuf:"varint,7,opt,name=expire" json:"expire,omitempty"`
 }
 
 func (x *SnapshotFile) Reset() {
 	*x = SnapshotFile{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_wfs_proto_msgTypes[5]
+		mi := &file_wfs_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -338,7 +548,7 @@ func (x *SnapshotFile) String() string {
 func (*SnapshotFile) ProtoMessage() {}
 
 func (x *SnapshotFile) ProtoReflect() protoreflect.Message {
-	mi := &file_wfs_proto_msgTypes[5]
+	mi := &file_wfs_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -351,7 +561,7 @@ func (x *SnapshotFile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SnapshotFile.ProtoReflect.Descriptor instead.
 func (*SnapshotFile) Descriptor() ([]byte, []int) {
-	return file_wfs_proto_rawDescGZIP(), []int{5}
+	return file_wfs_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *SnapshotFile) GetId() int64 {
@@ -382,97 +592,758 @@ func (x *SnapshotFile) GetCompressType() int32 {
 	return 0
 }
 
-var File_wfs_proto protoreflect.FileDescriptor
+func (x *SnapshotFile) GetContentType() string {
+	if x != nil && x.ContentType != nil {
+		return *x.ContentType
+	}
+	return ""
+}
 
-var file_wfs_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x77, 0x66, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x73, 0x74, 0x75,
-	0x62, 0x22, 0x25, 0x0a, 0x0b, 0x57, 0x66, 0x73, 0x4e, 0x6f, 0x64, 0x65, 0x42, 0x65, 0x61, 0x6e,
-	0x12, 0x16, 0x0a, 0x06, 0x72, 0x6d, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x06, 0x72, 0x6d, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x9b, 0x01, 0x0a, 0x0b, 0x57, 0x66, 0x73,
-	0x46, 0x69, 0x6c, 0x65, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x6f, 0x72,
-	0x65, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x74, 0x6f,
-	0x72, 0x65, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x12,
-	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69,
-	0x7a, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x54, 0x79,
-	0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65,
-	0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x72, 0x65, 0x66, 0x65, 0x72, 0x63,
-	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x72, 0x65, 0x66, 0x65,
-	0x72, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x41, 0x0a, 0x0b, 0x57, 0x66, 0x73, 0x50, 0x61, 0x74,
-	0x68, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x22, 0x36, 0x0a, 0x0c, 0x53, 0x6e, 0x61,
-	0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x49, 0x0a, 0x0d, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x65, 0x61,
-	0x6e, 0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x28, 0x0a, 0x05, 0x62, 0x65, 0x61, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x12, 0x2e, 0x73, 0x74, 0x75, 0x62, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x42, 0x65, 0x61, 0x6e, 0x52, 0x05, 0x62, 0x65, 0x61, 0x6e, 0x73, 0x22, 0x6a, 0x0a, 0x0c,
-	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04,
-	0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
-	0x54, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x70,
-	0x72, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x2f, 0x73, 0x74,
-	0x75, 0x62,
+func (x *SnapshotFile) GetMeta() map[string]string {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
 }
 
-var (
-	file_wfs_proto_rawDescOnce sync.Once
-	file_wfs_proto_rawDescData = file_wfs_proto_rawDesc
-)
+func (x *SnapshotFile) GetExpire() int64 {
+	if x != nil && x.Expire != nil {
+		return *x.Expire
+	}
+	return 0
+}
 
-func file_wfs_proto_rawDescGZIP() []byte {
-	file_wfs_proto_rawDescOnce.Do(func() {
-		file_wfs_proto_rawDescData = protoimpl.X.CompressGZIP(file_wfs_proto_rawDescData)
-	})
-	return file_wfs_proto_rawDescData
+type WfsPartBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Number      *int32 `protobuf:"varint,1,opt,name=number" json:"number,omitempty"`
+	Fingerprint []byte `protobuf:"bytes,2,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	Size        *int64 `protobuf:"varint,3,opt,name=size" json:"size,omitempty"`
 }
 
-var file_wfs_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_wfs_proto_goTypes = []interface{}{
-	(*WfsNodeBean)(nil),   // 0: stub.WfsNodeBean
-	(*WfsFileBean)(nil),   // 1: stub.WfsFileBean
-	(*WfsPathBean)(nil),   // 2: stub.WfsPathBean
-	(*SnapshotBean)(nil),  // 3: stub.SnapshotBean
-	(*SnapshotBeans)(nil), // 4: stub.SnapshotBeans
-	(*SnapshotFile)(nil),  // 5: stub.SnapshotFile
+func (x *WfsPartBean) Reset() {
+	*x = WfsPartBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_wfs_proto_depIdxs = []int32{
-	3, // 0: stub.SnapshotBeans.beans:type_name -> stub.SnapshotBean
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+
+func (x *WfsPartBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_wfs_proto_init() }
-func file_wfs_proto_init() {
-	if File_wfs_proto != nil {
-		return
+func (*WfsPartBean) ProtoMessage() {}
+
+func (x *WfsPartBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_wfs_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WfsNodeBean); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsPartBean.ProtoReflect.Descriptor instead.
+func (*WfsPartBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *WfsPartBean) GetNumber() int32 {
+	if x != nil && x.Number != nil {
+		return *x.Number
+	}
+	return 0
+}
+
+func (x *WfsPartBean) GetFingerprint() []byte {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return nil
+}
+
+func (x *WfsPartBean) GetSize() int64 {
+	if x != nil && x.Size != nil {
+		return *x.Size
+	}
+	return 0
+}
+
+type WfsUploadBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path         *string           `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	Timestramp   *int64            `protobuf:"varint,2,opt,name=timestramp" json:"timestramp,omitempty"`
+	CompressType *int32            `protobuf:"varint,3,opt,name=compressType" json:"compressType,omitempty"`
+	Parts        []*WfsPartBean    `protobuf:"bytes,4,rep,name=parts" json:"parts,omitempty"`
+	ContentType  *string           `protobuf:"bytes,5,opt,name=contentType" json:"contentType,omitempty"`
+	Meta         map[string]string `protobuf:"bytes,6,rep,name=meta" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Expire       *int64            `protobuf:"varint,7,opt,name=expire" json:"expire,omitempty"`
+}
+
+func (x *WfsUploadBean) Reset() {
+	*x = WfsUploadBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsUploadBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsUploadBean) ProtoMessage() {}
+
+func (x *WfsUploadBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_wfs_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WfsFileBean); i {
-			case 0:
-				return &v.state
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsUploadBean.ProtoReflect.Descriptor instead.
+func (*WfsUploadBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WfsUploadBean) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *WfsUploadBean) GetTimestramp() int64 {
+	if x != nil && x.Timestramp != nil {
+		return *x.Timestramp
+	}
+	return 0
+}
+
+func (x *WfsUploadBean) GetCompressType() int32 {
+	if x != nil && x.CompressType != nil {
+		return *x.CompressType
+	}
+	return 0
+}
+
+func (x *WfsUploadBean) GetParts() []*WfsPartBean {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *WfsUploadBean) GetContentType() string {
+	if x != nil && x.ContentType != nil {
+		return *x.ContentType
+	}
+	return ""
+}
+
+func (x *WfsUploadBean) GetMeta() map[string]string {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *WfsUploadBean) GetExpire() int64 {
+	if x != nil && x.Expire != nil {
+		return *x.Expire
+	}
+	return 0
+}
+
+type WfsJournalBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Op           *int32            `protobuf:"varint,1,opt,name=op" json:"op,omitempty"`
+	Path         *string           `protobuf:"bytes,2,opt,name=path" json:"path,omitempty"`
+	Fingerprint  []byte            `protobuf:"bytes,3,opt,name=fingerprint" json:"fingerprint,omitempty"`
+	Parts        []*WfsPartBean    `protobuf:"bytes,4,rep,name=parts" json:"parts,omitempty"`
+	CompressType *int32            `protobuf:"varint,5,opt,name=compressType" json:"compressType,omitempty"`
+	Timestramp   *int64            `protobuf:"varint,6,opt,name=timestramp" json:"timestramp,omitempty"`
+	ContentType  *string           `protobuf:"bytes,7,opt,name=contentType" json:"contentType,omitempty"`
+	Meta         map[string]string `protobuf:"bytes,8,rep,name=meta" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Expire       *int64            `protobuf:"varint,9,opt,name=expire" json:"expire,omitempty"`
+	Newpath      *string           `protobuf:"bytes,10,opt,name=newpath" json:"newpath,omitempty"`
+}
+
+func (x *WfsJournalBean) Reset() {
+	*x = WfsJournalBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsJournalBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsJournalBean) ProtoMessage() {}
+
+func (x *WfsJournalBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsJournalBean.ProtoReflect.Descriptor instead.
+func (*WfsJournalBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WfsJournalBean) GetOp() int32 {
+	if x != nil && x.Op != nil {
+		return *x.Op
+	}
+	return 0
+}
+
+func (x *WfsJournalBean) GetPath() string {
+	if x != nil && x.Path != nil {
+		return *x.Path
+	}
+	return ""
+}
+
+func (x *WfsJournalBean) GetFingerprint() []byte {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return nil
+}
+
+func (x *WfsJournalBean) GetParts() []*WfsPartBean {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *WfsJournalBean) GetCompressType() int32 {
+	if x != nil && x.CompressType != nil {
+		return *x.CompressType
+	}
+	return 0
+}
+
+func (x *WfsJournalBean) GetTimestramp() int64 {
+	if x != nil && x.Timestramp != nil {
+		return *x.Timestramp
+	}
+	return 0
+}
+
+func (x *WfsJournalBean) GetContentType() string {
+	if x != nil && x.ContentType != nil {
+		return *x.ContentType
+	}
+	return ""
+}
+
+func (x *WfsJournalBean) GetMeta() map[string]string {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *WfsJournalBean) GetExpire() int64 {
+	if x != nil && x.Expire != nil {
+		return *x.Expire
+	}
+	return 0
+}
+
+func (x *WfsJournalBean) GetNewpath() string {
+	if x != nil && x.Newpath != nil {
+		return *x.Newpath
+	}
+	return ""
+}
+
+type WfsIntentBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Journal *WfsJournalBean `protobuf:"bytes,1,opt,name=journal" json:"journal,omitempty"`
+	Stored  *bool           `protobuf:"varint,2,opt,name=stored" json:"stored,omitempty"`
+	Bound   *bool           `protobuf:"varint,3,opt,name=bound" json:"bound,omitempty"`
+	Created *bool           `protobuf:"varint,4,opt,name=created" json:"created,omitempty"`
+}
+
+func (x *WfsIntentBean) Reset() {
+	*x = WfsIntentBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsIntentBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsIntentBean) ProtoMessage() {}
+
+func (x *WfsIntentBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsIntentBean.ProtoReflect.Descriptor instead.
+func (*WfsIntentBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WfsIntentBean) GetJournal() *WfsJournalBean {
+	if x != nil {
+		return x.Journal
+	}
+	return nil
+}
+
+func (x *WfsIntentBean) GetStored() bool {
+	if x != nil && x.Stored != nil {
+		return *x.Stored
+	}
+	return false
+}
+
+func (x *WfsIntentBean) GetBound() bool {
+	if x != nil && x.Bound != nil {
+		return *x.Bound
+	}
+	return false
+}
+
+func (x *WfsIntentBean) GetCreated() bool {
+	if x != nil && x.Created != nil {
+		return *x.Created
+	}
+	return false
+}
+
+type WfsDefragBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Starttime *int64   `protobuf:"varint,1,opt,name=starttime" json:"starttime,omitempty"`
+	Endtime   *int64   `protobuf:"varint,2,opt,name=endtime" json:"endtime,omitempty"`
+	Auto      *bool    `protobuf:"varint,3,opt,name=auto" json:"auto,omitempty"`
+	Nodes     *int32   `protobuf:"varint,4,opt,name=nodes" json:"nodes,omitempty"`
+	Reclaimed *int64   `protobuf:"varint,5,opt,name=reclaimed" json:"reclaimed,omitempty"`
+	Errors    []string `protobuf:"bytes,6,rep,name=errors" json:"errors,omitempty"`
+}
+
+func (x *WfsDefragBean) Reset() {
+	*x = WfsDefragBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsDefragBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsDefragBean) ProtoMessage() {}
+
+func (x *WfsDefragBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsDefragBean.ProtoReflect.Descriptor instead.
+func (*WfsDefragBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WfsDefragBean) GetStarttime() int64 {
+	if x != nil && x.Starttime != nil {
+		return *x.Starttime
+	}
+	return 0
+}
+
+func (x *WfsDefragBean) GetEndtime() int64 {
+	if x != nil && x.Endtime != nil {
+		return *x.Endtime
+	}
+	return 0
+}
+
+func (x *WfsDefragBean) GetAuto() bool {
+	if x != nil && x.Auto != nil {
+		return *x.Auto
+	}
+	return false
+}
+
+func (x *WfsDefragBean) GetNodes() int32 {
+	if x != nil && x.Nodes != nil {
+		return *x.Nodes
+	}
+	return 0
+}
+
+func (x *WfsDefragBean) GetReclaimed() int64 {
+	if x != nil && x.Reclaimed != nil {
+		return *x.Reclaimed
+	}
+	return 0
+}
+
+func (x *WfsDefragBean) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+type WfsCompactBean struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Node   *string `protobuf:"bytes,1,opt,name=node" json:"node,omitempty"`
+	Offset *int64  `protobuf:"varint,2,opt,name=offset" json:"offset,omitempty"`
+	Length *int64  `protobuf:"varint,3,opt,name=length" json:"length,omitempty"`
+}
+
+func (x *WfsCompactBean) Reset() {
+	*x = WfsCompactBean{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_wfs_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WfsCompactBean) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WfsCompactBean) ProtoMessage() {}
+
+func (x *WfsCompactBean) ProtoReflect() protoreflect.Message {
+	mi := &file_wfs_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WfsCompactBean.ProtoReflect.Descriptor instead.
+func (*WfsCompactBean) Descriptor() ([]byte, []int) {
+	return file_wfs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WfsCompactBean) GetNode() string {
+	if x != nil && x.Node != nil {
+		return *x.Node
+	}
+	return ""
+}
+
+func (x *WfsCompactBean) GetOffset() int64 {
+	if x != nil && x.Offset != nil {
+		return *x.Offset
+	}
+	return 0
+}
+
+func (x *WfsCompactBean) GetLength() int64 {
+	if x != nil && x.Length != nil {
+		return *x.Length
+	}
+	return 0
+}
+
+var File_wfs_proto protoreflect.FileDescriptor
+
+var file_wfs_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x77, 0x66, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x73, 0x74, 0x75,
+	0x62, 0x22, 0xb9, 0x01, 0x0a, 0x0b, 0x57, 0x66, 0x73, 0x4e, 0x6f, 0x64, 0x65, 0x42, 0x65, 0x61,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x6d, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x72, 0x6d, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x64, 0x69, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x69,
+	0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x73, 0x68, 0x61, 0x72, 0x64,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1c, 0x0a, 0x09, 0x73, 0x68, 0x61, 0x72, 0x64, 0x64, 0x69, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x68, 0x61, 0x72, 0x64, 0x64, 0x69, 0x72, 0x73, 0x22, 0xa2, 0x02,
+	0x0a, 0x0b, 0x57, 0x66, 0x73, 0x46, 0x69, 0x6c, 0x65, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x1c, 0x0a,
+	0x09, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66,
+	0x73, 0x65, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72,
+	0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63,
+	0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x72,
+	0x65, 0x66, 0x65, 0x72, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x72, 0x65, 0x66, 0x65, 0x72, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x05, 0x70,
+	0x61, 0x72, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x74, 0x75,
+	0x62, 0x2e, 0x57, 0x66, 0x73, 0x50, 0x61, 0x72, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x52, 0x05, 0x70,
+	0x61, 0x72, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x61, 0x74, 0x61, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x64, 0x61, 0x74, 0x61, 0x73, 0x69, 0x7a, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x06, 0x64, 0x69, 0x67, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x69, 0x63, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x64, 0x69,
+	0x63, 0x74, 0x22, 0xc3, 0x01, 0x0a, 0x0b, 0x57, 0x66, 0x73, 0x44, 0x69, 0x63, 0x74, 0x42, 0x65,
+	0x61, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6c,
+	0x61, 0x69, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x70, 0x6c, 0x61, 0x69, 0x6e,
+	0x12, 0x16, 0x0a, 0x06, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x64, 0x69, 0x63, 0x74, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x22, 0xcd, 0x01, 0x0a, 0x0b, 0x57, 0x66, 0x73,
+	0x50, 0x61, 0x74, 0x68, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1e, 0x0a, 0x0a,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x12, 0x20, 0x0a, 0x0b,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x2f,
+	0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73,
+	0x74, 0x75, 0x62, 0x2e, 0x57, 0x66, 0x73, 0x50, 0x61, 0x74, 0x68, 0x42, 0x65, 0x61, 0x6e, 0x2e,
+	0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x1a,
+	0x37, 0x0a, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x36, 0x0a, 0x0c, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x22, 0x49, 0x0a, 0x0d, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x42, 0x65, 0x61, 0x6e,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x28, 0x0a, 0x05, 0x62, 0x65, 0x61, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x12, 0x2e, 0x73, 0x74, 0x75, 0x62, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x42, 0x65, 0x61, 0x6e, 0x52, 0x05, 0x62, 0x65, 0x61, 0x6e, 0x73, 0x22, 0x8f, 0x02, 0x0a, 0x0c,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x54, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x70,
+	0x72, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x30, 0x0a, 0x04, 0x6d, 0x65,
+	0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x74, 0x75, 0x62, 0x2e,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x2e, 0x4d, 0x65, 0x74,
+	0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x65, 0x78,
+	0x70, 0x69, 0x72, 0x65, 0x1a, 0x37, 0x0a, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5b, 0x0a,
+	0x0b, 0x57, 0x66, 0x73, 0x50, 0x61, 0x72, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x6e, 0x75,
+	0x6d, 0x62, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72,
+	0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65,
+	0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0xb6, 0x02, 0x0a, 0x0d, 0x57,
+	0x66, 0x73, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70,
+	0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73, 0x73,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x27, 0x0a, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x74, 0x75, 0x62, 0x2e, 0x57, 0x66, 0x73, 0x50, 0x61,
+	0x72, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x52, 0x05, 0x70, 0x61, 0x72, 0x74, 0x73, 0x12, 0x20, 0x0a,
+	0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x31, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x73, 0x74, 0x75, 0x62, 0x2e, 0x57, 0x66, 0x73, 0x55, 0x70, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x65,
+	0x61, 0x6e, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6d, 0x65,
+	0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x1a, 0x37, 0x0a, 0x09, 0x4d, 0x65,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x84, 0x03, 0x0a, 0x0e, 0x57, 0x66, 0x73, 0x4a, 0x6f, 0x75, 0x72, 0x6e,
+	0x61, 0x6c, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x02, 0x6f, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x20, 0x0a, 0x0b, 0x66, 0x69,
+	0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0b, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x05,
+	0x70, 0x61, 0x72, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x74,
+	0x75, 0x62, 0x2e, 0x57, 0x66, 0x73, 0x50, 0x61, 0x72, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x52, 0x05,
+	0x70, 0x61, 0x72, 0x74, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x70, 0x72, 0x65, 0x73,
+	0x73, 0x54, 0x79, 0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x63, 0x6f, 0x6d,
+	0x70, 0x72, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x72, 0x61, 0x6d, 0x70, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e,
+	0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x32, 0x0a, 0x04, 0x6d,
+	0x65, 0x74, 0x61, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x74, 0x75, 0x62,
+	0x2e, 0x57, 0x66, 0x73, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x42, 0x65, 0x61, 0x6e, 0x2e,
+	0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x12,
+	0x16, 0x0a, 0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6e, 0x65, 0x77, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x65, 0x77, 0x70, 0x61, 0x74,
+	0x68, 0x1a, 0x37, 0x0a, 0x09, 0x4d, 0x65, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x87, 0x01, 0x0a, 0x0d, 0x57,
+	0x66, 0x73, 0x49, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x2e, 0x0a, 0x07,
+	0x6a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e,
+	0x73, 0x74, 0x75, 0x62, 0x2e, 0x57, 0x66, 0x73, 0x4a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x42,
+	0x65, 0x61, 0x6e, 0x52, 0x07, 0x6a, 0x6f, 0x75, 0x72, 0x6e, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x05, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x22, 0xa7, 0x01, 0x0a, 0x0d, 0x57, 0x66, 0x73, 0x44, 0x65, 0x66, 0x72,
+	0x61, 0x67, 0x42, 0x65, 0x61, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x64, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x75, 0x74, 0x6f, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x61, 0x75,
+	0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x6c,
+	0x61, 0x69, 0x6d, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x65, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x54,
+	0x0a, 0x0e, 0x57, 0x66, 0x73, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x42, 0x65, 0x61, 0x6e,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x65,
+	0x6e, 0x67, 0x74, 0x68, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x2f, 0x73, 0x74, 0x75, 0x62,
+}
+
+var (
+	file_wfs_proto_rawDescOnce sync.Once
+	file_wfs_proto_rawDescData = file_wfs_proto_rawDesc
+)
+
+func file_wfs_proto_rawDescGZIP() []byte {
+	file_wfs_proto_rawDescOnce.Do(func() {
+		file_wfs_proto_rawDescData = protoimpl.X.CompressGZIP(file_wfs_proto_rawDescData)
+	})
+	return file_wfs_proto_rawDescData
+}
+
+var file_wfs_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_wfs_proto_goTypes = []interface{}{
+	(*WfsNodeBean)(nil),    // 0: stub.WfsNodeBean
+	(*WfsFileBean)(nil),    // 1: stub.WfsFileBean
+	(*WfsDictBean)(nil),    // 2: stub.WfsDictBean
+	(*WfsPathBean)(nil),    // 3: stub.WfsPathBean
+	(*SnapshotBean)(nil),   // 4: stub.SnapshotBean
+	(*SnapshotBeans)(nil),  // 5: stub.SnapshotBeans
+	(*SnapshotFile)(nil),   // 6: stub.SnapshotFile
+	(*WfsPartBean)(nil),    // 7: stub.WfsPartBean
+	(*WfsUploadBean)(nil),  // 8: stub.WfsUploadBean
+	(*WfsJournalBean)(nil), // 9: stub.WfsJournalBean
+	(*WfsIntentBean)(nil),  // 10: stub.WfsIntentBean
+	(*WfsDefragBean)(nil),  // 11: stub.WfsDefragBean
+	(*WfsCompactBean)(nil), // 12: stub.WfsCompactBean
+	nil,                    // 13: stub.WfsPathBean.MetaEntry
+	nil,                    // 14: stub.SnapshotFile.MetaEntry
+	nil,                    // 15: stub.WfsUploadBean.MetaEntry
+	nil,                    // 16: stub.WfsJournalBean.MetaEntry
+}
+var file_wfs_proto_depIdxs = []int32{
+	7,  // 0: stub.WfsFileBean.parts:type_name -> stub.WfsPartBean
+	13, // 1: stub.WfsPathBean.meta:type_name -> stub.WfsPathBean.MetaEntry
+	4,  // 2: stub.SnapshotBeans.beans:type_name -> stub.SnapshotBean
+	14, // 3: stub.SnapshotFile.meta:type_name -> stub.SnapshotFile.MetaEntry
+	7,  // 4: stub.WfsUploadBean.parts:type_name -> stub.WfsPartBean
+	15, // 5: stub.WfsUploadBean.meta:type_name -> stub.WfsUploadBean.MetaEntry
+	7,  // 6: stub.WfsJournalBean.parts:type_name -> stub.WfsPartBean
+	16, // 7: stub.WfsJournalBean.meta:type_name -> stub.WfsJournalBean.MetaEntry
+	9,  // 8: stub.WfsIntentBean.journal:type_name -> stub.WfsJournalBean
+	9,  // [9:9] is the sub-list for method output_type
+	9,  // [9:9] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_wfs_proto_init() }
+func file_wfs_proto_init() {
+	if File_wfs_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_wfs_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsNodeBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsFileBean); i {
+			case 0:
+				return &v.state
 			case 1:
 				return &v.sizeCache
 			case 2:
@@ -482,7 +1353,7 @@ func file_wfs_proto_init() {
 			}
 		}
 		file_wfs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*WfsPathBean); i {
+			switch v := v.(*WfsDictBean); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -494,7 +1365,7 @@ func file_wfs_proto_init() {
 			}
 		}
 		file_wfs_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SnapshotBean); i {
+			switch v := v.(*WfsPathBean); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -506,7 +1377,7 @@ func file_wfs_proto_init() {
 			}
 		}
 		file_wfs_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SnapshotBeans); i {
+			switch v := v.(*SnapshotBean); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -518,6 +1389,18 @@ func file_wfs_proto_init() {
 			}
 		}
 		file_wfs_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SnapshotBeans); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SnapshotFile); i {
 			case 0:
 				return &v.state
@@ -529,6 +1412,78 @@ func file_wfs_proto_init() {
 				return nil
 			}
 		}
+		file_wfs_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsPartBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsUploadBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsJournalBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsIntentBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsDefragBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_wfs_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WfsCompactBean); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -536,7 +1491,7 @@ func file_wfs_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_wfs_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
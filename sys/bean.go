@@ -7,49 +7,154 @@
 
 package sys
 
+import "io"
+
 type Server interface {
 	Serve() (err error)
 	Close() (err error)
 }
 
 type ConfBean struct {
-	FileSize           int64     `json:"filesize"`
-	Opaddr             *string   `json:"opaddr"`
-	WebAddr            *string   `json:"webaddr"`
-	Listen             int       `json:"listen"`
-	Admin_Ssl_crt      string    `json:"admin.ssl_certificate"`
-	Admin_Ssl_crt_key  string    `json:"admin.ssl_certificate_key"`
-	Ssl_crt            string    `json:"ssl_certificate"`
-	Ssl_crt_key        string    `json:"ssl_certificate_key"`
-	Memlimit           int64     `json:"memlimit"`
-	DataMaxsize        int64     `json:"data.maxsize"`
-	Init               bool      `json:"init"`
-	Keystore           *string   `json:"keystore"`
-	Mode               *int      `json:"mode"`
-	Sync               *bool     `json:"sync"`
-	Compress           *int32    `json:"compress"`
-	WfsData            *string   `json:"data.dir"`
-	SLASH              bool      `json:"prefix.slash"`
-	MaxSigma           float64   `json:"maxsigma"`
-	MaxSide            int       `json:"maxside"`
-	MaxPixel           int       `json:"maxpixel"`
-	Resample           int8      `json:"resample"`
-	ImgViewingRevProxy string    `json:"imgViewingRevProxy"`
-	FileHash           *int      `json:"filehash"`
-	AdminUserName      *string   `json:"adminusername"`
-	AdminPassword      *string   `json:"adminpassword"`
-	Restrict           *int      `json:"restrict"`
-	DBType             int       `json:"dbtype"`
-	DBConfig           *DBConfig `json:"db"`
+	FileSize           int64            `json:"filesize"`
+	Opaddr             *string          `json:"opaddr"`
+	WebAddr            *string          `json:"webaddr"`
+	Listen             int              `json:"listen"`
+	Admin_Ssl_crt      string           `json:"admin.ssl_certificate"`
+	Admin_Ssl_crt_key  string           `json:"admin.ssl_certificate_key"`
+	Ssl_crt            string           `json:"ssl_certificate"`
+	Ssl_crt_key        string           `json:"ssl_certificate_key"`
+	Memlimit           int64            `json:"memlimit"`
+	DataMaxsize        int64            `json:"data.maxsize"`
+	Init               bool             `json:"init"`
+	Keystore           *string          `json:"keystore"`
+	Mode               *int             `json:"mode"`
+	Sync               *bool            `json:"sync"`
+	Compress           *int32           `json:"compress"`
+	WfsData            *string          `json:"data.dir"`
+	SLASH              bool             `json:"prefix.slash"`
+	MaxSigma           float64          `json:"maxsigma"`
+	MaxSide            int              `json:"maxside"`
+	MaxPixel           int              `json:"maxpixel"`
+	Resample           int8             `json:"resample"`
+	ImgViewingRevProxy string           `json:"imgViewingRevProxy"`
+	FileHash           *int             `json:"filehash"`
+	AdminUserName      *string          `json:"adminusername"`
+	AdminPassword      *string          `json:"adminpassword"`
+	Restrict           *int             `json:"restrict"`
+	DBType             int              `json:"dbtype"`
+	S3Listen           int              `json:"s3.listen"`
+	DBConfig           *DBConfig        `json:"db"`
+	TTL                map[string]int64 `json:"ttl"`
+	TTLInterval        int              `json:"ttl.interval"`
+	UploadTTL          int64            `json:"upload.ttl"`
+	ScrubRate          int              `json:"scrub.rate"`
+	ScrubInterval      int              `json:"scrub.interval"`
+	ScrubQuarantine    bool             `json:"scrub.quarantine"`
+	Journal            *bool            `json:"journal"`
+	DedupVerify        bool             `json:"dedup.verify"`
+	CompressRules      map[string]int32 `json:"compress.rules"`
+	ZstdDict           bool             `json:"zstd.dict"`
+	ZstdDictPrefix     []string         `json:"zstd.dict.prefix"`
+	Encrypt            bool             `json:"encrypt"`
+	EncryptKeyFile     string           `json:"encrypt.keyfile"`
+	DataDirs           []*DataDirBean   `json:"data.dirs"`
+	ErasureData        int              `json:"erasure.data"`
+	ErasureParity      int              `json:"erasure.parity"`
+	TierHot            string           `json:"tier.hot"`
+	TierCold           string           `json:"tier.cold"`
+	TierAge            int64            `json:"tier.age"`
+	TierCompress       *int32           `json:"tier.compress"`
+	TierPromote        int              `json:"tier.promote"`
+	DefragRatio        float64          `json:"defrag.ratio"`
+	DefragWindow       []string         `json:"defrag.window"`
+	DefragRate         int              `json:"defrag.rate"`
+	DefragCPU          int              `json:"defrag.cpu"`
+	DefragNodes        int              `json:"defrag.nodes"`
+	DefragMerge        float64          `json:"defrag.merge"`
+	MmapSize           int64            `json:"mmap.size"`
+	MmapNodes          int              `json:"mmap.nodes"`
+}
+
+type DataDirBean struct {
+	Path   string `json:"path"`
+	Weight int    `json:"weight"`
 }
 
 type PathBean struct {
 	Id         int64
 	Path       string
 	Body       []byte
+	Size       int64
 	Timestramp int64
 }
 
+// DataBean streams the data of a path by its Reader, it is closed once read
+type DataBean struct {
+	Reader      io.ReadSeeker
+	Size        int64
+	Fingerprint []byte
+	Timestramp  int64
+	ContentType string
+	Meta        map[string]string
+	// Encoding is the Content-Encoding of the stored bytes read by GetStored
+	Encoding string
+}
+
+// Close gives back what the Reader holds, as the map of the node it reads from
+func (t *DataBean) Close() {
+	if c, ok := t.Reader.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// Decode turns the Reader of the stored bytes of GetStored into the Reader of the data, without reading the path
+// again. It returns false if the Reader cannot decode them, the bean is closed then.
+func (t *DataBean) Decode() bool {
+	if d, ok := t.Reader.(interface{ Decode() (int64, error) }); ok {
+		if size, err := d.Decode(); err == nil {
+			t.Size, t.Encoding = size, ""
+			return true
+		}
+	}
+	t.Close()
+	return false
+}
+
+type MetaBean struct {
+	ContentType string
+	Meta        map[string]string
+	Expire      int64
+}
+
+type StatBean struct {
+	Path         string
+	Size         int64
+	StoredSize   int64
+	CompressType int32
+	Node         string
+	Offset       int64
+	Refercount   int32
+	Fingerprint  []byte
+	Timestramp   int64
+	ContentType  string
+	Meta         map[string]string
+	Expire       int64
+}
+
+type ListBean struct {
+	Path         string
+	Size         int64
+	Fingerprint  []byte
+	Timestramp   int64
+	CompressType int32
+}
+
+type ListResult struct {
+	Entries  []*ListBean
+	Prefixes []string
+	Next     string
+}
+
 type FragBean struct {
 	Node       string
 	RmSize     int64
@@ -57,6 +162,113 @@ type FragBean struct {
 	FileSize   int64
 }
 
+type DefragBean struct {
+	Running   bool
+	Auto      bool
+	Node      []string
+	Nodes     int
+	NodeTotal int
+	Reclaimed int64
+	StartTime int64
+	EndTime   int64
+	History   []*DefragRun
+}
+
+type DefragRun struct {
+	Auto      bool
+	Nodes     int
+	Reclaimed int64
+	StartTime int64
+	EndTime   int64
+	Errors    []string
+}
+
+type MmapBean struct {
+	Maps   int64
+	Unmaps int64
+	Preads int64
+	Nodes  int
+	Bytes  int64
+}
+
+type ScrubBean struct {
+	Running   bool
+	Node      string
+	Nodes     int
+	NodeTotal int
+	Blocks    int64
+	Bytes     int64
+	Paths     int64
+	StartTime int64
+	EndTime   int64
+	Findings  []*ScrubFinding
+}
+
+type DictBean struct {
+	Running   bool
+	Samples   int64
+	StartTime int64
+	EndTime   int64
+	Dicts     []*DictItem
+}
+
+type DataDirsBean struct {
+	Running   bool
+	Moved     int64
+	Bytes     int64
+	StartTime int64
+	EndTime   int64
+	Dirs      []*DataDirItem
+}
+
+type DataDirItem struct {
+	Path     string
+	Weight   int
+	Free     uint64
+	Total    uint64
+	Nodes    int
+	ReadOnly bool
+}
+
+type ShardsBean struct {
+	Running   bool
+	Repaired  int64
+	Failed    int64
+	StartTime int64
+	EndTime   int64
+	Nodes     []*ShardNodeItem
+}
+
+type ShardNodeItem struct {
+	Node    string
+	Data    int
+	Parity  int
+	Size    int64
+	Missing int
+	Damaged int
+	Dirs    []string
+}
+
+type DictItem struct {
+	Id         int32
+	Scope      string
+	Samples    int32
+	Size       int64
+	Plain      int64
+	Dicted     int64
+	Timestramp int64
+	Current    bool
+}
+
+type ScrubFinding struct {
+	Kind        string
+	Node        string
+	Offset      int64
+	Path        string
+	Fingerprint []byte
+	Quarantined bool
+}
+
 type openssl struct {
 	PrivateBytes []byte
 	PublicBytes  []byte
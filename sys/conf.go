@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -49,6 +50,7 @@ func praseflag() {
 	flag.Int64Var(&limit, "limit", 0, "export limit")
 	flag.BoolVar(&efile, "file", false, "wfs file data")
 	flag.BoolVar(&filegz, "gz", false, "exported compressed data")
+	flag.BoolVar(&repair, "repair", false, "repair the data found inconsistent by fsck")
 
 	flag.Parse()
 	parsec()
@@ -71,6 +73,10 @@ func praseflag() {
 		}
 	}
 
+	if Conf.S3Listen > 0 {
+		S3LISTEN = Conf.S3Listen
+	}
+
 	if OPADDR != "" && Conf.Opaddr != nil {
 		OPADDR = *Conf.Opaddr
 	}
@@ -107,6 +113,56 @@ func praseflag() {
 		WFSDATA = *Conf.WfsData
 	}
 
+	for _, d := range Conf.DataDirs {
+		if d != nil && d.Path != "" {
+			if d.Weight <= 0 {
+				d.Weight = 1
+			}
+			d.Path = filepath.Clean(d.Path)
+			DataDirs = append(DataDirs, d)
+		}
+	}
+
+	if Conf.ErasureData > 0 && Conf.ErasureParity > 0 && Conf.ErasureData+Conf.ErasureParity <= 256 {
+		ErasureData, ErasureParity = Conf.ErasureData, Conf.ErasureParity
+	}
+
+	if Conf.TierHot != "" && Conf.TierCold != "" {
+		TierHot, TierCold = filepath.Clean(Conf.TierHot), filepath.Clean(Conf.TierCold)
+	}
+	if Conf.TierAge > 0 {
+		TierAge = Conf.TierAge
+	}
+	TierCompress = Conf.TierCompress
+	if Conf.TierPromote > 0 {
+		TierPromote = Conf.TierPromote
+	}
+
+	if Conf.DefragRatio > 0 && Conf.DefragRatio < 1 {
+		DefragRatio = Conf.DefragRatio
+	}
+	if Conf.DefragWindow != nil {
+		DefragWindow = Conf.DefragWindow
+	}
+	if Conf.DefragRate > 0 {
+		DefragRate = Conf.DefragRate
+	}
+	if Conf.DefragCPU > 0 && Conf.DefragCPU <= 100 {
+		DefragCPU = Conf.DefragCPU
+	}
+	if Conf.DefragNodes > 0 {
+		DefragNodes = Conf.DefragNodes
+	}
+	if Conf.DefragMerge > 0 && Conf.DefragMerge < 1 {
+		DefragMerge = Conf.DefragMerge
+	}
+	if Conf.MmapSize > 0 {
+		MmapSize = Conf.MmapSize * MB
+	}
+	if Conf.MmapNodes > 0 {
+		MmapNodes = Conf.MmapNodes
+	}
+
 	if Conf.Compress != nil {
 		CompressType = *Conf.Compress
 	}
@@ -127,14 +183,54 @@ func praseflag() {
 		FileHash = *Conf.FileHash
 	}
 
-	if Service != "" {
-		praseService(Service)
-	}
+	Encrypt = Conf.Encrypt
+	EncryptKeyFile = Conf.EncryptKeyFile
 
 	if Conf.Restrict != nil {
 		Restrict = *Conf.Restrict
 	}
 
+	if Conf.TTL != nil {
+		TTL = Conf.TTL
+	}
+
+	if Conf.TTLInterval > 0 {
+		TTLInterval = Conf.TTLInterval
+	}
+
+	if Conf.UploadTTL > 0 {
+		UploadTTL = Conf.UploadTTL
+	}
+
+	if Conf.ScrubRate > 0 {
+		ScrubRate = Conf.ScrubRate
+	}
+
+	if Conf.ScrubInterval > 0 {
+		ScrubInterval = Conf.ScrubInterval
+	}
+
+	Quarantine = Conf.ScrubQuarantine
+
+	if Conf.Journal != nil {
+		Journal = *Conf.Journal
+	}
+
+	DedupVerify = Conf.DedupVerify
+
+	if Conf.CompressRules != nil {
+		CompressRules = Conf.CompressRules
+	}
+
+	ZstdDict = Conf.ZstdDict
+	if Conf.ZstdDictPrefix != nil {
+		ZstdDictPrefix = Conf.ZstdDictPrefix
+	}
+
+	if Service != "" {
+		praseService(Service)
+	}
+
 	flag.Usage = usage
 	flag.Usage()
 
@@ -217,6 +313,21 @@ func praseService(s string) {
 		} else {
 			importmeta()
 		}
+	case "fsck":
+		if err := Fsck(repair); err != nil {
+			fmt.Println("fsck failed:", err)
+			os.Exit(1)
+		}
+	case "rebuild":
+		if err := Rebuild(); err != nil {
+			fmt.Println("rebuild failed:", err)
+			os.Exit(1)
+		}
+	case "rotatekey":
+		if err := RotateKey(); err != nil {
+			fmt.Println("rotatekey failed:", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Printf("could not find service with: %s\n", s)
 		os.Exit(1)
@@ -591,6 +702,36 @@ func wfsmkdir(dir string) (err error) {
 		logger.Error(err)
 		os.Exit(1)
 	}
+	for _, d := range DataDirs {
+		if err = os.MkdirAll(d.Path, 0777); err != nil {
+			logger.Error(err)
+			os.Exit(1)
+		}
+	}
+	for _, d := range []string{TierHot, TierCold} {
+		if d != "" {
+			if err = os.MkdirAll(d, 0777); err != nil {
+				logger.Error(err)
+				os.Exit(1)
+			}
+		}
+	}
+	return
+}
+
+// FileDirs returns the directories of the node files, the default one first
+func FileDirs() (_r []string) {
+	_r = []string{filepath.Clean(WFSDATA + "/wfsfile")}
+	for _, d := range DataDirs {
+		if !slices.Contains(_r, d.Path) {
+			_r = append(_r, d.Path)
+		}
+	}
+	for _, d := range []string{TierHot, TierCold} {
+		if d != "" && !slices.Contains(_r, d) {
+			_r = append(_r, d)
+		}
+	}
 	return
 }
 
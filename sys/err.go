@@ -27,6 +27,15 @@ var ERR_DEFRAG_UNDERWAY = err(5103, "defragmentation is underway")
 var ERR_STOPSERVICE = err(5104, "service has stopped")
 var ERR_FILEAPPEND = err(5105, "append data error")
 var ERR_FILECREATE = err(5106, "create file error")
+var ERR_CORRUPT = err(5107, "data is corrupt")
+var ERR_SCRUB_UNDERWAY = err(5108, "scrub is underway")
+var ERR_DICT_UNDERWAY = err(5109, "dictionary training is underway")
+var ERR_DICT_MODE = err(5110, "dictionaries are trained from the paths of mode 1")
+var ERR_REBALANCE_UNDERWAY = err(5111, "rebalance is underway")
+var ERR_DATADIRS = err(5112, "rebalance needs several data directories")
+var ERR_REPAIR_UNDERWAY = err(5113, "shard repair is underway")
+var ERR_DEFRAG_JOB_UNDERWAY = err(5114, "defragmentation job is underway")
+var ERR_REHASH_MODE = err(5115, "paths are re-hashed from the path index of mode 1")
 
 type ERROR interface {
 	WfsError() *WfsError
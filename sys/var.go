@@ -50,6 +50,7 @@ var (
 	WEBADDR        = fmt.Sprint(6<<10 + 2)
 	OPADDR         = fmt.Sprint(":", 5<<10+2)
 	LISTEN         = 4<<10 + 2
+	S3LISTEN       = 0
 	SYNC           = false
 	Restrict       = 95
 	DBBUFFER       = 1 << 6 * MB
@@ -62,6 +63,35 @@ var (
 	OpenSSL        = &openssl{}
 	Memlimit       = int64(1 << 10)
 	FileHash       = 0
+	TTL            = map[string]int64{}
+	TTLInterval    = 60
+	UploadTTL      = int64(86400)
+	ScrubRate      = 20
+	ScrubInterval  = 0
+	Quarantine     = false
+	Journal        = true
+	DedupVerify    = false
+	CompressRules  = map[string]int32{}
+	ZstdDict       = false
+	ZstdDictPrefix = []string{}
+	Encrypt        = false
+	EncryptKeyFile = ""
+	DataDirs       = []*DataDirBean{}
+	ErasureData    = 0
+	ErasureParity  = 0
+	TierHot        = ""
+	TierCold       = ""
+	TierAge        = int64(30 * 24 * 3600)
+	TierCompress   *int32
+	TierPromote    = 100
+	DefragRatio    = float64(0)
+	DefragWindow   = []string{}
+	DefragRate     = 20
+	DefragCPU      = 50
+	DefragNodes    = 1
+	DefragMerge    = float64(0)
+	MmapSize       = int64(0)
+	MmapNodes      = 0
 	defaultConf    = ""
 	host           = ""
 	user           = ""
@@ -73,6 +103,7 @@ var (
 	limit          = int64(0)
 	efile          = false
 	filegz         = false
+	repair         = false
 	metaType       = byte(1)
 	fileType       = byte(2)
 	useOriginal    = byte(0)
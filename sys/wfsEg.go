@@ -13,12 +13,20 @@ import (
 )
 
 var (
-	KeyStoreInit func(string)
-	Count        func() int64
-	Seq          func() int64
-	AppendData   func(string, []byte, int32) (int64, ERROR)
-	GetData      func(string) []byte
-	DelData      func(string) ERROR
+	KeyStoreInit   func(string)
+	Count          func() int64
+	Seq            func() int64
+	AppendData     func(string, []byte, int32, *MetaBean) (int64, ERROR)
+	GetData        func(string) []byte
+	GetReader      func(string) *DataBean
+	GetStored      func(string) *DataBean
+	DelData        func(string) ERROR
+	StatData       func(string) *StatBean
+	ListPaths      func(string, string, string, int) *ListResult
+	UploadInit     func(string, int32, *MetaBean) (int64, ERROR)
+	UploadPart     func(int64, int32, []byte) ERROR
+	UploadComplete func(int64) (int64, ERROR)
+	UploadAbort    func(int64) ERROR
 	//add            func([]byte, []byte) error
 	//Del            func([]byte) error
 	Contains       func(string) bool
@@ -35,4 +43,22 @@ var (
 	Modify         func(string, string) ERROR
 	WsClient       func(tls bool, pid, opaddr, requri, name, pwd string) (ws *WS, err error)
 	IsEmptyBigFile func(string) bool
+	ScrubStart     func() ERROR
+	ScrubStop      func()
+	ScrubStatus    func() *ScrubBean
+	Corrupt        func(string) bool
+	Fsck           func(bool) error
+	Rebuild        func() error
+	Collisions     func() int64
+	CompressOf     func(string) int32
+	DictTrain      func() ERROR
+	DictStatus     func() *DictBean
+	RotateKey      func() error
+	DataDirStatus  func() *DataDirsBean
+	Rebalance      func() ERROR
+	ShardStatus    func() *ShardsBean
+	RepairShards   func() ERROR
+	DefragStart    func() ERROR
+	DefragStatus   func() *DefragBean
+	MmapStatus     func() *MmapBean
 )
@@ -8,6 +8,7 @@
 package tc
 
 import (
+	"encoding/hex"
 	"fmt"
 	"github.com/donnie4w/gofer/base58"
 	"io/fs"
@@ -36,6 +37,7 @@ import (
 func init() {
 	sys.Serve.Put(4, adminservice)
 	sys.Serve.Put(5, clientservice)
+	sys.Serve.Put(6, s3service)
 	sys.WsClient = newAdminLocalWsClient
 }
 
@@ -102,11 +104,21 @@ func (t *adminService) _serve(addr string, serverCrt, serverKey string) (err err
 	t.tlAdmin.HandleWithFilter("/file", loginFilter(), fileHtml)
 	t.tlAdmin.HandleWithFilter("/fragment", loginFilter(), fragmentHtml)
 	t.tlAdmin.HandleWithFilter("/defrag", loginFilter(), defragData)
+	t.tlAdmin.HandleWithFilter("/scrub", authFilter(), scrubHandler)
+	t.tlAdmin.HandleWithFilter("/dict", authFilter(), dictHandler)
+	t.tlAdmin.HandleWithFilter("/datadir", authFilter(), dataDirHandler)
+	t.tlAdmin.HandleWithFilter("/shard", authFilter(), shardHandler)
+	t.tlAdmin.HandleWithFilter("/defragjob", authFilter(), defragJobHandler)
 	t.tlAdmin.HandleWithFilter("/filedata", loginFilter(), fileDataHandler)
 	t.tlAdmin.HandleWithFilter("/monitor", loginFilter(), monitorHtml)
 	t.tlAdmin.HandleWebSocketBindConfig("/monitorData", mntHandler, mntConfig())
 	t.tlAdmin.HandleWithFilter("/append/", authFilter(), appendHandler)
 	t.tlAdmin.HandleWithFilter("/delete/", authFilter(), deleteHandler)
+	t.tlAdmin.HandleWithFilter("/upload/init", authFilter(), uploadInitHandler)
+	t.tlAdmin.HandleWithFilter("/upload/part", authFilter(), uploadPartHandler)
+	t.tlAdmin.HandleWithFilter("/upload/complete", authFilter(), uploadCompleteHandler)
+	t.tlAdmin.HandleWithFilter("/upload/abort", authFilter(), uploadAbortHandler)
+	t.tlAdmin.HandleWithFilter("/list", authFilter(), listHandler)
 	t.tlAdmin.HandleWithFilter("/rename", loginFilter(), renameHandler)
 	t.tlAdmin.HandleWebSocketBindConfig("/export", exportHandler, wsConfig())
 	t.tlAdmin.HandleWebSocketBindConfig("/exportincr", exportIncrHandler, wsConfig())
@@ -394,11 +406,8 @@ func initStore() {
 func readHandler(hc *tlnet.HttpContext) {
 	defer util.Recover()
 	uri := hc.Request().RequestURI
-	if bs, cy, err := getData(uri[2:]); err == nil {
-		if cy != "" {
-			hc.Writer().Header().Add("Content-Type", cy)
-		}
-		hc.ResponseBytes(0, bs)
+	if rb, err := getData(uri[2:]); err == nil && rb != nil {
+		serveResource(hc, rb)
 	} else {
 		hc.Writer().WriteHeader(404)
 	}
@@ -424,25 +433,41 @@ func fragmentHtml(hc *tlnet.HttpContext) {
 		}
 	}()
 	fbs := make([]*FragmentBean, 0)
-	filepath.WalkDir(sys.WFSDATA+"/wfsfile", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() {
-			if i, b := base58.DecodeForInt64([]byte(d.Name())); b && util.CheckNodeId(int64(i)) && !sys.IsEmptyBigFile(path) {
-				fi, _ := d.Info()
-				fb := &FragmentBean{Name: d.Name(), FileSize: fi.Size(), Time: fi.ModTime().Format(time.DateTime)}
-				if fa, err := sys.FragAnalysis(d.Name()); err == nil {
-					fb.FragmentSize = fa.FileSize - fa.ActualSize + fa.RmSize
-					fb.Status = 1
-				} else if err.Equal(sys.ERR_DEFRAG_FORBID) {
-					fb.Status = 2
+	for _, dir := range sys.FileDirs() {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				if i, b := base58.DecodeForInt64([]byte(d.Name())); b && util.CheckNodeId(int64(i)) && !sys.IsEmptyBigFile(path) {
+					fi, _ := d.Info()
+					fb := &FragmentBean{Name: d.Name(), FileSize: fi.Size(), Time: fi.ModTime().Format(time.DateTime)}
+					if fa, err := sys.FragAnalysis(d.Name()); err == nil {
+						fb.FragmentSize = fa.FileSize - fa.ActualSize + fa.RmSize
+						fb.Status = 1
+					} else if err.Equal(sys.ERR_DEFRAG_FORBID) {
+						fb.Status = 2
+					}
+					fbs = append(fbs, fb)
 				}
-				fbs = append(fbs, fb)
+			}
+			return nil
+		})
+	}
+	for _, n := range sys.ShardStatus().Nodes {
+		fb := &FragmentBean{Name: n.Node, FileSize: n.Size}
+		for i, dir := range n.Dirs {
+			if fi, err := os.Stat(fmt.Sprint(dir, "/", n.Node, ".", i)); err == nil {
+				fb.Time = fi.ModTime().Format(time.DateTime)
+				break
 			}
 		}
-		return nil
-	})
+		if fa, err := sys.FragAnalysis(n.Node); err == nil {
+			fb.FragmentSize = fa.FileSize - fa.ActualSize + fa.RmSize
+			fb.Status = 1
+		}
+		fbs = append(fbs, fb)
+	}
 	sort.Slice(fbs, func(i, j int) bool { return fbs[i].Time > fbs[j].Time })
 	tplToHtml(getLang(hc), FRAGMENT, fbs, hc)
 }
@@ -466,6 +491,92 @@ func defragData(hc *tlnet.HttpContext) {
 	}
 }
 
+// scrubHandler starts or stops the integrity scrub with the action param, and returns its progress and findings
+func scrubHandler(hc *tlnet.HttpContext) {
+	switch hc.PostParamTrimSpace("action") {
+	case "start":
+		if err := sys.ScrubStart(); err != nil {
+			hc.ResponseString(`{"status":false,"desc":"` + err.WfsError().GetInfo() + `"}`)
+			return
+		}
+	case "stop":
+		sys.ScrubStop()
+	}
+	sb := sys.ScrubStatus()
+	sp := &ScrubPage{Status: true, Running: sb.Running, Node: sb.Node, Nodes: sb.Nodes, NodeTotal: sb.NodeTotal, Blocks: sb.Blocks, Bytes: sb.Bytes, Paths: sb.Paths, StartTime: sb.StartTime, EndTime: sb.EndTime, Findings: make([]*ScrubItem, 0, len(sb.Findings))}
+	for _, f := range sb.Findings {
+		sp.Findings = append(sp.Findings, &ScrubItem{Kind: f.Kind, Node: f.Node, Offset: f.Offset, Path: f.Path, Fingerprint: hex.EncodeToString(f.Fingerprint), Quarantined: f.Quarantined})
+	}
+	hc.ResponseBytes(0, goutil.JsonEncode(sp))
+}
+
+// dictHandler starts a training of the zstd dictionaries with the action param train, and returns its progress and the dictionaries
+func dictHandler(hc *tlnet.HttpContext) {
+	if hc.PostParamTrimSpace("action") == "train" {
+		if err := sys.DictTrain(); err != nil {
+			hc.ResponseString(`{"status":false,"desc":"` + err.WfsError().GetInfo() + `"}`)
+			return
+		}
+	}
+	db := sys.DictStatus()
+	dp := &DictPage{Status: true, Running: db.Running, Samples: db.Samples, StartTime: db.StartTime, EndTime: db.EndTime, Dicts: make([]*DictItem, 0, len(db.Dicts))}
+	for _, d := range db.Dicts {
+		dp.Dicts = append(dp.Dicts, &DictItem{Id: d.Id, Scope: d.Scope, Samples: d.Samples, Size: d.Size, Plain: d.Plain, Dicted: d.Dicted, Time: time.Unix(0, d.Timestramp).Format(time.DateTime), Current: d.Current})
+	}
+	hc.ResponseBytes(0, goutil.JsonEncode(dp))
+}
+
+// dataDirHandler starts a rebalance of the node files with the action param rebalance, and returns its progress
+// and the data directories
+func dataDirHandler(hc *tlnet.HttpContext) {
+	if hc.PostParamTrimSpace("action") == "rebalance" {
+		if err := sys.Rebalance(); err != nil {
+			hc.ResponseString(`{"status":false,"desc":"` + err.WfsError().GetInfo() + `"}`)
+			return
+		}
+	}
+	db := sys.DataDirStatus()
+	dp := &DataDirPage{Status: true, Running: db.Running, Moved: db.Moved, Bytes: db.Bytes, StartTime: db.StartTime, EndTime: db.EndTime, Dirs: make([]*DataDirItem, 0, len(db.Dirs))}
+	for _, d := range db.Dirs {
+		dp.Dirs = append(dp.Dirs, &DataDirItem{Path: d.Path, Weight: d.Weight, Free: d.Free, Total: d.Total, Nodes: d.Nodes, ReadOnly: d.ReadOnly})
+	}
+	hc.ResponseBytes(0, goutil.JsonEncode(dp))
+}
+
+// shardHandler starts a repair of the shards with the action param repair, and returns its progress and the
+// health of the erasure coded node files
+func shardHandler(hc *tlnet.HttpContext) {
+	if hc.PostParamTrimSpace("action") == "repair" {
+		if err := sys.RepairShards(); err != nil {
+			hc.ResponseString(`{"status":false,"desc":"` + err.WfsError().GetInfo() + `"}`)
+			return
+		}
+	}
+	sb := sys.ShardStatus()
+	sp := &ShardPage{Status: true, Running: sb.Running, Repaired: sb.Repaired, Failed: sb.Failed, StartTime: sb.StartTime, EndTime: sb.EndTime, Nodes: make([]*ShardItem, 0, len(sb.Nodes))}
+	for _, n := range sb.Nodes {
+		sp.Nodes = append(sp.Nodes, &ShardItem{Node: n.Node, Data: n.Data, Parity: n.Parity, Size: n.Size, Missing: n.Missing, Damaged: n.Damaged, Dirs: n.Dirs})
+	}
+	hc.ResponseBytes(0, goutil.JsonEncode(sp))
+}
+
+// defragJobHandler starts a defragmentation job with the action param start, and returns its progress and the
+// history of the defragmentations
+func defragJobHandler(hc *tlnet.HttpContext) {
+	if hc.PostParamTrimSpace("action") == "start" {
+		if err := sys.DefragStart(); err != nil {
+			hc.ResponseString(`{"status":false,"desc":"` + err.WfsError().GetInfo() + `"}`)
+			return
+		}
+	}
+	db := sys.DefragStatus()
+	dp := &DefragPage{Status: true, Running: db.Running, Auto: db.Auto, Node: db.Node, Nodes: db.Nodes, NodeTotal: db.NodeTotal, Reclaimed: db.Reclaimed, StartTime: db.StartTime, EndTime: db.EndTime, History: make([]*DefragRunItem, 0, len(db.History))}
+	for _, r := range db.History {
+		dp.History = append(dp.History, &DefragRunItem{Auto: r.Auto, Nodes: r.Nodes, Reclaimed: r.Reclaimed, StartTime: r.StartTime, EndTime: r.EndTime, Errors: r.Errors})
+	}
+	hc.ResponseBytes(0, goutil.JsonEncode(dp))
+}
+
 func fileDataHandler(hc *tlnet.HttpContext) {
 	searchType := hc.PostParamTrimSpace("searchType")
 	if searchType == "1" {
@@ -508,7 +619,7 @@ func searchByPage(pagenum, pagecount int, lastId int64) (fp *FilePage) {
 			fp = &FilePage{TotalNum: int(sys.Count()), CurrentNum: pagenum, FS: make([]*FileBean, 0)}
 			fp.RevProxy, fp.CliProtocol, fp.ClientPort = webclientInfo()
 			for _, pb := range pbs {
-				fb := &FileBean{Name: pb.Path, Size: len(pb.Body), Time: util.TimestrampFormat(pb.Timestramp), Id: int(pb.Id)}
+				fb := &FileBean{Name: pb.Path, Size: int(pb.Size), Time: util.TimestrampFormat(pb.Timestramp), Id: int(pb.Id)}
 				fp.FS = append(fp.FS, fb)
 			}
 		}
@@ -522,7 +633,7 @@ func searchByPrev(prev string) (fp *FilePage) {
 			fp = &FilePage{FS: make([]*FileBean, 0), TotalNum: len(pbs), CurrentNum: 1}
 			fp.RevProxy, fp.CliProtocol, fp.ClientPort = webclientInfo()
 			for _, pb := range pbs {
-				fb := &FileBean{Name: pb.Path, Size: len(pb.Body), Time: util.TimestrampFormat(pb.Timestramp), Id: int(pb.Id)}
+				fb := &FileBean{Name: pb.Path, Size: int(pb.Size), Time: util.TimestrampFormat(pb.Timestramp), Id: int(pb.Id)}
 				fp.FS = append(fp.FS, fb)
 			}
 			sort.Slice(fp.FS, func(i, j int) bool { return fp.FS[i].Id > fp.FS[j].Id })
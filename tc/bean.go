@@ -7,6 +7,8 @@
 
 package tc
 
+import "io"
+
 type AdminView struct {
 	Show       string
 	AdminUser  map[string]string
@@ -37,3 +39,130 @@ type FragmentBean struct {
 	FragmentSize int64
 	FileSize     int64
 }
+
+type ScrubPage struct {
+	Status    bool         `json:"status"`
+	Running   bool         `json:"running"`
+	Node      string       `json:"node,omitempty"`
+	Nodes     int          `json:"nodes"`
+	NodeTotal int          `json:"nodeTotal"`
+	Blocks    int64        `json:"blocks"`
+	Bytes     int64        `json:"bytes"`
+	Paths     int64        `json:"paths"`
+	StartTime int64        `json:"startTime"`
+	EndTime   int64        `json:"endTime"`
+	Findings  []*ScrubItem `json:"findings"`
+}
+
+type ScrubItem struct {
+	Kind        string `json:"kind"`
+	Node        string `json:"node,omitempty"`
+	Offset      int64  `json:"offset"`
+	Path        string `json:"path,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Quarantined bool   `json:"quarantined"`
+}
+
+type DictPage struct {
+	Status    bool        `json:"status"`
+	Running   bool        `json:"running"`
+	Samples   int64       `json:"samples"`
+	StartTime int64       `json:"startTime"`
+	EndTime   int64       `json:"endTime"`
+	Dicts     []*DictItem `json:"dicts"`
+}
+
+type DictItem struct {
+	Id      int32  `json:"id"`
+	Scope   string `json:"scope"`
+	Samples int32  `json:"samples"`
+	Size    int64  `json:"size"`
+	Plain   int64  `json:"plain"`
+	Dicted  int64  `json:"dicted"`
+	Time    string `json:"time"`
+	Current bool   `json:"current"`
+}
+
+type DataDirPage struct {
+	Status    bool           `json:"status"`
+	Running   bool           `json:"running"`
+	Moved     int64          `json:"moved"`
+	Bytes     int64          `json:"bytes"`
+	StartTime int64          `json:"startTime"`
+	EndTime   int64          `json:"endTime"`
+	Dirs      []*DataDirItem `json:"dirs"`
+}
+
+type DataDirItem struct {
+	Path     string `json:"path"`
+	Weight   int    `json:"weight"`
+	Free     uint64 `json:"free"`
+	Total    uint64 `json:"total"`
+	Nodes    int    `json:"nodes"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+type ShardPage struct {
+	Status    bool         `json:"status"`
+	Running   bool         `json:"running"`
+	Repaired  int64        `json:"repaired"`
+	Failed    int64        `json:"failed"`
+	StartTime int64        `json:"startTime"`
+	EndTime   int64        `json:"endTime"`
+	Nodes     []*ShardItem `json:"nodes"`
+}
+
+type ShardItem struct {
+	Node    string   `json:"node"`
+	Data    int      `json:"data"`
+	Parity  int      `json:"parity"`
+	Size    int64    `json:"size"`
+	Missing int      `json:"missing"`
+	Damaged int      `json:"damaged"`
+	Dirs    []string `json:"dirs"`
+}
+
+type DefragPage struct {
+	Status    bool             `json:"status"`
+	Running   bool             `json:"running"`
+	Auto      bool             `json:"auto"`
+	Node      []string         `json:"node"`
+	Nodes     int              `json:"nodes"`
+	NodeTotal int              `json:"nodeTotal"`
+	Reclaimed int64            `json:"reclaimed"`
+	StartTime int64            `json:"startTime"`
+	EndTime   int64            `json:"endTime"`
+	History   []*DefragRunItem `json:"history"`
+}
+
+type DefragRunItem struct {
+	Auto      bool     `json:"auto"`
+	Nodes     int      `json:"nodes"`
+	Reclaimed int64    `json:"reclaimed"`
+	StartTime int64    `json:"startTime"`
+	EndTime   int64    `json:"endTime"`
+	Errors    []string `json:"errors"`
+}
+
+type ResourceBean struct {
+	Body        []byte
+	Reader      io.ReadSeeker
+	ContentType string
+	ETag        string
+	Timestramp  int64
+	Meta        map[string]string
+}
+
+type ListPage struct {
+	Status   bool        `json:"status"`
+	Entries  []*ListItem `json:"entries"`
+	Prefixes []string    `json:"prefixes"`
+	Next     string      `json:"next,omitempty"`
+}
+
+type ListItem struct {
+	Path         string `json:"path"`
+	Size         int64  `json:"size"`
+	Timestramp   int64  `json:"timestramp"`
+	CompressType int32  `json:"compressType"`
+}
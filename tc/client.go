@@ -9,12 +9,18 @@ package tc
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/donnie4w/gofer/image"
+	goutil "github.com/donnie4w/gofer/util"
 	"github.com/donnie4w/tlnet"
 	"github.com/donnie4w/wfs/sys"
 	"github.com/donnie4w/wfs/util"
@@ -27,6 +33,8 @@ type clientService struct {
 	tln     *tlnet.Tlnet
 }
 
+const wfsMetaPrefix = "X-Wfs-Meta-"
+
 var clientservice = &clientService{false, tlnet.NewTlnet()}
 var images *image.Image
 
@@ -72,24 +80,218 @@ func (t *clientService) _serve(addr string, serverCrt, serverKey string) (err er
 }
 
 func loadHandler(hc *tlnet.HttpContext) {
-	if bs, cy, _ := getData(hc.Request().RequestURI); bs != nil {
-		if cy != "" {
-			hc.Writer().Header().Add("Content-Type", cy)
+	uri := hc.Request().RequestURI
+	if hc.Request().Method == http.MethodHead && len(uri) > 1 && !strings.Contains(uri, "?") {
+		if rb, sb := statByName(uri[1:]); rb != nil {
+			header := hc.Writer().Header()
+			header.Set("X-Wfs-Stored-Size", strconv.FormatInt(sb.StoredSize, 10))
+			header.Set("X-Wfs-Compress-Type", strconv.Itoa(int(sb.CompressType)))
+			header.Set("X-Wfs-Refercount", strconv.Itoa(int(sb.Refercount)))
+			if sb.Node != "" {
+				header.Set("X-Wfs-Node", sb.Node)
+				header.Set("X-Wfs-Offset", strconv.FormatInt(sb.Offset, 10))
+			}
+			if sb.Expire > 0 {
+				header.Set("X-Wfs-Expire", strconv.FormatInt(sb.Expire, 10))
+			}
+			serveResource(hc, rb)
+		} else {
+			hc.Writer().WriteHeader(404)
+		}
+		return
+	}
+	if hc.Request().Method == http.MethodGet && len(uri) > 1 && !strings.Contains(uri, "?") && hc.Request().Header.Get("Range") == "" {
+		if rb, db := storedByName(uri[1:]); rb != nil {
+			header := hc.Writer().Header()
+			header.Add("Vary", "Accept-Encoding")
+			if rb.ContentType != "" && acceptEncoding(hc.Request().Header.Get("Accept-Encoding"), db.Encoding) {
+				header.Set("Content-Encoding", db.Encoding)
+				serveResource(hc, rb)
+				return
+			}
+			// the stored bytes are decoded by the reader already opened, the path is not looked up again
+			if fingerprint := db.Fingerprint; db.Decode() {
+				rb.Reader, rb.ETag = db.Reader, etag(fingerprint, "")
+				serveResource(hc, rb)
+				return
+			}
 		}
-		hc.ResponseBytes(0, bs)
+	}
+	if rb, err := getData(uri); rb != nil {
+		serveResource(hc, rb)
+	} else if err != nil && err.Equal(sys.ERR_CORRUPT) {
+		hc.Writer().Header().Set("X-Wfs-Error", strconv.Itoa(int(err.Code())))
+		hc.Writer().WriteHeader(http.StatusInternalServerError)
 	} else {
 		hc.Writer().WriteHeader(404)
 	}
 }
 
-func getData(uri string) (retbs []byte, t string, err sys.ERROR) {
+// serveResource writes the resource through http.ServeContent, which answers
+// Range/If-Range with 206 (multipart/byteranges for several ranges) and
+// If-None-Match/If-Modified-Since with 304.
+func serveResource(hc *tlnet.HttpContext, rb *ResourceBean) {
+	header := hc.Writer().Header()
+	if rb.ContentType != "" {
+		header.Set("Content-Type", rb.ContentType)
+	}
+	if rb.ETag != "" {
+		header.Set("ETag", rb.ETag)
+	}
+	setHeaderMeta(header, wfsMetaPrefix, rb.Meta)
+	var modtime time.Time
+	if rb.Timestramp > 0 {
+		modtime = time.Unix(0, rb.Timestramp)
+	}
+	var content io.ReadSeeker = bytes.NewReader(rb.Body)
+	if rb.Reader != nil {
+		content = rb.Reader
+	}
+	http.ServeContent(hc.Writer(), hc.Request(), "", modtime, content)
+	if c, ok := content.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// lazyContent defers reading the object until the body is written, so that
+// HEAD and conditional requests are answered from metadata only.
+type lazyContent struct {
+	size   int64
+	offset int64
+	load   func() io.ReadSeeker
+	rs     io.ReadSeeker
+}
+
+func (t *lazyContent) Read(p []byte) (n int, err error) {
+	if t.rs == nil {
+		if t.rs = t.load(); t.rs == nil {
+			return 0, io.ErrUnexpectedEOF
+		}
+		if _, err = t.rs.Seek(t.offset, io.SeekStart); err != nil {
+			return
+		}
+	}
+	return t.rs.Read(p)
+}
+
+func (t *lazyContent) Seek(offset int64, whence int) (int64, error) {
+	if t.rs != nil {
+		return t.rs.Seek(offset, whence)
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += t.offset
+	case io.SeekEnd:
+		offset += t.size
+	}
+	if offset < 0 {
+		return 0, os.ErrInvalid
+	}
+	t.offset = offset
+	return offset, nil
+}
+
+// Close gives back the reader of the object if it is loaded
+func (t *lazyContent) Close() error {
+	if c, ok := t.rs.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// headerMeta collects the headers beginning with prefix as user metadata, keyed by the lower-cased rest of the name
+func headerMeta(header http.Header, prefix string) (_r map[string]string) {
+	for k, v := range header {
+		if len(k) > len(prefix) && len(v) > 0 && strings.EqualFold(k[:len(prefix)], prefix) {
+			if _r == nil {
+				_r = make(map[string]string)
+			}
+			_r[strings.ToLower(k[len(prefix):])] = v[0]
+		}
+	}
+	return
+}
+
+func setHeaderMeta(header http.Header, prefix string, meta map[string]string) {
+	for k, v := range meta {
+		header.Set(prefix+k, v)
+	}
+}
+
+// typeByName returns the stored content type, or the one guessed from the suffix of path
+func typeByName(path, stored string) (ct string) {
+	if stored != "" {
+		return stored
+	}
+	if index := strings.LastIndex(path, "."); index > 0 {
+		if suffix := path[index:]; len(suffix) > 1 {
+			ct, _ = contentType(suffix, "")
+		}
+	}
+	return
+}
+
+func dataReader(path string) io.ReadSeeker {
+	if db := sys.GetReader(path); db != nil {
+		return db.Reader
+	}
+	return nil
+}
+
+// statByName describes the file from its metadata, the body is loaded only if it is written
+func statByName(uri1 string) (rb *ResourceBean, sb *sys.StatBean) {
+	path := uri1
+	if decoded, err := url.QueryUnescape(path); err == nil {
+		path = decoded
+	}
+	if sb = sys.StatData(path); sb != nil {
+		rb = &ResourceBean{Reader: &lazyContent{size: sb.Size, load: func() io.ReadSeeker { return dataReader(path) }}, ContentType: typeByName(path, sb.ContentType), ETag: etag(sb.Fingerprint, ""), Timestramp: sb.Timestramp, Meta: sb.Meta}
+	} else if sys.Conf.SLASH && uri1[0] != '/' {
+		return statByName("/" + uri1)
+	}
+	return
+}
+
+// storedByName returns the file with its stored bytes when they are compressed by a codec of Content-Encoding, with
+// the data bean that decodes them. The file of unknown content type is served decoded, its type is detected from
+// the data.
+func storedByName(uri1 string) (rb *ResourceBean, db *sys.DataBean) {
+	path := uri1
+	if decoded, err := url.QueryUnescape(path); err == nil {
+		path = decoded
+	}
+	if db = sys.GetStored(path); db != nil {
+		rb = &ResourceBean{Reader: db.Reader, ContentType: typeByName(path, db.ContentType), ETag: etag(db.Fingerprint, db.Encoding), Timestramp: db.Timestramp, Meta: db.Meta}
+	} else if sys.Conf.SLASH && uri1[0] != '/' && !sys.Contains(path) {
+		return storedByName("/" + uri1)
+	}
+	return
+}
+
+// acceptEncoding tells if encoding is listed in the Accept-Encoding header accept without q=0
+func acceptEncoding(accept, encoding string) bool {
+	for _, s := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(s, ";")
+		if strings.EqualFold(strings.TrimSpace(name), encoding) {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(q), 64); err == nil && v == 0 {
+					return false
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+func getData(uri string) (rb *ResourceBean, err sys.ERROR) {
 	if len(uri) > 1 {
 		return getDataByName(uri[1:])
 	}
 	return
 }
 
-func getDataByName(uri1 string) (bs []byte, ct string, err sys.ERROR) {
+func getDataByName(uri1 string) (rb *ResourceBean, err sys.ERROR) {
 	path, argstr := uri1, ""
 	if index := strings.Index(uri1, "?"); index > 0 {
 		path = uri1[:index]
@@ -98,11 +300,18 @@ func getDataByName(uri1 string) (bs []byte, ct string, err sys.ERROR) {
 	if decoded, err := url.QueryUnescape(path); err == nil {
 		path = decoded
 	}
-	bs = sys.GetData(path)
-	if bs != nil {
+	if db := sys.GetReader(path); db != nil {
+		var bs []byte
+		rd, ct := db.Reader, ""
 		if argstr != "" {
 			m, o := getmode(argstr)
 			switch m {
+			case sys.IMAGEMODE, sys.IMAGEVIEW, sys.IMAGEVIEW2, sys.MD2HTML:
+				bs, _ = io.ReadAll(rd)
+				db.Close()
+				rd = nil
+			}
+			switch m {
 			case sys.IMAGEMODE, sys.IMAGEVIEW, sys.IMAGEVIEW2:
 				if iv2, err := parseUriToImagemode(argstr); err == nil {
 					if bss, err := images.Encode(bs, iv2.width, iv2.height, image.Mode(iv2.mode), iv2.getOptions()); err == nil {
@@ -122,20 +331,34 @@ func getDataByName(uri1 string) (bs []byte, ct string, err sys.ERROR) {
 				ct, _ = contentType(m, o)
 			}
 
-		} else if index := strings.LastIndex(path, "."); index > 0 {
-			if suffix := path[index:]; len(suffix) > 1 {
-				ct, _ = contentType(suffix, "")
-			}
+		} else {
+			ct = typeByName(path, db.ContentType)
 		}
+		rb = &ResourceBean{Body: bs, Reader: rd, ContentType: ct, ETag: etag(db.Fingerprint, argstr), Timestramp: db.Timestramp, Meta: db.Meta}
 	} else {
 		if sys.Conf.SLASH && uri1[0] != '/' {
 			return getDataByName("/" + uri1)
 		}
-		err = sys.ERR_NOTEXSIT
+		if err = sys.ERR_NOTEXSIT; sys.Corrupt(path) {
+			err = sys.ERR_CORRUPT
+		}
 	}
 	return
 }
 
+// etag is derived from the content fingerprint, processed variants
+// (imageView, md2html...) get the arguments folded in so they never share
+// a validator with the original body.
+func etag(fingerprint []byte, argstr string) string {
+	if len(fingerprint) == 0 {
+		return ""
+	}
+	if argstr != "" {
+		return fmt.Sprint(`"`, hex.EncodeToString(fingerprint), "-", strconv.FormatUint(uint64(goutil.CRC32([]byte(argstr))), 16), `"`)
+	}
+	return fmt.Sprint(`"`, hex.EncodeToString(fingerprint), `"`)
+}
+
 func getmode(s string) (string, string) {
 	i := strings.Index(s, "/")
 	if i <= 0 {
@@ -0,0 +1,153 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package tc
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/donnie4w/tlnet"
+	"github.com/donnie4w/wfs/sys"
+)
+
+// useClient serves the resource port over the memStore and returns its address
+func useClient(t *testing.T) (*memStore, string) {
+	ms := useMemStore(t)
+	conf, getReader, getStored := sys.Conf, sys.GetReader, sys.GetStored
+	t.Cleanup(func() { sys.Conf, sys.GetReader, sys.GetStored = conf, getReader, getStored })
+	if sys.Conf == nil {
+		sys.Conf = &sys.ConfBean{}
+	}
+	sys.GetStored = func(string) *sys.DataBean { return nil }
+	sys.GetReader = func(path string) *sys.DataBean {
+		if sb := sys.StatData(path); sb != nil {
+			return &sys.DataBean{Reader: bytes.NewReader(sys.GetData(path)), Size: sb.Size, Fingerprint: sb.Fingerprint, Timestramp: sb.Timestramp, ContentType: sb.ContentType, Meta: sb.Meta}
+		}
+		return nil
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	tln := tlnet.NewTlnet()
+	tln.Handle("/", loadHandler)
+	go tln.HttpStart(addr)
+	t.Cleanup(func() { tln.Close() })
+	for i := 0; i < 100; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			c.Close()
+			return ms, "http://" + addr
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the resource port does not listen")
+	return nil, ""
+}
+
+func get(t *testing.T, method, url string, header map[string]string) (*http.Response, []byte) {
+	t.Helper()
+	r, _ := http.NewRequest(method, url, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, body
+}
+
+func TestRangeConditional(t *testing.T) {
+	_, url := useClient(t)
+	data := []byte("0123456789abcdefghij")
+	sys.AppendData("r/a.txt", data, 0, nil)
+	resp, body := get(t, http.MethodGet, url+"/r/a.txt", nil)
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(body, data) || etag == "" || lastModified == "" || resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Fatal(resp.StatusCode, string(body), resp.Header)
+	}
+	if resp, body = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"Range": "bytes=2-5"}); resp.StatusCode != http.StatusPartialContent || string(body) != "2345" || resp.Header.Get("Content-Range") != "bytes 2-5/20" {
+		t.Fatal("range:", resp.StatusCode, string(body), resp.Header)
+	}
+	if resp, body = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"Range": "bytes=-3"}); resp.StatusCode != http.StatusPartialContent || string(body) != "hij" {
+		t.Fatal("suffix range:", resp.StatusCode, string(body))
+	}
+	if resp, _ = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"Range": "bytes=30-"}); resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatal("unsatisfiable range:", resp.StatusCode)
+	}
+	if resp, body = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"If-None-Match": etag}); resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Fatal("If-None-Match:", resp.StatusCode)
+	}
+	if resp, _ = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"If-Modified-Since": lastModified}); resp.StatusCode != http.StatusNotModified {
+		t.Fatal("If-Modified-Since:", resp.StatusCode)
+	}
+	// a range of another version of the file is answered with the whole file
+	if resp, body = get(t, http.MethodGet, url+"/r/a.txt", map[string]string{"Range": "bytes=2-5", "If-Range": `"0"`}); resp.StatusCode != http.StatusOK || !bytes.Equal(body, data) {
+		t.Fatal("If-Range:", resp.StatusCode, string(body))
+	}
+	if resp, _ = get(t, http.MethodGet, url+"/r/none.txt", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatal("missing file:", resp.StatusCode)
+	}
+}
+
+func TestHead(t *testing.T) {
+	_, url := useClient(t)
+	sys.AppendData("h/a.txt", []byte("the data of h/a.txt"), 0, &sys.MetaBean{Meta: map[string]string{"k": "v"}})
+	stat := sys.StatData
+	sys.StatData = func(path string) *sys.StatBean {
+		if sb := stat(path); sb != nil {
+			sb.StoredSize, sb.CompressType, sb.Refercount, sb.Node, sb.Offset = 12, 2, 1, "1", 64
+			return sb
+		}
+		return nil
+	}
+	var reads int32
+	getReader := sys.GetReader
+	sys.GetReader = func(path string) *sys.DataBean {
+		atomic.AddInt32(&reads, 1)
+		return getReader(path)
+	}
+	resp, body := get(t, http.MethodHead, url+"/h/a.txt", nil)
+	if resp.StatusCode != http.StatusOK || len(body) != 0 || resp.ContentLength != int64(len("the data of h/a.txt")) || resp.Header.Get("ETag") == "" {
+		t.Fatal(resp.StatusCode, resp.Header)
+	}
+	for k, v := range map[string]string{"X-Wfs-Stored-Size": "12", "X-Wfs-Compress-Type": "2", "X-Wfs-Refercount": "1", "X-Wfs-Node": "1", "X-Wfs-Offset": "64", "X-Wfs-Meta-K": "v"} {
+		if resp.Header.Get(k) != v {
+			t.Fatalf("%s: %q", k, resp.Header.Get(k))
+		}
+	}
+	if atomic.LoadInt32(&reads) != 0 {
+		t.Fatal("the data is read by HEAD")
+	}
+	if resp, _ = get(t, http.MethodHead, url+"/h/none.txt", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatal("missing file:", resp.StatusCode)
+	}
+}
+
+func TestStoredContentType(t *testing.T) {
+	_, url := useClient(t)
+	sys.AppendData("c/a.txt", []byte(`{"k":"v"}`), 0, &sys.MetaBean{ContentType: "application/json", Meta: map[string]string{"owner": "wfs"}})
+	sys.AppendData("c/b.txt", []byte("the data of c/b.txt"), 0, nil)
+	// the stored Content-Type is taken before the one of the suffix
+	if resp, _ := get(t, http.MethodGet, url+"/c/a.txt", nil); resp.Header.Get("Content-Type") != "application/json" || resp.Header.Get("X-Wfs-Meta-Owner") != "wfs" {
+		t.Fatal(resp.Header)
+	}
+	if resp, _ := get(t, http.MethodGet, url+"/c/b.txt", nil); !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") || resp.Header.Get("X-Wfs-Meta-Owner") != "" {
+		t.Fatal(resp.Header)
+	}
+}
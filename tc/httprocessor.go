@@ -9,6 +9,7 @@ package tc
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
@@ -53,8 +54,14 @@ func appendHandler(hc *tlnet.HttpContext) {
 		}
 	}
 
+	mb, ok := metaParam(hc)
+	if !ok {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+		return
+	}
+
 	if len(bs) > 0 && name != "" {
-		if _, err := sys.AppendData(name, bs, sys.CompressType); err == nil {
+		if _, err := sys.AppendData(name, bs, sys.CompressOf(name), mb); err == nil {
 			hc.ResponseString(`{"status":true, "name":"` + name + `","size":` + strconv.Itoa(len(bs)) + `}`)
 		} else {
 			hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
@@ -62,6 +69,19 @@ func appendHandler(hc *tlnet.HttpContext) {
 	}
 }
 
+// metaParam reads the contentType and ttl (seconds) fields and the X-Wfs-Meta-* headers stored with the file
+func metaParam(hc *tlnet.HttpContext) (mb *sys.MetaBean, ok bool) {
+	mb = &sys.MetaBean{ContentType: hc.PostParamTrimSpace("contentType"), Meta: headerMeta(hc.Request().Header, wfsMetaPrefix)}
+	if v := hc.PostParamTrimSpace("ttl"); v != "" {
+		ttl, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ttl <= 0 {
+			return
+		}
+		mb.Expire = util.ExpireAt(ttl)
+	}
+	return mb, true
+}
+
 func deleteHandler(hc *tlnet.HttpContext) {
 	defer util.Recover()
 	if !strings.EqualFold(hc.ReqInfo.Method, http.MethodDelete) {
@@ -108,3 +128,102 @@ func renameHandler(hc *tlnet.HttpContext) {
 		hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
 	}
 }
+
+func uploadInitHandler(hc *tlnet.HttpContext) {
+	defer util.Recover()
+	name := hc.PostParamTrimSpace("filename")
+	if decoded, err := url.QueryUnescape(name); err == nil {
+		name = decoded
+	}
+	mb, ok := metaParam(hc)
+	if name == "" || !ok {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+		return
+	}
+	if id, err := sys.UploadInit(name, sys.CompressOf(name), mb); err == nil {
+		nbs, _ := json.Marshal(name)
+		hc.ResponseString(`{"status":true, "name":` + string(nbs) + `,"uploadId":"` + strconv.FormatInt(id, 10) + `"}`)
+	} else {
+		hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
+	}
+}
+
+func uploadPartHandler(hc *tlnet.HttpContext) {
+	defer util.Recover()
+	id, err1 := strconv.ParseInt(hc.PostParamTrimSpace("uploadId"), 10, 64)
+	number, err2 := strconv.ParseInt(hc.PostParamTrimSpace("partNumber"), 10, 32)
+	if err1 != nil || err2 != nil {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+		return
+	}
+
+	file, _, err := hc.FormFile("file")
+	if err != nil {
+		hc.ResponseString(err.Error())
+		return
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	io.Copy(&buf, io.LimitReader(file, sys.DataMaxsize+1))
+	bs := buf.Bytes()
+
+	if err := sys.UploadPart(id, int32(number), bs); err == nil {
+		hc.ResponseString(`{"status":true, "partNumber":` + strconv.FormatInt(number, 10) + `,"size":` + strconv.Itoa(len(bs)) + `}`)
+	} else {
+		hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
+	}
+}
+
+func uploadCompleteHandler(hc *tlnet.HttpContext) {
+	defer util.Recover()
+	id, err := strconv.ParseInt(hc.PostParamTrimSpace("uploadId"), 10, 64)
+	if err != nil {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+		return
+	}
+	if _, err := sys.UploadComplete(id); err == nil {
+		hc.ResponseString(`{"status":true, "uploadId":"` + strconv.FormatInt(id, 10) + `"}`)
+	} else {
+		hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
+	}
+}
+
+func uploadAbortHandler(hc *tlnet.HttpContext) {
+	defer util.Recover()
+	id, err := strconv.ParseInt(hc.PostParamTrimSpace("uploadId"), 10, 64)
+	if err != nil {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+		return
+	}
+	if err := sys.UploadAbort(id); err == nil {
+		hc.ResponseString(`{"status":true, "uploadId":"` + strconv.FormatInt(id, 10) + `"}`)
+	} else {
+		hc.ResponseString(`{"status":false, "desc":"` + err.WfsError().GetInfo() + `"}`)
+	}
+}
+
+func listHandler(hc *tlnet.HttpContext) {
+	defer util.Recover()
+	limit := 100
+	if v := hc.PostParamTrimSpace("limit"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i > 0 {
+			limit = i
+		} else {
+			hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_PARAMS.WfsError().GetInfo() + `"}`)
+			return
+		}
+	}
+	lr := sys.ListPaths(hc.PostParam("prefix"), hc.PostParam("startAfter"), hc.PostParam("delimiter"), limit)
+	if lr == nil {
+		hc.ResponseString(`{"status":false, "desc":"` + sys.ERR_STOPSERVICE.WfsError().GetInfo() + `"}`)
+		return
+	}
+	lp := &ListPage{Status: true, Entries: make([]*ListItem, 0, len(lr.Entries)), Prefixes: lr.Prefixes, Next: lr.Next}
+	for _, e := range lr.Entries {
+		lp.Entries = append(lp.Entries, &ListItem{Path: e.Path, Size: e.Size, Timestramp: e.Timestramp, CompressType: e.CompressType})
+	}
+	if bs, err := json.Marshal(lp); err == nil {
+		hc.ResponseBytes(http.StatusOK, bs)
+	}
+}
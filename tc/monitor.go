@@ -28,6 +28,12 @@ type sysmonitor struct {
 	RamUsage     float64
 	DiskFree     uint64
 	CpuUsage     float64
+	Collisions   int64
+	MmapNodes    int
+	MmapBytes    int64
+	Maps         int64
+	Unmaps       int64
+	Preads       int64
 }
 
 func monitorToJson() (_r string, err error) {
@@ -47,6 +53,10 @@ func getSysMonitor() (_r *sysmonitor) {
 	_r.Alloc = rtm.Alloc
 	_r.TotalAlloc = rtm.TotalAlloc
 	_r.NumGC = rtm.NumGC
+	_r.Collisions = sys.Collisions()
+	if mb := sys.MmapStatus(); mb != nil {
+		_r.MmapNodes, _r.MmapBytes, _r.Maps, _r.Unmaps, _r.Preads = mb.Nodes, mb.Bytes, mb.Maps, mb.Unmaps, mb.Preads
+	}
 
 	if ram, err := getRAM(); err == nil {
 		_r.RamUsage = float64(ram.UsedMB) / float64(ram.TotalMB)
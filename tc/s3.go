@@ -0,0 +1,398 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package tc
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	goutil "github.com/donnie4w/gofer/util"
+	"github.com/donnie4w/tlnet"
+	"github.com/donnie4w/wfs/sys"
+	"github.com/donnie4w/wfs/util"
+)
+
+// s3Service serves a subset of the S3 REST API with path-style addressing,
+// the object key of bucket b is stored at the WFS path "b/key".
+type s3Service struct {
+	isClose bool
+	tln     *tlnet.Tlnet
+}
+
+var s3service = &s3Service{false, tlnet.NewTlnet()}
+
+func (t *s3Service) Serve() (err error) {
+	if sys.S3LISTEN > 0 {
+		err = t._serve(fmt.Sprint(":", sys.S3LISTEN), sys.Conf.Ssl_crt, sys.Conf.Ssl_crt_key)
+	}
+	return
+}
+
+func (t *s3Service) Close() (err error) {
+	defer util.Recover()
+	if sys.S3LISTEN > 0 {
+		t.isClose = true
+		err = t.tln.Close()
+	}
+	return
+}
+
+func (t *s3Service) _serve(addr string, serverCrt, serverKey string) (err error) {
+	defer util.Recover()
+	if addr, err = util.ParseAddr(addr); err != nil {
+		return
+	}
+	t.tln.HandleFunc("/", nil, s3Handler)
+	if serverCrt != "" && serverKey != "" {
+		sys.FmtLog("s3 start tls [", addr, "]")
+		err = t.tln.HttpsStart(addr, serverCrt, serverKey)
+	}
+	if !t.isClose {
+		sys.FmtLog("s3 listen [", addr, "]")
+		err = t.tln.HttpStart(addr)
+	}
+	if !t.isClose && err != nil {
+		fmt.Println("s3 start failed:", err.Error())
+		sys.Wfs.Close()
+		os.Exit(1)
+	}
+	if t.isClose {
+		err = nil
+	}
+	return
+}
+
+const (
+	s3xmlns      = "http://s3.amazonaws.com/doc/2006-03-01/"
+	s3MetaPrefix = "X-Amz-Meta-"
+)
+
+type s3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string
+	Message   string
+	Resource  string `xml:",omitempty"`
+	RequestId string `xml:",omitempty"`
+	status    int
+}
+
+func newS3Error(status int, code, message string) *s3Error {
+	return &s3Error{Code: code, Message: message, status: status}
+}
+
+var (
+	s3ErrAccessDenied          = newS3Error(http.StatusForbidden, "AccessDenied", "Access Denied")
+	s3ErrInvalidAccessKeyId    = newS3Error(http.StatusForbidden, "InvalidAccessKeyId", "The AWS access key Id you provided does not exist in our records.")
+	s3ErrSignatureDoesNotMatch = newS3Error(http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+	s3ErrRequestTimeTooSkewed  = newS3Error(http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.")
+	s3ErrExpiredToken          = newS3Error(http.StatusForbidden, "AccessDenied", "Request has expired")
+	s3ErrAuthorizationHeader   = newS3Error(http.StatusBadRequest, "AuthorizationHeaderMalformed", "The authorization header is malformed.")
+	s3ErrMissingContentSha256  = newS3Error(http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: x-amz-content-sha256")
+	s3ErrContentSha256Mismatch = newS3Error(http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.")
+	s3ErrBadDigest             = newS3Error(http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what we received.")
+	s3ErrIncompleteBody        = newS3Error(http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.")
+	s3ErrEntityTooLarge        = newS3Error(http.StatusBadRequest, "EntityTooLarge", "Your proposed upload exceeds the maximum allowed object size.")
+	s3ErrEmptyObject           = newS3Error(http.StatusBadRequest, "InvalidArgument", "Empty objects are not supported.")
+	s3ErrInvalidArgument       = newS3Error(http.StatusBadRequest, "InvalidArgument", "Invalid Argument")
+	s3ErrInvalidCopySource     = newS3Error(http.StatusBadRequest, "InvalidArgument", "Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+	s3ErrNoSuchKey             = newS3Error(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+	s3ErrMethodNotAllowed      = newS3Error(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+	s3ErrInternalError         = newS3Error(http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.")
+	s3ErrCorrupt               = newS3Error(http.StatusInternalServerError, "InternalError", "The data of the object is corrupt.")
+	s3ErrNotImplemented        = newS3Error(http.StatusNotImplemented, "NotImplemented", "A header you provided implies functionality that is not implemented.")
+)
+
+func s3Fail(w http.ResponseWriter, r *http.Request, e *s3Error) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(e.status)
+		return
+	}
+	re := *e
+	re.Resource, re.RequestId = r.URL.Path, w.Header().Get("x-amz-request-id")
+	s3Xml(w, e.status, &re)
+}
+
+func s3Xml(w http.ResponseWriter, status int, v any) {
+	bs, _ := xml.Marshal(v)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(bs)
+}
+
+func s3Handler(w http.ResponseWriter, r *http.Request) {
+	defer util.Recover()
+	w.Header().Set("x-amz-request-id", strconv.FormatUint(uint64(goutil.UUID64()), 16))
+	sign, e := s3Authorize(r)
+	if e != nil {
+		s3Fail(w, r, e)
+		return
+	}
+	bucket, key := s3SplitPath(r.URL.Path)
+	switch {
+	case bucket == "":
+		s3Fail(w, r, s3ErrNotImplemented)
+	case key == "":
+		if r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+			s3ListObjectsV2(w, r, bucket)
+		} else {
+			s3Fail(w, r, s3ErrNotImplemented)
+		}
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		s3GetObject(w, r, bucket+"/"+key)
+	case r.Method == http.MethodPut:
+		if r.Header.Get("x-amz-copy-source") != "" {
+			s3CopyObject(w, r, bucket+"/"+key)
+		} else {
+			s3PutObject(w, r, sign, bucket+"/"+key)
+		}
+	case r.Method == http.MethodDelete:
+		s3DeleteObject(w, r, bucket+"/"+key)
+	default:
+		s3Fail(w, r, s3ErrMethodNotAllowed)
+	}
+}
+
+func s3SplitPath(path string) (bucket, key string) {
+	path = strings.TrimPrefix(path, "/")
+	if i := strings.Index(path, "/"); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+	return path, ""
+}
+
+func s3PutObject(w http.ResponseWriter, r *http.Request, sign *s3Signature, path string) {
+	bs, e := s3ReadPayload(r, sign)
+	if e != nil {
+		s3Fail(w, r, e)
+		return
+	}
+	if md := r.Header.Get("Content-MD5"); md != "" {
+		if sum := md5.Sum(bs); md != base64.StdEncoding.EncodeToString(sum[:]) {
+			s3Fail(w, r, s3ErrBadDigest)
+			return
+		}
+	}
+	if e = s3Append(path, bs, s3Meta(r)); e != nil {
+		s3Fail(w, r, e)
+		return
+	}
+	if sb := sys.StatData(path); sb != nil {
+		w.Header().Set("ETag", etag(sb.Fingerprint, ""))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// s3Meta reads the Content-Type, the x-amz-meta-* headers and the X-Wfs-Ttl (seconds) header of r
+func s3Meta(r *http.Request) *sys.MetaBean {
+	mb := &sys.MetaBean{ContentType: r.Header.Get("Content-Type"), Meta: headerMeta(r.Header, s3MetaPrefix)}
+	if ttl, err := strconv.ParseInt(r.Header.Get("X-Wfs-Ttl"), 10, 64); err == nil {
+		mb.Expire = util.ExpireAt(ttl)
+	}
+	return mb
+}
+
+func s3Append(path string, bs []byte, mb *sys.MetaBean) *s3Error {
+	if len(bs) == 0 {
+		return s3ErrEmptyObject
+	}
+	if int64(len(bs)) > sys.DataMaxsize {
+		return s3ErrEntityTooLarge
+	}
+	if _, err := sys.AppendData(path, bs, sys.CompressOf(path), mb); err != nil && !err.Equal(sys.ERR_EXSIT) {
+		if err.Equal(sys.ERR_OVERSIZE) {
+			return s3ErrEntityTooLarge
+		}
+		return s3ErrInternalError
+	}
+	return nil
+}
+
+func s3GetObject(w http.ResponseWriter, r *http.Request, path string) {
+	sb := sys.StatData(path)
+	if sb == nil {
+		s3Fail(w, r, s3ErrNoSuchKey)
+		return
+	}
+	if sys.Corrupt(path) {
+		s3Fail(w, r, s3ErrCorrupt)
+		return
+	}
+	header := w.Header()
+	ct := typeByName(path, sb.ContentType)
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	header.Set("Content-Type", ct)
+	header.Set("ETag", etag(sb.Fingerprint, ""))
+	setHeaderMeta(header, s3MetaPrefix, sb.Meta)
+	content := &lazyContent{size: sb.Size, load: func() io.ReadSeeker { return dataReader(path) }}
+	var modtime time.Time
+	if sb.Timestramp > 0 {
+		modtime = s3Time(sb.Timestramp)
+	}
+	http.ServeContent(w, r, "", modtime, content)
+	content.Close()
+}
+
+func s3DeleteObject(w http.ResponseWriter, r *http.Request, path string) {
+	if err := sys.DelData(path); err != nil && !err.Equal(sys.ERR_NOTEXSIT) {
+		s3Fail(w, r, s3ErrInternalError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type s3CopyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	LastModified string
+	ETag         string
+}
+
+func s3CopyObject(w http.ResponseWriter, r *http.Request, path string) {
+	src := r.Header.Get("x-amz-copy-source")
+	if i := strings.Index(src, "?"); i >= 0 {
+		src = src[:i]
+	}
+	if decoded, err := url.PathUnescape(src); err == nil {
+		src = decoded
+	}
+	bucket, key := s3SplitPath(src)
+	if bucket == "" || key == "" {
+		s3Fail(w, r, s3ErrInvalidCopySource)
+		return
+	}
+	ssb := sys.StatData(bucket + "/" + key)
+	if ssb != nil && ssb.Size > sys.DataMaxsize {
+		// the copy is appended at once, a larger source is not read into memory
+		s3Fail(w, r, s3ErrEntityTooLarge)
+		return
+	}
+	bs := sys.GetData(bucket + "/" + key)
+	if bs == nil && sys.Corrupt(bucket+"/"+key) {
+		s3Fail(w, r, s3ErrCorrupt)
+		return
+	}
+	if bs == nil || ssb == nil {
+		s3Fail(w, r, s3ErrNoSuchKey)
+		return
+	}
+	mb := &sys.MetaBean{ContentType: ssb.ContentType, Meta: ssb.Meta, Expire: ssb.Expire}
+	if strings.EqualFold(r.Header.Get("x-amz-metadata-directive"), "REPLACE") {
+		mb = s3Meta(r)
+	}
+	if e := s3Append(path, bs, mb); e != nil {
+		s3Fail(w, r, e)
+		return
+	}
+	sb := sys.StatData(path)
+	if sb == nil {
+		s3Fail(w, r, s3ErrInternalError)
+		return
+	}
+	s3Xml(w, http.StatusOK, &s3CopyObjectResult{Xmlns: s3xmlns, LastModified: s3TimeString(sb.Timestramp), ETag: etag(sb.Fingerprint, "")})
+}
+
+type s3Object struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int64
+	StorageClass string
+}
+
+type s3CommonPrefix struct {
+	Prefix string
+}
+
+type s3ListBucketResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Xmlns                 string   `xml:"xmlns,attr"`
+	Name                  string
+	Prefix                string
+	Delimiter             string `xml:",omitempty"`
+	StartAfter            string `xml:",omitempty"`
+	ContinuationToken     string `xml:",omitempty"`
+	NextContinuationToken string `xml:",omitempty"`
+	EncodingType          string `xml:",omitempty"`
+	KeyCount              int
+	MaxKeys               int
+	IsTruncated           bool
+	Contents              []*s3Object
+	CommonPrefixes        []*s3CommonPrefix
+}
+
+func s3ListObjectsV2(w http.ResponseWriter, r *http.Request, bucket string) {
+	q := r.URL.Query()
+	lr := &s3ListBucketResult{Xmlns: s3xmlns, Name: bucket, Prefix: q.Get("prefix"), Delimiter: q.Get("delimiter"), StartAfter: q.Get("start-after"), ContinuationToken: q.Get("continuation-token"), EncodingType: q.Get("encoding-type"), MaxKeys: 1000}
+	if lr.EncodingType != "" && lr.EncodingType != "url" {
+		s3Fail(w, r, s3ErrInvalidArgument)
+		return
+	}
+	if v := q.Get("max-keys"); v != "" {
+		if i, err := strconv.Atoi(v); err == nil && i >= 0 {
+			lr.MaxKeys = min(i, 1000)
+		} else {
+			s3Fail(w, r, s3ErrInvalidArgument)
+			return
+		}
+	}
+	marker := lr.StartAfter
+	if lr.ContinuationToken != "" {
+		if bs, err := base64.StdEncoding.DecodeString(lr.ContinuationToken); err == nil {
+			marker = string(bs)
+		} else {
+			s3Fail(w, r, s3ErrInvalidArgument)
+			return
+		}
+	}
+
+	base := bucket + "/"
+	if lb := sys.ListPaths(base+lr.Prefix, base+marker, lr.Delimiter, lr.MaxKeys); lb != nil {
+		for _, e := range lb.Entries {
+			lr.Contents = append(lr.Contents, &s3Object{Key: s3EncodeKey(e.Path[len(base):], lr.EncodingType), LastModified: s3TimeString(e.Timestramp), ETag: etag(e.Fingerprint, ""), Size: e.Size, StorageClass: "STANDARD"})
+		}
+		for _, cp := range lb.Prefixes {
+			lr.CommonPrefixes = append(lr.CommonPrefixes, &s3CommonPrefix{Prefix: s3EncodeKey(cp[len(base):], lr.EncodingType)})
+		}
+		lr.KeyCount = len(lb.Entries) + len(lb.Prefixes)
+		if lb.Next != "" {
+			lr.IsTruncated = true
+			lr.NextContinuationToken = base64.StdEncoding.EncodeToString([]byte(lb.Next[len(base):]))
+		}
+	}
+	if lr.EncodingType != "" {
+		lr.Prefix, lr.Delimiter, lr.StartAfter = s3EncodeKey(lr.Prefix, lr.EncodingType), s3EncodeKey(lr.Delimiter, lr.EncodingType), s3EncodeKey(lr.StartAfter, lr.EncodingType)
+	}
+	s3Xml(w, http.StatusOK, lr)
+}
+
+func s3EncodeKey(key, encodingType string) string {
+	if encodingType == "url" {
+		return url.QueryEscape(key)
+	}
+	return key
+}
+
+func s3Time(timestramp int64) time.Time {
+	return time.Unix(0, timestramp).UTC()
+}
+
+func s3TimeString(timestramp int64) string {
+	return s3Time(timestramp).Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2023, donnie <donnie4w@gmail.com>
+// All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//
+// github.com/donnie4w/wfs
+
+package tc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/donnie4w/wfs/keystore"
+	"github.com/donnie4w/wfs/sys"
+)
+
+const (
+	sigV4Algorithm       = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat      = "20060102T150405Z"
+	sigV4MaxSkew         = 15 * time.Minute
+	unsignedPayload      = "UNSIGNED-PAYLOAD"
+	streamingPayload     = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingTrailer     = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	emptyStringSHA256    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sigV4PayloadPrefix   = "AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4MaxPresignedAge = 7 * 24 * time.Hour
+)
+
+var s3Now = time.Now
+
+// s3SecretKey returns the secret of an access key. The access key is the name of an
+// admin account and the secret is the md5 hex of its password, as kept by the keystore.
+var s3SecretKey = func(accessKey string) (string, bool) {
+	if u, ok := Admin.GetAdmin(accessKey); ok {
+		return u.Pwd, true
+	}
+	return "", false
+}
+
+// s3Signature is the verified signature of a request, chunked payloads are
+// verified against it.
+type s3Signature struct {
+	key         []byte
+	amzDate     string
+	scope       string
+	signature   string
+	payloadHash string
+}
+
+func s3Authorize(r *http.Request) (sign *s3Signature, e *s3Error) {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, sigV4Algorithm+" ") {
+		return s3AuthorizeHeader(r, auth[len(sigV4Algorithm)+1:])
+	}
+	if r.URL.Query().Get("X-Amz-Algorithm") == sigV4Algorithm {
+		return s3AuthorizeQuery(r)
+	}
+	return nil, s3ErrAccessDenied
+}
+
+func s3AuthorizeHeader(r *http.Request, auth string) (sign *s3Signature, e *s3Error) {
+	var credential, signedHeaders, signature string
+	for _, kv := range strings.Split(auth, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(kv), "="); ok {
+			switch k {
+			case "Credential":
+				credential = v
+			case "SignedHeaders":
+				signedHeaders = v
+			case "Signature":
+				signature = v
+			}
+		}
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if amzDate == "" {
+		if t, err := http.ParseTime(r.Header.Get("Date")); err == nil {
+			amzDate = t.UTC().Format(sigV4TimeFormat)
+		}
+	}
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash == "" {
+		return nil, s3ErrMissingContentSha256
+	}
+	if sign, e = s3NewSignature(credential, amzDate, signedHeaders, signature); e != nil {
+		return
+	}
+	if e = s3CheckTime(amzDate, 0); e != nil {
+		return
+	}
+	sign.payloadHash = payloadHash
+	return sign, sign.verify(r, strings.Split(signedHeaders, ";"), r.URL.Query(), payloadHash)
+}
+
+func s3AuthorizeQuery(r *http.Request) (sign *s3Signature, e *s3Error) {
+	q := r.URL.Query()
+	amzDate, signedHeaders := q.Get("X-Amz-Date"), q.Get("X-Amz-SignedHeaders")
+	if sign, e = s3NewSignature(q.Get("X-Amz-Credential"), amzDate, signedHeaders, q.Get("X-Amz-Signature")); e != nil {
+		return
+	}
+	expires, err := strconv.ParseInt(q.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > sigV4MaxPresignedAge {
+		return nil, s3ErrAuthorizationHeader
+	}
+	if e = s3CheckTime(amzDate, time.Duration(expires)*time.Second); e != nil {
+		return
+	}
+	q.Del("X-Amz-Signature")
+	sign.payloadHash = unsignedPayload
+	return sign, sign.verify(r, strings.Split(signedHeaders, ";"), q, unsignedPayload)
+}
+
+func s3NewSignature(credential, amzDate, signedHeaders, signature string) (sign *s3Signature, e *s3Error) {
+	cs := strings.Split(credential, "/")
+	if len(cs) != 5 || cs[4] != "aws4_request" || signedHeaders == "" || signature == "" || len(amzDate) < 8 || amzDate[:8] != cs[1] {
+		return nil, s3ErrAuthorizationHeader
+	}
+	secret, ok := s3SecretKey(cs[0])
+	if !ok {
+		return nil, s3ErrInvalidAccessKeyId
+	}
+	key := hmacSHA256([]byte("AWS4"+secret), cs[1])
+	for _, s := range cs[2:] {
+		key = hmacSHA256(key, s)
+	}
+	return &s3Signature{key: key, amzDate: amzDate, scope: strings.Join(cs[1:], "/"), signature: signature}, nil
+}
+
+// s3CheckTime rejects requests signed too far from now, presigned requests are valid from amzDate to amzDate+expires
+func s3CheckTime(amzDate string, expires time.Duration) *s3Error {
+	t, err := time.Parse(sigV4TimeFormat, amzDate)
+	if err != nil {
+		return s3ErrAuthorizationHeader
+	}
+	now := s3Now()
+	if expires > 0 {
+		if now.Before(t.Add(-sigV4MaxSkew)) {
+			return s3ErrRequestTimeTooSkewed
+		}
+		if now.After(t.Add(expires)) {
+			return s3ErrExpiredToken
+		}
+		return nil
+	}
+	if d := now.Sub(t); d > sigV4MaxSkew || d < -sigV4MaxSkew {
+		return s3ErrRequestTimeTooSkewed
+	}
+	return nil
+}
+
+func (t *s3Signature) verify(r *http.Request, signedHeaders []string, query url.Values, payloadHash string) *s3Error {
+	var buf strings.Builder
+	buf.WriteString(r.Method)
+	buf.WriteByte('\n')
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+	buf.WriteString(s3URIEncode(path, false))
+	buf.WriteByte('\n')
+	buf.WriteString(s3CanonicalQuery(query))
+	buf.WriteByte('\n')
+	for _, h := range signedHeaders {
+		buf.WriteString(h)
+		buf.WriteByte(':')
+		buf.WriteString(s3HeaderValue(r, h))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	buf.WriteString(strings.Join(signedHeaders, ";"))
+	buf.WriteByte('\n')
+	buf.WriteString(payloadHash)
+
+	sts := sigV4Algorithm + "\n" + t.amzDate + "\n" + t.scope + "\n" + sha256Hex([]byte(buf.String()))
+	if !hmac.Equal([]byte(hex.EncodeToString(hmacSHA256(t.key, sts))), []byte(t.signature)) {
+		return s3ErrSignatureDoesNotMatch
+	}
+	return nil
+}
+
+func s3HeaderValue(r *http.Request, name string) string {
+	var v string
+	switch name {
+	case "host":
+		v = r.Host
+	case "content-length":
+		if v = r.Header.Get("Content-Length"); v == "" && r.ContentLength >= 0 {
+			v = strconv.FormatInt(r.ContentLength, 10)
+		}
+	default:
+		v = strings.Join(r.Header.Values(name), ",")
+	}
+	return strings.Join(strings.Fields(v), " ")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ss := make([]string, 0, len(query))
+	for _, k := range keys {
+		vs := query[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			ss = append(ss, s3URIEncode(k, true)+"="+s3URIEncode(v, true))
+		}
+	}
+	return strings.Join(ss, "&")
+}
+
+// s3URIEncode encodes every byte except the unreserved characters, as required by SigV4
+func s3URIEncode(s string, encodeSlash bool) string {
+	const hexUpper = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			buf.WriteByte(c)
+		} else {
+			buf.WriteByte('%')
+			buf.WriteByte(hexUpper[c>>4])
+			buf.WriteByte(hexUpper[c&15])
+		}
+	}
+	return buf.String()
+}
+
+// s3ReadPayload reads the request body of at most DataMaxsize bytes and checks it against x-amz-content-sha256
+func s3ReadPayload(r *http.Request, sign *s3Signature) (bs []byte, e *s3Error) {
+	switch sign.payloadHash {
+	case streamingPayload, streamingTrailer:
+		if bs, e = sign.readChunked(r.Body, sign.payloadHash == streamingPayload); e == nil {
+			if v := r.Header.Get("X-Amz-Decoded-Content-Length"); v != "" && v != strconv.Itoa(len(bs)) {
+				e = s3ErrIncompleteBody
+			}
+		}
+		return
+	}
+	if r.ContentLength > sys.DataMaxsize {
+		return nil, s3ErrEntityTooLarge
+	}
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, io.LimitReader(r.Body, sys.DataMaxsize+1)); err != nil {
+		return nil, s3ErrIncompleteBody
+	}
+	if bs = buf.Bytes(); int64(len(bs)) > sys.DataMaxsize {
+		return nil, s3ErrEntityTooLarge
+	}
+	if sign.payloadHash != unsignedPayload && !strings.EqualFold(sign.payloadHash, sha256Hex(bs)) {
+		return nil, s3ErrContentSha256Mismatch
+	}
+	return
+}
+
+// readChunked decodes an aws-chunked body, the signature of every chunk is chained to the seed signature
+func (t *s3Signature) readChunked(body io.Reader, signed bool) (_r []byte, e *s3Error) {
+	br := bufio.NewReader(body)
+	prev := t.signature
+	var buf bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, s3ErrIncompleteBody
+		}
+		line = strings.TrimRight(line, "\r\n")
+		sizeStr, ext, _ := strings.Cut(line, ";")
+		size, err := strconv.ParseInt(sizeStr, 16, 64)
+		if err != nil || size < 0 {
+			return nil, s3ErrIncompleteBody
+		}
+		if int64(buf.Len())+size > sys.DataMaxsize {
+			return nil, s3ErrEntityTooLarge
+		}
+		chunk := make([]byte, size)
+		if _, err = io.ReadFull(br, chunk); err != nil {
+			return nil, s3ErrIncompleteBody
+		}
+		if signed {
+			sig, ok := strings.CutPrefix(ext, "chunk-signature=")
+			if !ok {
+				return nil, s3ErrSignatureDoesNotMatch
+			}
+			sts := sigV4PayloadPrefix + "\n" + t.amzDate + "\n" + t.scope + "\n" + prev + "\n" + emptyStringSHA256 + "\n" + sha256Hex(chunk)
+			if !hmac.Equal([]byte(hex.EncodeToString(hmacSHA256(t.key, sts))), []byte(sig)) {
+				return nil, s3ErrSignatureDoesNotMatch
+			}
+			prev = sig
+		}
+		buf.Write(chunk)
+		if size == 0 && !signed {
+			for {
+				if trailer, err := br.ReadString('\n'); err != nil {
+					return nil, s3ErrIncompleteBody
+				} else if strings.TrimRight(trailer, "\r\n") == "" {
+					return buf.Bytes(), nil
+				}
+			}
+		}
+		if crlf, err := br.ReadString('\n'); err != nil || strings.TrimRight(crlf, "\r\n") != "" {
+			return nil, s3ErrIncompleteBody
+		}
+		if size == 0 {
+			return buf.Bytes(), nil
+		}
+	}
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(bs []byte) string {
+	sum := sha256.Sum256(bs)
+	return hex.EncodeToString(sum[:])
+}
@@ -796,6 +796,94 @@ const (
             </tbody>
         </table>
     </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>Integrity Scrub</h6>
+        <button id="scrubStart" class="btn btn-primary btn-sm" onclick="scrub('start')">start scrub</button>
+        <button id="scrubStop" class="btn btn-secondary btn-sm" onclick="scrub('stop')">stop</button>
+        <span id="scrubProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>Finding</th>
+                <th>File Name</th>
+                <th>Offset</th>
+                <th>Path</th>
+                <th>Fingerprint</th>
+                <th>Quarantined</th>
+            </tr>
+            <tbody id="scrubTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>Compression Dictionaries</h6>
+        <button id="dictTrain" class="btn btn-primary btn-sm" onclick="dict('train')">train dictionaries</button>
+        <span id="dictProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>Id</th>
+                <th>Scope</th>
+                <th>Samples</th>
+                <th>zstd ratio</th>
+                <th>dictionary ratio</th>
+                <th>Trained time</th>
+                <th>In use</th>
+            </tr>
+            <tbody id="dictTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>Data Directories</h6>
+        <button id="rebalance" class="btn btn-primary btn-sm" onclick="datadir('rebalance')">rebalance</button>
+        <span id="rebalanceProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>Directory</th>
+                <th>Weight</th>
+                <th>Free(GB)</th>
+                <th>Total(GB)</th>
+                <th>Files</th>
+                <th>Status</th>
+            </tr>
+            <tbody id="datadirTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>Erasure Coded Files</h6>
+        <button id="repairShards" class="btn btn-primary btn-sm" onclick="shard('repair')">repair</button>
+        <span id="repairProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>File</th>
+                <th>Shards</th>
+                <th>Size(MB)</th>
+                <th>Missing</th>
+                <th>Damaged</th>
+                <th>Health</th>
+                <th>Directories</th>
+            </tr>
+            <tbody id="shardTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>Defragmentation Jobs</h6>
+        <button id="defragJobStart" class="btn btn-primary btn-sm" onclick="defragJob('start')">run now</button>
+        <span id="defragJobProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>Start</th>
+                <th>Trigger</th>
+                <th>Files</th>
+                <th>Reclaimed(MB)</th>
+                <th>Duration(s)</th>
+                <th>Errors</th>
+            </tr>
+            <tbody id="defragJobTableBody">
+            </tbody>
+        </table>
+    </div>
     <script>
         function fragment(n) {
             if (confirm("[" + n + "]Are you sure you want to defragment? \nIt is recommended that defragmentation should be performed in a state where WFS service operations are relatively low to reduce the impact on front-end service quality ")) {
@@ -824,6 +912,195 @@ const (
                 });
             }
         }
+        function scrub(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/scrub', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "scrubbing... " : (data.startTime > 0 ? "finished " : "")
+                if (data.startTime > 0) {
+                    p += "files:" + data.nodes + "/" + data.nodeTotal + " blocks:" + data.blocks + " bytes:" + data.bytes + " paths:" + data.paths
+                }
+                if (data.node) {
+                    p += " [" + data.node + "]"
+                }
+                document.getElementById("scrubProgress").innerText = p
+                document.getElementById("scrubStart").disabled = data.running
+                document.getElementById("scrubStop").disabled = !data.running
+                const body = document.getElementById("scrubTableBody")
+                body.innerHTML = ""
+                data.findings.forEach(f => {
+                    const tr = body.insertRow()
+                    const cells = [f.kind, f.node, f.offset, f.path, f.fingerprint, f.quarantined ? "yes" : "no"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v === undefined ? "" : v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => scrub(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        scrub("")
+        function dict(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/dict', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "training... " : (data.startTime > 0 ? "finished " : "")
+                if (data.startTime > 0) {
+                    p += "samples:" + data.samples
+                }
+                document.getElementById("dictProgress").innerText = p
+                document.getElementById("dictTrain").disabled = data.running
+                const body = document.getElementById("dictTableBody")
+                body.innerHTML = ""
+                const ratio = (n, size) => size > 0 ? (100 * n / size).toFixed(1) + "%" : ""
+                data.dicts.forEach(d => {
+                    const tr = body.insertRow()
+                    const cells = [d.id, d.scope, d.samples, ratio(d.plain, d.size), ratio(d.dicted, d.size), d.time, d.current ? "yes" : "no"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => dict(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        dict("")
+        function datadir(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/datadir', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "rebalancing... " : (data.startTime > 0 ? "finished " : "")
+                if (data.startTime > 0) {
+                    p += "moved files:" + data.moved + " bytes:" + data.bytes
+                }
+                document.getElementById("rebalanceProgress").innerText = p
+                document.getElementById("rebalance").disabled = data.running
+                const body = document.getElementById("datadirTableBody")
+                body.innerHTML = ""
+                const gb = n => (n / (1 << 30)).toFixed(1)
+                data.dirs.forEach(d => {
+                    const tr = body.insertRow()
+                    const cells = [d.path, d.weight, gb(d.free), gb(d.total), d.nodes, d.readOnly ? "read-only" : "writable"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => datadir(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        datadir("")
+        function shard(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/shard', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "repairing... " : (data.startTime > 0 ? "finished " : "")
+                if (data.startTime > 0) {
+                    p += "repaired shards:" + data.repaired + " failed files:" + data.failed
+                }
+                document.getElementById("repairProgress").innerText = p
+                document.getElementById("repairShards").disabled = data.running
+                const body = document.getElementById("shardTableBody")
+                body.innerHTML = ""
+                data.nodes.forEach(n => {
+                    const tr = body.insertRow()
+                    const bad = n.missing + n.damaged
+                    const health = bad == 0 ? "healthy" : (bad <= n.parity ? "degraded" : "lost")
+                    const cells = [n.node, n.data + "+" + n.parity, (n.size / (1 << 20)).toFixed(1), n.missing, n.damaged, health, n.dirs.join(" ")]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => shard(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        shard("")
+
+        function defragJob(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/defragjob', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "defragmenting... " : ""
+                if (data.running) {
+                    p += "Files:" + data.nodes + "/" + data.nodeTotal + " Reclaimed(MB):" + (data.reclaimed / (1 << 20)).toFixed(1) + " " + data.node.join(" ")
+                }
+                document.getElementById("defragJobProgress").innerText = p
+                document.getElementById("defragJobStart").disabled = data.running
+                const body = document.getElementById("defragJobTableBody")
+                body.innerHTML = ""
+                data.history.forEach(r => {
+                    const tr = body.insertRow()
+                    const cells = [new Date(r.startTime / 1e6).toLocaleString(), r.auto ? "scheduled" : "manual", r.nodes, (r.reclaimed / (1 << 20)).toFixed(1), ((r.endTime - r.startTime) / 1e9).toFixed(1), (r.errors || []).join("; ")]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => defragJob(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        defragJob("")
     </script>
 </body>
 
@@ -894,6 +1171,11 @@ const (
                 <th>Disk Free Space(GB)</th>
                 <th>Memory usage</th>
                 <th>CPU usage</th>
+                <th>Fingerprint collisions</th>
+                <th>Mapped files</th>
+                <th>Mapped(MB)</th>
+                <th>Maps/Unmaps</th>
+                <th>Preads</th>
             </tr>
             <tbody id="monitorBody">
             </tbody>
@@ -930,7 +1212,12 @@ const (
                     + '<td>' + json.NumCPU + '</td>'
                     + '<td>' + json.DiskFree + '</td>'
                     + '<td>' + Math.round(json.RamUsage * 10000) / 100 + '%</td>'
-                    + '<td>' + Math.round(json.CpuUsage * 100) / 100 + '%</td>';
+                    + '<td>' + Math.round(json.CpuUsage * 100) / 100 + '%</td>'
+                    + '<td>' + json.Collisions + '</td>'
+                    + '<td>' + json.MmapNodes + '</td>'
+                    + '<td>' + Math.round(json.MmapBytes / (1 << 20)) + '</td>'
+                    + '<td>' + json.Maps + '/' + json.Unmaps + '</td>'
+                    + '<td>' + json.Preads + '</td>';
                 tr.innerHTML = d;
                 document.getElementById("monitorBody").appendChild(tr);
             }
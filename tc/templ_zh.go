@@ -688,6 +688,94 @@ const (
             </tbody>
         </table>
     </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>完整性校验</h6>
+        <button id="scrubStart" class="btn btn-primary btn-sm" onclick="scrub('start')">开始校验</button>
+        <button id="scrubStop" class="btn btn-secondary btn-sm" onclick="scrub('stop')">停止</button>
+        <span id="scrubProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>问题</th>
+                <th>文件名</th>
+                <th>偏移</th>
+                <th>路径</th>
+                <th>指纹</th>
+                <th>已隔离</th>
+            </tr>
+            <tbody id="scrubTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>压缩字典</h6>
+        <button id="dictTrain" class="btn btn-primary btn-sm" onclick="dict('train')">训练字典</button>
+        <span id="dictProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>编号</th>
+                <th>范围</th>
+                <th>样本数</th>
+                <th>zstd 压缩率</th>
+                <th>字典压缩率</th>
+                <th>训练时间</th>
+                <th>使用中</th>
+            </tr>
+            <tbody id="dictTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>数据目录</h6>
+        <button id="rebalance" class="btn btn-primary btn-sm" onclick="datadir('rebalance')">重新均衡</button>
+        <span id="rebalanceProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>目录</th>
+                <th>权重</th>
+                <th>可用空间(GB)</th>
+                <th>总空间(GB)</th>
+                <th>文件数</th>
+                <th>状态</th>
+            </tr>
+            <tbody id="datadirTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>纠删码文件</h6>
+        <button id="repairShards" class="btn btn-primary btn-sm" onclick="shard('repair')">修复</button>
+        <span id="repairProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>文件</th>
+                <th>分片</th>
+                <th>大小(MB)</th>
+                <th>缺失</th>
+                <th>损坏</th>
+                <th>状态</th>
+                <th>目录</th>
+            </tr>
+            <tbody id="shardTableBody">
+            </tbody>
+        </table>
+    </div>
+    <div class="mt-3" style="font-size: xx-small;">
+        <h6>碎片整理任务</h6>
+        <button id="defragJobStart" class="btn btn-primary btn-sm" onclick="defragJob('start')">立即执行</button>
+        <span id="defragJobProgress" class="ms-2"></span>
+        <table class="table table-striped mt-1" style="font-size: smaller;">
+            <tr>
+                <th>开始时间</th>
+                <th>触发</th>
+                <th>文件</th>
+                <th>回收(MB)</th>
+                <th>耗时(秒)</th>
+                <th>错误</th>
+            </tr>
+            <tbody id="defragJobTableBody">
+            </tbody>
+        </table>
+    </div>
     <script>
         function fragment(n) {
             if (confirm("[" + n + "]确定进行碎片整理？\n建议碎片整理应当在WFS服务操作比较少的状态进行，可减少对前端服务质量的影响")) {
@@ -716,6 +804,195 @@ const (
                 });
             }
         }
+        function scrub(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/scrub', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "校验中... " : (data.startTime > 0 ? "已完成 " : "")
+                if (data.startTime > 0) {
+                    p += "文件:" + data.nodes + "/" + data.nodeTotal + " 数据块:" + data.blocks + " 字节:" + data.bytes + " 路径:" + data.paths
+                }
+                if (data.node) {
+                    p += " [" + data.node + "]"
+                }
+                document.getElementById("scrubProgress").innerText = p
+                document.getElementById("scrubStart").disabled = data.running
+                document.getElementById("scrubStop").disabled = !data.running
+                const body = document.getElementById("scrubTableBody")
+                body.innerHTML = ""
+                data.findings.forEach(f => {
+                    const tr = body.insertRow()
+                    const cells = [f.kind, f.node, f.offset, f.path, f.fingerprint, f.quarantined ? "是" : "否"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v === undefined ? "" : v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => scrub(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        scrub("")
+        function dict(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/dict', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "训练中... " : (data.startTime > 0 ? "已完成 " : "")
+                if (data.startTime > 0) {
+                    p += "样本:" + data.samples
+                }
+                document.getElementById("dictProgress").innerText = p
+                document.getElementById("dictTrain").disabled = data.running
+                const body = document.getElementById("dictTableBody")
+                body.innerHTML = ""
+                const ratio = (n, size) => size > 0 ? (100 * n / size).toFixed(1) + "%" : ""
+                data.dicts.forEach(d => {
+                    const tr = body.insertRow()
+                    const cells = [d.id, d.scope, d.samples, ratio(d.plain, d.size), ratio(d.dicted, d.size), d.time, d.current ? "是" : "否"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => dict(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        dict("")
+        function datadir(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/datadir', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "均衡中... " : (data.startTime > 0 ? "已完成 " : "")
+                if (data.startTime > 0) {
+                    p += "移动文件:" + data.moved + " 字节:" + data.bytes
+                }
+                document.getElementById("rebalanceProgress").innerText = p
+                document.getElementById("rebalance").disabled = data.running
+                const body = document.getElementById("datadirTableBody")
+                body.innerHTML = ""
+                const gb = n => (n / (1 << 30)).toFixed(1)
+                data.dirs.forEach(d => {
+                    const tr = body.insertRow()
+                    const cells = [d.path, d.weight, gb(d.free), gb(d.total), d.nodes, d.readOnly ? "只读" : "可写"]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => datadir(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        datadir("")
+        function shard(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/shard', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "修复中... " : (data.startTime > 0 ? "完成 " : "")
+                if (data.startTime > 0) {
+                    p += "修复分片:" + data.repaired + " 失败文件:" + data.failed
+                }
+                document.getElementById("repairProgress").innerText = p
+                document.getElementById("repairShards").disabled = data.running
+                const body = document.getElementById("shardTableBody")
+                body.innerHTML = ""
+                data.nodes.forEach(n => {
+                    const tr = body.insertRow()
+                    const bad = n.missing + n.damaged
+                    const health = bad == 0 ? "健康" : (bad <= n.parity ? "降级" : "丢失")
+                    const cells = [n.node, n.data + "+" + n.parity, (n.size / (1 << 20)).toFixed(1), n.missing, n.damaged, health, n.dirs.join(" ")]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => shard(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        shard("")
+
+        function defragJob(action) {
+            const formData = new FormData();
+            formData.append("action", action)
+            fetch('/defragjob', {
+                method: 'POST',
+                body: formData,
+            }).then(response => {
+                return response.json();
+            }).then(data => {
+                if (!data.status) {
+                    alert(data.desc)
+                    return
+                }
+                let p = data.running ? "碎片整理中... " : ""
+                if (data.running) {
+                    p += "文件:" + data.nodes + "/" + data.nodeTotal + " 回收(MB):" + (data.reclaimed / (1 << 20)).toFixed(1) + " " + data.node.join(" ")
+                }
+                document.getElementById("defragJobProgress").innerText = p
+                document.getElementById("defragJobStart").disabled = data.running
+                const body = document.getElementById("defragJobTableBody")
+                body.innerHTML = ""
+                data.history.forEach(r => {
+                    const tr = body.insertRow()
+                    const cells = [new Date(r.startTime / 1e6).toLocaleString(), r.auto ? "定时" : "手动", r.nodes, (r.reclaimed / (1 << 20)).toFixed(1), ((r.endTime - r.startTime) / 1e9).toFixed(1), (r.errors || []).join("; ")]
+                    cells.forEach(v => {
+                        tr.insertCell().innerText = v
+                    })
+                })
+                if (data.running) {
+                    setTimeout(() => defragJob(""), 2000)
+                }
+            }).catch(error => {
+                console.error('Error:', error);
+            });
+        }
+        defragJob("")
     </script>
 </body>
 
@@ -896,6 +1173,11 @@ const (
                 <th>磁盘剩余(GB)</th>
                 <th>内存使用率</th>
                 <th>CPU使用率</th>
+                <th>指纹冲突</th>
+                <th>映射文件数</th>
+                <th>映射大小(MB)</th>
+                <th>映射/解除映射次数</th>
+                <th>pread次数</th>
             </tr>
             <tbody id="monitorBody">
             </tbody>
@@ -932,7 +1214,12 @@ const (
                     + '<td>' + json.NumCPU + '</td>'
                     + '<td>' + json.DiskFree + '</td>'
                     + '<td>' + Math.round(json.RamUsage * 10000) / 100 + '%</td>'
-                    + '<td>' + Math.round(json.CpuUsage * 100) / 100 + '%</td>';
+                    + '<td>' + Math.round(json.CpuUsage * 100) / 100 + '%</td>'
+                    + '<td>' + json.Collisions + '</td>'
+                    + '<td>' + json.MmapNodes + '</td>'
+                    + '<td>' + Math.round(json.MmapBytes / (1 << 20)) + '</td>'
+                    + '<td>' + json.Maps + '/' + json.Unmaps + '</td>'
+                    + '<td>' + json.Preads + '</td>';
                 tr.innerHTML = d;
                 document.getElementById("monitorBody").appendChild(tr);
             }
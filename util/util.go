@@ -60,6 +60,14 @@ func TimestrampFormat(tt int64) string {
 	return time.Unix(0, tt).Format(time.DateTime)
 }
 
+// ExpireAt returns the time in unix nanoseconds ttl seconds from now, 0 if ttl is not positive
+func ExpireAt(ttl int64) int64 {
+	if ttl > 0 {
+		return time.Now().Add(time.Duration(ttl) * time.Second).UnixNano()
+	}
+	return 0
+}
+
 func Recover() {
 	if err := recover(); err != nil {
 		logger.Error(string(debug.Stack()))